@@ -97,6 +97,7 @@ func loadConfig(dashboardServicePtr **internal.DashboardService) (*config.Dashbo
 				// 同步更新 servers map（如果 dashboardService 已创建）
 				if dashboardServicePtr != nil && *dashboardServicePtr != nil {
 					(*dashboardServicePtr).ReloadServers(newCfg.Servers)
+					(*dashboardServicePtr).ReloadProbes(newCfg.Probes)
 				}
 
 				*currentCfg = *newCfg
@@ -0,0 +1,343 @@
+package internal
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ruanun/simple-server-status/internal/agent/config"
+	"github.com/ruanun/simple-server-status/pkg/model"
+)
+
+// 可用性检查的默认值
+const (
+	defaultCheckInterval = 60 * time.Second
+	defaultCheckTimeout  = 10 * time.Second
+	defaultCertWarnDays  = 14
+	maxCheckBodySize     = 1 << 20
+	checkUserAgent       = "simple-server-status-agent"
+)
+
+// checkSpec 一项可用性检查的规则
+type checkSpec struct {
+	name         string
+	typ          string
+	target       string // http 检查为 URL，其余为 host:port
+	interval     time.Duration
+	timeout      time.Duration
+	expectStatus []int
+	bodyRegex    *regexp.Regexp
+	warnDays     int
+	serverName   string
+	insecure     bool
+}
+
+// checkState 一项检查的调度状态与最近一次结果
+type checkState struct {
+	spec    checkSpec
+	next    time.Time
+	running bool
+	result  *model.CheckResult
+}
+
+// newCheckSpecs 根据配置生成检查规则，配置无效时返回错误
+func newCheckSpecs(checks []config.CheckConfig) ([]checkSpec, error) {
+	specs := make([]checkSpec, 0, len(checks))
+	names := make(map[string]bool, len(checks))
+	for i, cfg := range checks {
+		spec, err := newCheckSpec(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 个检查配置无效: %w", i+1, err)
+		}
+		if names[spec.name] {
+			return nil, fmt.Errorf("检查名称重复: %s", spec.name)
+		}
+		names[spec.name] = true
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// newCheckSpec 校验单项检查配置并应用默认值
+func newCheckSpec(cfg config.CheckConfig) (checkSpec, error) {
+	spec := checkSpec{
+		name:         strings.TrimSpace(cfg.Name),
+		typ:          strings.ToLower(strings.TrimSpace(cfg.Type)),
+		interval:     time.Duration(cfg.Interval) * time.Second,
+		timeout:      time.Duration(cfg.Timeout) * time.Second,
+		expectStatus: cfg.ExpectStatus,
+		warnDays:     cfg.WarnDays,
+		serverName:   strings.TrimSpace(cfg.ServerName),
+		insecure:     cfg.InsecureSkipVerify,
+	}
+	switch spec.typ {
+	case model.CheckTypeHTTP:
+		spec.target = strings.TrimSpace(cfg.URL)
+		u, err := url.Parse(spec.target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return spec, fmt.Errorf("http 检查的 url 必须是 http:// 或 https:// 开头的完整地址")
+		}
+		for _, code := range spec.expectStatus {
+			if code < 100 || code > 599 {
+				return spec, fmt.Errorf("无效的期望状态码: %d", code)
+			}
+		}
+		if cfg.BodyRegex != "" {
+			re, err := regexp.Compile(cfg.BodyRegex)
+			if err != nil {
+				return spec, fmt.Errorf("无效的响应内容正则表达式: %w", err)
+			}
+			spec.bodyRegex = re
+		}
+	case model.CheckTypeTCP, model.CheckTypeTLS:
+		spec.target = strings.TrimSpace(cfg.Target)
+		if host, port, err := net.SplitHostPort(spec.target); err != nil || host == "" || port == "" {
+			return spec, fmt.Errorf("%s 检查的 target 必须是 host:port 格式", spec.typ)
+		}
+	default:
+		return spec, fmt.Errorf("不支持的检查类型 %q，仅支持 http、tcp、tls", cfg.Type)
+	}
+	if spec.name == "" {
+		spec.name = spec.target
+	}
+
+	if spec.interval < 0 || spec.timeout < 0 || spec.warnDays < 0 {
+		return spec, fmt.Errorf("检查周期、超时时间与警告天数不能为负数")
+	}
+	if spec.interval == 0 {
+		spec.interval = defaultCheckInterval
+	}
+	if spec.timeout == 0 {
+		spec.timeout = min(defaultCheckTimeout, spec.interval)
+	}
+	if spec.timeout > spec.interval {
+		return spec, fmt.Errorf("超时时间不能大于检查周期")
+	}
+	if spec.warnDays == 0 {
+		spec.warnDays = defaultCertWarnDays
+	}
+	return spec, nil
+}
+
+// CheckCollector 可用性检查采集器
+// 按各项检查自己的周期在后台执行 HTTP、TCP、TLS 证书检查，每次上报携带各项检查最近一次的结果；
+// 检查测量本机到目标的直连情况，不经过 Agent 的出站代理
+type CheckCollector struct {
+	baseCollector
+	rootCAs *x509.CertPool // 为空时使用系统根证书
+	now     func() time.Time
+
+	mu     sync.Mutex
+	checks []*checkState
+	wg     sync.WaitGroup // 正在执行的检查
+}
+
+// NewCheckCollector 创建可用性检查采集器；未配置检查时不上报
+func NewCheckCollector() *CheckCollector {
+	return &CheckCollector{
+		baseCollector: baseCollector{name: CollectorChecks},
+		now:           time.Now,
+	}
+}
+
+// ApplyConfig 应用检查配置；名称、类型与目标不变的检查保留最近一次结果和调度时间
+func (c *CheckCollector) ApplyConfig(cfg *config.AgentConfig) error {
+	specs, err := newCheckSpecs(cfg.Checks)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	checks := make([]*checkState, 0, len(specs))
+	for _, spec := range specs {
+		st := &checkState{spec: spec}
+		for _, old := range c.checks {
+			if old.spec.name == spec.name && old.spec.typ == spec.typ && old.spec.target == spec.target {
+				st.next, st.result = old.next, old.result
+			}
+		}
+		checks = append(checks, st)
+	}
+	c.checks = checks
+	return nil
+}
+
+// Collect 启动到期的检查，返回各项检查最近一次的结果；尚未完成首次检查的项不上报
+func (c *CheckCollector) Collect(_ context.Context) (PartialInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.checks) == 0 {
+		return func(s *model.ServerInfo) { s.Checks = nil }, nil
+	}
+	now := c.now()
+	results := make([]*model.CheckResult, 0, len(c.checks))
+	for _, st := range c.checks {
+		// 检查在后台执行，耗时较长的检查不会阻塞上报
+		if !st.running && !now.Before(st.next) {
+			st.running = true
+			st.next = now.Add(st.spec.interval)
+			c.wg.Add(1)
+			go c.run(st)
+		}
+		if st.result != nil {
+			results = append(results, st.result)
+		}
+	}
+	return func(s *model.ServerInfo) { s.Checks = results }, nil
+}
+
+// run 执行一次检查并保存结果
+func (c *CheckCollector) run(st *checkState) {
+	defer c.wg.Done()
+	ctx, cancel := context.WithTimeout(context.Background(), st.spec.timeout)
+	defer cancel()
+	result := c.check(ctx, st.spec)
+
+	c.mu.Lock()
+	st.result = result
+	st.running = false
+	c.mu.Unlock()
+}
+
+// check 按类型执行检查
+func (c *CheckCollector) check(ctx context.Context, spec checkSpec) *model.CheckResult {
+	r := &model.CheckResult{
+		Name:   spec.name,
+		Type:   spec.typ,
+		Target: spec.target,
+		Status: model.CheckStatusOK,
+	}
+	start := time.Now()
+	var err error
+	switch spec.typ {
+	case model.CheckTypeHTTP:
+		err = c.checkHTTP(ctx, spec, r)
+	case model.CheckTypeTCP:
+		err = checkTCP(ctx, spec)
+	case model.CheckTypeTLS:
+		err = c.checkTLS(ctx, spec, r)
+	}
+	r.LatencyMs = math.Round(float64(time.Since(start).Microseconds())/10) / 100
+	if err != nil {
+		r.Status = model.CheckStatusFail
+		r.Error = checkErrorMessage(err)
+	}
+	r.CheckedAt = c.now().Unix()
+	return r
+}
+
+// checkHTTP 发送 GET 请求并检查状态码与响应内容；自动跟随重定向，按最终响应判断
+func (c *CheckCollector) checkHTTP(ctx context.Context, spec checkSpec, r *model.CheckResult) error {
+	client := NewHTTPClient(nil, spec.timeout)
+	transport := client.Transport.(*http.Transport)
+	// 每次检查都建立新连接，延迟包含连接与 TLS 握手
+	transport.DisableKeepAlives = true
+	transport.TLSClientConfig = &tls.Config{RootCAs: c.rootCAs, InsecureSkipVerify: spec.insecure}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, spec.target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", checkUserAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	r.StatusCode = resp.StatusCode
+	if len(spec.expectStatus) > 0 {
+		if !slices.Contains(spec.expectStatus, resp.StatusCode) {
+			return fmt.Errorf("状态码 %d 不符合预期", resp.StatusCode)
+		}
+	} else if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return fmt.Errorf("状态码 %d 不符合预期", resp.StatusCode)
+	}
+	if spec.bodyRegex != nil {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxCheckBodySize))
+		if err != nil {
+			return fmt.Errorf("读取响应失败: %w", err)
+		}
+		if !spec.bodyRegex.Match(body) {
+			return errors.New("响应内容不匹配")
+		}
+	}
+	return nil
+}
+
+// checkTCP 建立 TCP 连接后立即关闭
+func checkTCP(ctx context.Context, spec checkSpec) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", spec.target)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// checkTLS 完成 TLS 握手并检查证书有效期
+// 握手时不校验证书，以便证书已过期或不受信任时仍能读取过期时间，随后再单独校验证书链与主机名
+func (c *CheckCollector) checkTLS(ctx context.Context, spec checkSpec, r *model.CheckResult) error {
+	serverName := spec.serverName
+	if serverName == "" {
+		serverName, _, _ = net.SplitHostPort(spec.target)
+	}
+	d := tls.Dialer{Config: &tls.Config{ServerName: serverName, InsecureSkipVerify: true}}
+	conn, err := d.DialContext(ctx, "tcp", spec.target)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return errors.New("服务器未提供证书")
+	}
+	leaf := certs[0]
+	r.CertExpiresAt = leaf.NotAfter.Unix()
+	left := leaf.NotAfter.Sub(c.now())
+	if left <= 0 {
+		return errors.New("证书已过期")
+	}
+	if !spec.insecure {
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+		opts := x509.VerifyOptions{Roots: c.rootCAs, Intermediates: intermediates, DNSName: serverName}
+		if _, err := leaf.Verify(opts); err != nil {
+			return fmt.Errorf("证书校验失败: %w", err)
+		}
+	}
+	if days := int(left.Hours() / 24); days < spec.warnDays {
+		r.Status = model.CheckStatusWarn
+		r.Error = fmt.Sprintf("证书将在 %d 天后过期", days)
+	}
+	return nil
+}
+
+// checkErrorMessage 返回简短的错误描述，去掉 net/http 错误中重复的请求地址
+func checkErrorMessage(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) || os.IsTimeout(err) {
+		return "超时"
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	return err.Error()
+}
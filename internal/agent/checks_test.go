@@ -0,0 +1,245 @@
+package internal
+
+import (
+	"context"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ruanun/simple-server-status/internal/agent/config"
+	"github.com/ruanun/simple-server-status/pkg/model"
+)
+
+// runCheck 按配置执行一次检查
+func runCheck(t *testing.T, c *CheckCollector, cfg config.CheckConfig) *model.CheckResult {
+	t.Helper()
+	spec, err := newCheckSpec(cfg)
+	if err != nil {
+		t.Fatalf("newCheckSpec() error = %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), spec.timeout)
+	defer cancel()
+	return c.check(ctx, spec)
+}
+
+// TestCheckCollector_HTTP 测试状态码、响应内容与证书校验
+func TestCheckCollector_HTTP(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			_, _ = w.Write([]byte(`{"status":"up"}`))
+		case "/maintenance":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/old":
+			http.Redirect(w, r, "/health", http.StatusMovedPermanently)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+	tlsSrv := httptest.NewTLSServer(handler)
+	defer tlsSrv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(tlsSrv.Certificate())
+	c := NewCheckCollector()
+	c.rootCAs = roots
+
+	tests := []struct {
+		name       string
+		cfg        config.CheckConfig
+		wantStatus string
+		wantCode   int
+		wantError  string
+	}{
+		{"正常", config.CheckConfig{URL: srv.URL + "/health"}, model.CheckStatusOK, 200, ""},
+		{"跟随重定向", config.CheckConfig{URL: srv.URL + "/old"}, model.CheckStatusOK, 200, ""},
+		{"状态码异常", config.CheckConfig{URL: srv.URL + "/missing"}, model.CheckStatusFail, 404, "状态码 404 不符合预期"},
+		{"期望的状态码", config.CheckConfig{URL: srv.URL + "/maintenance", ExpectStatus: []int{503}}, model.CheckStatusOK, 503, ""},
+		{"不在期望的状态码中", config.CheckConfig{URL: srv.URL + "/health", ExpectStatus: []int{204}}, model.CheckStatusFail, 200, "状态码 200 不符合预期"},
+		{"响应内容匹配", config.CheckConfig{URL: srv.URL + "/health", BodyRegex: `"status":\s*"up"`}, model.CheckStatusOK, 200, ""},
+		{"响应内容不匹配", config.CheckConfig{URL: srv.URL + "/health", BodyRegex: "down"}, model.CheckStatusFail, 200, "响应内容不匹配"},
+		{"HTTPS", config.CheckConfig{URL: tlsSrv.URL + "/health"}, model.CheckStatusOK, 200, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Type = model.CheckTypeHTTP
+			got := runCheck(t, c, tt.cfg)
+			if got.Status != tt.wantStatus || got.StatusCode != tt.wantCode || got.Error != tt.wantError {
+				t.Errorf("结果 = %+v; want %s %d %q", *got, tt.wantStatus, tt.wantCode, tt.wantError)
+			}
+			if got.Name != tt.cfg.URL || got.Target != tt.cfg.URL || got.LatencyMs <= 0 {
+				t.Errorf("Name/Target/LatencyMs = %q/%q/%v", got.Name, got.Target, got.LatencyMs)
+			}
+		})
+	}
+
+	// 不受信任的证书
+	untrusted := NewCheckCollector()
+	got := runCheck(t, untrusted, config.CheckConfig{Type: model.CheckTypeHTTP, URL: tlsSrv.URL})
+	if got.Status != model.CheckStatusFail || !strings.Contains(got.Error, "certificate") {
+		t.Errorf("不受信任的证书 = %+v", *got)
+	}
+	got = runCheck(t, untrusted, config.CheckConfig{Type: model.CheckTypeHTTP, URL: tlsSrv.URL + "/health", InsecureSkipVerify: true})
+	if got.Status != model.CheckStatusOK {
+		t.Errorf("跳过证书校验 = %+v", *got)
+	}
+}
+
+// TestCheckCollector_Timeout 测试超时
+func TestCheckCollector_Timeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	c := NewCheckCollector()
+	spec, err := newCheckSpec(config.CheckConfig{Type: model.CheckTypeHTTP, URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec.timeout = 50 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), spec.timeout)
+	defer cancel()
+	if got := c.check(ctx, spec); got.Status != model.CheckStatusFail || got.Error != "超时" {
+		t.Errorf("结果 = %+v", *got)
+	}
+}
+
+// TestCheckCollector_TCP 测试 TCP 连接检查
+func TestCheckCollector_TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	c := NewCheckCollector()
+
+	if got := runCheck(t, c, config.CheckConfig{Type: model.CheckTypeTCP, Target: addr}); got.Status != model.CheckStatusOK {
+		t.Errorf("端口监听时 = %+v", *got)
+	}
+	_ = ln.Close()
+	if got := runCheck(t, c, config.CheckConfig{Type: model.CheckTypeTCP, Target: addr}); got.Status != model.CheckStatusFail || got.Error == "" {
+		t.Errorf("端口关闭后 = %+v", *got)
+	}
+}
+
+// TestCheckCollector_TLS 测试证书有效期、证书链与主机名校验
+func TestCheckCollector_TLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	target := srv.Listener.Addr().String()
+	notAfter := srv.Certificate().NotAfter
+
+	now := time.Now()
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	c := NewCheckCollector()
+	c.rootCAs = roots
+	c.now = func() time.Time { return now }
+
+	tests := []struct {
+		name       string
+		now        time.Time
+		cfg        config.CheckConfig
+		wantStatus string
+		wantError  string
+	}{
+		{"正常", now, config.CheckConfig{}, model.CheckStatusOK, ""},
+		{"指定 SNI", now, config.CheckConfig{ServerName: "example.com"}, model.CheckStatusOK, ""},
+		{"主机名不匹配", now, config.CheckConfig{ServerName: "other.test"}, model.CheckStatusFail, "证书校验失败"},
+		{"即将过期", notAfter.Add(-72 * time.Hour), config.CheckConfig{}, model.CheckStatusWarn, "证书将在 3 天后过期"},
+		{"自定义警告天数", notAfter.Add(-72 * time.Hour), config.CheckConfig{WarnDays: 2}, model.CheckStatusOK, ""},
+		{"已过期", notAfter.Add(time.Hour), config.CheckConfig{}, model.CheckStatusFail, "证书已过期"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = tt.now
+			tt.cfg.Type, tt.cfg.Target = model.CheckTypeTLS, target
+			got := runCheck(t, c, tt.cfg)
+			if got.Status != tt.wantStatus || !strings.HasPrefix(got.Error, tt.wantError) {
+				t.Errorf("结果 = %+v; want %s %q", *got, tt.wantStatus, tt.wantError)
+			}
+			if got.CertExpiresAt != notAfter.Unix() {
+				t.Errorf("CertExpiresAt = %d; want %d", got.CertExpiresAt, notAfter.Unix())
+			}
+		})
+	}
+
+	// 自签名证书跳过校验时仍检查有效期
+	now = time.Now()
+	untrusted := NewCheckCollector()
+	if got := runCheck(t, untrusted, config.CheckConfig{Type: model.CheckTypeTLS, Target: target}); got.Status != model.CheckStatusFail {
+		t.Errorf("不受信任的证书 = %+v", *got)
+	}
+	if got := runCheck(t, untrusted, config.CheckConfig{Type: model.CheckTypeTLS, Target: target, InsecureSkipVerify: true}); got.Status != model.CheckStatusOK {
+		t.Errorf("跳过证书校验 = %+v", *got)
+	}
+}
+
+// TestCheckCollector 测试各项检查按自己的周期在后台执行
+func TestCheckCollector(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer srv.Close()
+
+	now := time.Unix(1700000000, 0)
+	c := NewCheckCollector()
+	c.now = func() time.Time { return now }
+	cfg := &config.AgentConfig{Checks: []config.CheckConfig{
+		{Name: "api", Type: "http", URL: srv.URL, Interval: 10},
+	}}
+	if err := c.ApplyConfig(cfg); err != nil {
+		t.Fatalf("ApplyConfig() error = %v", err)
+	}
+	collect := func() []*model.CheckResult {
+		t.Helper()
+		return collectInto(t, c).Checks
+	}
+
+	// 首次上报只启动检查，完成后的上报携带结果
+	if got := collect(); len(got) != 0 {
+		t.Errorf("检查完成前 = %v", got)
+	}
+	c.wg.Wait()
+	got := collect()
+	if len(got) != 1 || got[0].Name != "api" || got[0].Status != model.CheckStatusOK || got[0].CheckedAt != now.Unix() {
+		t.Fatalf("检查完成后 = %+v", got)
+	}
+
+	// 周期内不重复检查，到期后再次检查
+	now = now.Add(5 * time.Second)
+	collect()
+	c.wg.Wait()
+	if hits.Load() != 1 {
+		t.Errorf("周期内请求次数 = %d; want 1", hits.Load())
+	}
+	now = now.Add(5 * time.Second)
+	collect()
+	c.wg.Wait()
+	if got = collect(); hits.Load() != 2 || got[0].CheckedAt != now.Unix() {
+		t.Errorf("到期后请求次数 = %d, 结果 = %+v", hits.Load(), got)
+	}
+
+	// 重新加载相同配置时保留结果，删除后不再上报
+	if err := c.ApplyConfig(cfg); err != nil {
+		t.Fatalf("ApplyConfig() error = %v", err)
+	}
+	if got = collect(); len(got) != 1 {
+		t.Errorf("重新加载配置后 = %v", got)
+	}
+	if err := c.ApplyConfig(&config.AgentConfig{}); err != nil {
+		t.Fatalf("ApplyConfig() error = %v", err)
+	}
+	if got = collect(); got != nil {
+		t.Errorf("删除配置后 = %v", got)
+	}
+}
@@ -0,0 +1,424 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ruanun/simple-server-status/internal/agent/config"
+	"github.com/ruanun/simple-server-status/pkg/model"
+	"go.uber.org/zap"
+)
+
+// PartialInfo 采集结果，将采集到的数据写入 ServerInfo 的对应部分
+// 调用时 info 的嵌套对象均已补全，多个采集器可以写入同一对象的不同字段
+type PartialInfo func(info *model.ServerInfo)
+
+// Collector 指标采集器
+type Collector interface {
+	// Name 采集器名称，唯一，同时作为配置中的 key
+	Name() string
+	// Interval 默认采集周期；0 表示每次上报都采集
+	Interval() time.Duration
+	// Enabled 默认是否启用
+	Enabled() bool
+	// Collect 执行一次采集
+	// 部分失败时可以同时返回已采集到的结果和错误
+	Collect(ctx context.Context) (PartialInfo, error)
+}
+
+// ConfigurableCollector 需要读取 AgentConfig 中专属配置的采集器
+type ConfigurableCollector interface {
+	Collector
+	// ApplyConfig 应用配置，配置无效时返回错误
+	ApplyConfig(cfg *config.AgentConfig) error
+}
+
+// DefaultCollectorTimeout 单个采集器默认超时时间
+const DefaultCollectorTimeout = 5 * time.Second
+
+// partialErrorLogInterval 部分采集失败（返回结果的同时返回错误）时相同错误的记录间隔
+const partialErrorLogInterval = 10 * time.Minute
+
+// collectorEntry 采集器在注册表中的状态
+type collectorEntry struct {
+	collector Collector
+	enabled   bool
+	interval  time.Duration
+	timeout   time.Duration
+
+	lastRun  time.Time
+	refresh  bool        // 下次 Collect 时立即采集，忽略周期
+	partial  PartialInfo // 最近一次成功的采集结果
+	stale    bool        // partial 是否为失败后沿用的旧结果
+	failures int         // 连续失败次数（不含部分成功）
+	running  atomic.Bool // 上一次采集是否仍在执行（超时后采集 goroutine 可能尚未退出）
+
+	partialErr    string    // 最近一次部分成功时的错误信息
+	partialLogged time.Time // 最近一次记录部分成功错误的时间
+}
+
+// collectResult 单次采集结果
+type collectResult struct {
+	ran      bool
+	partial  PartialInfo
+	err      error
+	elapsed  time.Duration
+	timedOut bool
+}
+
+// CollectorRegistry 采集器注册表
+// 并发执行已启用的采集器，按注册顺序将结果合并为一份 ServerInfo
+type CollectorRegistry struct {
+	mu           sync.Mutex
+	entries      []*collectorEntry
+	byName       map[string]*collectorEntry
+	errorHandler *ErrorHandler
+	monitor      *PerformanceMonitor
+	logger       *zap.SugaredLogger
+}
+
+// NewCollectorRegistry 创建采集器注册表
+func NewCollectorRegistry(errorHandler *ErrorHandler, monitor *PerformanceMonitor, logger *zap.SugaredLogger) *CollectorRegistry {
+	return &CollectorRegistry{
+		byName:       make(map[string]*collectorEntry),
+		errorHandler: errorHandler,
+		monitor:      monitor,
+		logger:       logger,
+	}
+}
+
+// Register 注册采集器
+func (r *CollectorRegistry) Register(c Collector) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name := c.Name()
+	if name == "" {
+		return fmt.Errorf("采集器名称不能为空")
+	}
+	if _, exists := r.byName[name]; exists {
+		return fmt.Errorf("采集器 %s 已注册", name)
+	}
+
+	entry := &collectorEntry{collector: c, enabled: c.Enabled(), interval: c.Interval(), timeout: DefaultCollectorTimeout}
+	r.entries = append(r.entries, entry)
+	r.byName[name] = entry
+	return nil
+}
+
+// SetEnabled 启用或禁用采集器
+func (r *CollectorRegistry) SetEnabled(name string, enabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.byName[name]
+	if !ok {
+		return fmt.Errorf("未知采集器: %s", name)
+	}
+	entry.enabled = enabled
+	if !enabled {
+		entry.partial = nil
+		entry.stale = false
+	}
+	return nil
+}
+
+// SetInterval 设置采集周期
+func (r *CollectorRegistry) SetInterval(name string, interval time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.byName[name]
+	if !ok {
+		return fmt.Errorf("未知采集器: %s", name)
+	}
+	entry.interval = interval
+	return nil
+}
+
+// SetTimeout 设置采集超时时间
+func (r *CollectorRegistry) SetTimeout(name string, timeout time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.byName[name]
+	if !ok {
+		return fmt.Errorf("未知采集器: %s", name)
+	}
+	if timeout <= 0 {
+		return fmt.Errorf("采集器 %s 超时时间必须大于 0", name)
+	}
+	entry.timeout = timeout
+	return nil
+}
+
+// SetFilter 设置采集器的过滤规则，采集器需实现 FilteredCollector
+func (r *CollectorRegistry) SetFilter(name string, include, exclude []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.byName[name]
+	if !ok {
+		return fmt.Errorf("未知采集器: %s", name)
+	}
+	fc, ok := entry.collector.(FilteredCollector)
+	if !ok {
+		return fmt.Errorf("采集器 %s 不支持 include/exclude 过滤", name)
+	}
+	filter, err := NewNameFilter(include, exclude)
+	if err != nil {
+		return fmt.Errorf("采集器 %s: %w", name, err)
+	}
+	fc.SetFilter(filter)
+	return nil
+}
+
+// Refresh 使指定采集器在下次 Collect 时立即采集；未指定时刷新全部采集器
+func (r *CollectorRegistry) Refresh(names ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(names) == 0 {
+		for _, entry := range r.entries {
+			entry.refresh = true
+		}
+		return
+	}
+	for _, name := range names {
+		if entry, ok := r.byName[name]; ok {
+			entry.refresh = true
+		}
+	}
+}
+
+// Configure 应用 AgentConfig 中的采集器配置
+func (r *CollectorRegistry) Configure(collectors map[string]config.CollectorConfig) error {
+	for name, cc := range collectors {
+		if cc.Enabled != nil {
+			if err := r.SetEnabled(name, *cc.Enabled); err != nil {
+				return err
+			}
+		}
+		if cc.Interval > 0 {
+			if err := r.SetInterval(name, time.Duration(cc.Interval)*time.Second); err != nil {
+				return err
+			}
+		}
+		if cc.Timeout > 0 {
+			if err := r.SetTimeout(name, time.Duration(cc.Timeout)*time.Second); err != nil {
+				return err
+			}
+		}
+		if len(cc.Include) > 0 || len(cc.Exclude) > 0 {
+			if err := r.SetFilter(name, cc.Include, cc.Exclude); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyConfig 将 AgentConfig 交给实现了 ConfigurableCollector 的采集器
+func (r *CollectorRegistry) ApplyConfig(cfg *config.AgentConfig) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, entry := range r.entries {
+		cc, ok := entry.collector.(ConfigurableCollector)
+		if !ok {
+			continue
+		}
+		if err := cc.ApplyConfig(cfg); err != nil {
+			return fmt.Errorf("采集器 %s: %w", entry.collector.Name(), err)
+		}
+	}
+	return nil
+}
+
+// IsEnabled 判断采集器是否启用
+func (r *CollectorRegistry) IsEnabled(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.byName[name]
+	return ok && entry.enabled
+}
+
+// Names 返回所有已注册采集器的名称（排序后）
+func (r *CollectorRegistry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.entries))
+	for _, entry := range r.entries {
+		names = append(names, entry.collector.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Collect 并发执行到期的采集器并合并结果
+// 各采集器按自己的周期执行，未到期的采集器复用上一次的结果，上报始终包含各项指标的最新值；采集失败或超时时沿用上一次的结果并标记为过期，
+// 错误交由 ErrorHandler 处理。最长阻塞时间为各采集器超时时间的最大值
+func (r *CollectorRegistry) Collect(ctx context.Context) *model.ServerInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	results := make([]collectResult, len(r.entries))
+	var wg sync.WaitGroup
+	for i, entry := range r.entries {
+		if !entry.enabled {
+			continue
+		}
+		if !entry.isDue(now) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, entry *collectorEntry) {
+			defer wg.Done()
+			results[i] = r.runWithTimeout(ctx, entry)
+		}(i, entry)
+	}
+	wg.Wait()
+
+	// 先补全空对象，采集器只写入自己负责的字段；
+	// 被禁用或尚未采集成功的部分保持为空对象，面板可以直接展示
+	info := &model.ServerInfo{}
+	info.FillEmpty()
+	for i, entry := range r.entries {
+		if !entry.enabled {
+			continue
+		}
+
+		if res := results[i]; res.ran {
+			entry.lastRun = now
+			entry.refresh = false
+			if res.partial != nil {
+				entry.partial = res.partial
+				entry.stale = false
+			} else if entry.partial != nil {
+				entry.stale = true
+			}
+			r.handleResult(entry, res, now)
+			if r.monitor != nil {
+				r.monitor.RecordCollectorLatency(entry.collector.Name(), res.elapsed, res.timedOut)
+			}
+		}
+
+		if entry.partial != nil {
+			entry.partial(info)
+			if entry.stale {
+				info.Stale = append(info.Stale, entry.collector.Name())
+			}
+		}
+	}
+
+	// 采集器可能把嵌套对象整体替换为 nil，再次补全
+	info.FillEmpty()
+	return info
+}
+
+// isDue 判断采集器是否需要执行
+// 从未成功、被要求刷新或上次失败的采集器立即执行，其余按周期执行
+func (e *collectorEntry) isDue(now time.Time) bool {
+	return e.partial == nil || e.refresh || e.failures > 0 ||
+		e.interval <= 0 || now.Sub(e.lastRun) >= e.interval
+}
+
+// runWithTimeout 在超时时间内执行单个采集器
+// 采集器不响应 ctx 时不等待其返回，并且在其退出前不会再次启动，避免 goroutine 堆积
+func (r *CollectorRegistry) runWithTimeout(ctx context.Context, entry *collectorEntry) collectResult {
+	c, timeout := entry.collector, entry.timeout
+	if !entry.running.CompareAndSwap(false, true) {
+		return collectResult{ran: true, err: fmt.Errorf("上一次采集仍未结束"), timedOut: true}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan collectResult, 1)
+	go func() {
+		defer entry.running.Store(false)
+		partial, err := r.runCollector(ctx, c)
+		done <- collectResult{ran: true, partial: partial, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		res.elapsed = time.Since(start)
+		return res
+	case <-ctx.Done():
+		return collectResult{
+			ran:      true,
+			err:      fmt.Errorf("采集超时 (%v): %w", timeout, ctx.Err()),
+			elapsed:  time.Since(start),
+			timedOut: true,
+		}
+	}
+}
+
+// runCollector 执行单个采集器，捕获 panic
+func (r *CollectorRegistry) runCollector(ctx context.Context, c Collector) (partial PartialInfo, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			partial = nil
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return c.Collect(ctx)
+}
+
+// handleResult 记录采集结果；连续失败时降低日志级别，避免刷屏
+// 部分成功（返回结果的同时返回错误，如个别磁盘不可读）不计为失败，采集器按正常周期执行，相同错误每 partialErrorLogInterval 最多记录一次
+func (r *CollectorRegistry) handleResult(entry *collectorEntry, res collectResult, now time.Time) {
+	err := res.err
+	if err == nil || res.partial != nil {
+		if entry.failures > 0 && r.logger != nil {
+			r.logger.Infof("采集器 %s 已恢复", entry.collector.Name())
+		}
+		entry.failures = 0
+		if err == nil {
+			entry.partialErr = ""
+			return
+		}
+		r.handlePartialError(entry, err, now)
+		return
+	}
+
+	entry.failures++
+	if r.errorHandler == nil {
+		return
+	}
+	severity := SeverityLow
+	if entry.failures == 1 {
+		severity = SeverityMedium
+	}
+	r.errorHandler.HandleError(NewAppError(ErrorTypeSystem, severity,
+		fmt.Sprintf("采集器 %s 采集失败", entry.collector.Name()), err))
+}
+
+// handlePartialError 记录部分成功时的错误；错误信息变化或超过记录间隔时才交由 ErrorHandler 处理
+func (r *CollectorRegistry) handlePartialError(entry *collectorEntry, err error, now time.Time) {
+	msg := err.Error()
+	if msg == entry.partialErr && now.Sub(entry.partialLogged) < partialErrorLogInterval {
+		return
+	}
+	severity := SeverityLow
+	if msg != entry.partialErr {
+		severity = SeverityMedium
+	}
+	entry.partialErr = msg
+	entry.partialLogged = now
+	if r.errorHandler == nil {
+		return
+	}
+	r.errorHandler.HandleError(NewAppError(ErrorTypeSystem, severity,
+		fmt.Sprintf("采集器 %s 部分数据采集失败", entry.collector.Name()), err))
+}
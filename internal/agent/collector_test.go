@@ -0,0 +1,923 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ruanun/simple-server-status/internal/agent/config"
+	"github.com/ruanun/simple-server-status/pkg/model"
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/disk"
+	"github.com/shirou/gopsutil/v4/host"
+	"github.com/shirou/gopsutil/v4/load"
+	"github.com/shirou/gopsutil/v4/mem"
+	"go.uber.org/zap"
+)
+
+// fakeCollector 测试用采集器
+type fakeCollector struct {
+	name     string
+	interval time.Duration
+	enabled  bool
+	calls    int
+	collect  func(calls int) (PartialInfo, error)
+}
+
+func (f *fakeCollector) Name() string            { return f.name }
+func (f *fakeCollector) Interval() time.Duration { return f.interval }
+func (f *fakeCollector) Enabled() bool           { return f.enabled }
+func (f *fakeCollector) Collect(_ context.Context) (PartialInfo, error) {
+	f.calls++
+	return f.collect(f.calls)
+}
+
+// cpuPercentCollector 返回固定 CPU 占用的假采集器
+func cpuPercentCollector(name string, percent float64) *fakeCollector {
+	return &fakeCollector{name: name, enabled: true, collect: func(int) (PartialInfo, error) {
+		return func(s *model.ServerInfo) { s.CpuInfo = &model.CpuInfo{Percent: percent} }, nil
+	}}
+}
+
+// collectInto 执行一次采集，返回写入了采集结果的 ServerInfo
+func collectInto(t *testing.T, c Collector) *model.ServerInfo {
+	t.Helper()
+	partial, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	s := &model.ServerInfo{}
+	partial(s)
+	return s
+}
+
+// newTestRegistry 创建使用空日志的采集器注册表
+func newTestRegistry() (*CollectorRegistry, *ErrorHandler) {
+	logger := zap.NewNop().Sugar()
+	errorHandler := NewErrorHandler(logger, nil)
+	return NewCollectorRegistry(errorHandler, nil, logger), errorHandler
+}
+
+// TestCollectorRegistry_Register 测试注册与重名检查
+func TestCollectorRegistry_Register(t *testing.T) {
+	registry, _ := newTestRegistry()
+
+	if err := registry.Register(cpuPercentCollector("cpu", 1)); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := registry.Register(cpuPercentCollector("cpu", 2)); err == nil {
+		t.Error("重复注册应返回错误")
+	}
+	if err := registry.Register(cpuPercentCollector("", 2)); err == nil {
+		t.Error("空名称应返回错误")
+	}
+	if names := registry.Names(); len(names) != 1 || names[0] != "cpu" {
+		t.Errorf("Names() = %v", names)
+	}
+}
+
+// TestCollectorRegistry_Collect 测试合并结果与补全空对象
+func TestCollectorRegistry_Collect(t *testing.T) {
+	registry, _ := newTestRegistry()
+	_ = registry.Register(cpuPercentCollector("cpu", 42))
+
+	info := registry.Collect(context.Background())
+	if info.CpuInfo.Percent != 42 {
+		t.Errorf("CPU = %v; want 42", info.CpuInfo.Percent)
+	}
+	// 未注册的部分补全为空对象，面板转换时不会空指针
+	if info.HostInfo == nil || info.DiskInfo == nil || info.NetworkInfo == nil {
+		t.Fatal("空对象未补全")
+	}
+	info.HostInfo.Platform = "linux"
+	_ = model.NewRespServerInfo(info)
+}
+
+// TestCollectorRegistry_EnableDisable 测试启用与禁用
+func TestCollectorRegistry_EnableDisable(t *testing.T) {
+	registry, _ := newTestRegistry()
+	fc := cpuPercentCollector("cpu", 42)
+	off := cpuPercentCollector("extra", 99)
+	off.enabled = false // 默认禁用
+	_ = registry.Register(fc)
+	_ = registry.Register(off)
+
+	if registry.IsEnabled("extra") {
+		t.Error("默认禁用的采集器不应启用")
+	}
+	if info := registry.Collect(context.Background()); info.CpuInfo.Percent != 42 || off.calls != 0 {
+		t.Errorf("禁用的采集器不应执行: cpu=%v calls=%d", info.CpuInfo.Percent, off.calls)
+	}
+
+	if err := registry.SetEnabled("cpu", false); err != nil {
+		t.Fatalf("SetEnabled() error = %v", err)
+	}
+	calls := fc.calls
+	if info := registry.Collect(context.Background()); info.CpuInfo.Percent != 0 || fc.calls != calls {
+		t.Errorf("禁用后不应再采集，也不应保留旧结果: cpu=%v", info.CpuInfo.Percent)
+	}
+
+	if err := registry.SetEnabled("unknown", true); err == nil {
+		t.Error("未知采集器应返回错误")
+	}
+}
+
+// TestCollectorRegistry_Interval 测试采集周期内复用上次结果
+func TestCollectorRegistry_Interval(t *testing.T) {
+	registry, _ := newTestRegistry()
+	fc := &fakeCollector{name: "host", enabled: true, interval: time.Hour, collect: func(calls int) (PartialInfo, error) {
+		return func(s *model.ServerInfo) { s.HostInfo = &model.HostInfo{Uptime: uint64(calls)} }, nil
+	}}
+	_ = registry.Register(fc)
+
+	first := registry.Collect(context.Background())
+	second := registry.Collect(context.Background())
+	if fc.calls != 1 {
+		t.Errorf("周期内采集次数 = %d; want 1", fc.calls)
+	}
+	if first.HostInfo.Uptime != 1 || second.HostInfo.Uptime != 1 {
+		t.Errorf("未复用上次结果: %d %d", first.HostInfo.Uptime, second.HostInfo.Uptime)
+	}
+
+	_ = registry.SetInterval("host", 0)
+	registry.Collect(context.Background())
+	if fc.calls != 2 {
+		t.Errorf("周期为 0 时应每次采集: calls = %d", fc.calls)
+	}
+}
+
+// TestCollectorRegistry_Refresh 测试按需刷新与失败后立即重试
+func TestCollectorRegistry_Refresh(t *testing.T) {
+	registry, _ := newTestRegistry()
+	fail := true
+	slow := &fakeCollector{name: CollectorCPUInfo, enabled: true, interval: time.Hour, collect: func(calls int) (PartialInfo, error) {
+		if calls == 2 && fail {
+			return nil, errors.New("boom")
+		}
+		return func(s *model.ServerInfo) { s.CpuInfo.Info = []string{fmt.Sprint(calls)} }, nil
+	}}
+	fast := &fakeCollector{name: CollectorCPU, enabled: true, collect: func(calls int) (PartialInfo, error) {
+		return func(s *model.ServerInfo) { s.CpuInfo.Percent = float64(calls) }, nil
+	}}
+	_ = registry.Register(slow)
+	_ = registry.Register(fast)
+
+	registry.Collect(context.Background())
+	info := registry.Collect(context.Background())
+	// 快慢采集器写入同一对象的不同字段，上报合并两者的最新值
+	if slow.calls != 1 || fast.calls != 2 || info.CpuInfo.Percent != 2 || info.CpuInfo.Info[0] != "1" {
+		t.Fatalf("周期调度错误: slow=%d fast=%d info=%+v", slow.calls, fast.calls, info.CpuInfo)
+	}
+
+	// 按需刷新：忽略周期立即采集；失败时沿用旧值
+	registry.Refresh(CollectorCPUInfo)
+	info = registry.Collect(context.Background())
+	if slow.calls != 2 || info.CpuInfo.Info[0] != "1" || len(info.Stale) != 1 {
+		t.Errorf("刷新失败时应沿用旧值: calls=%d info=%+v stale=%v", slow.calls, info.CpuInfo, info.Stale)
+	}
+
+	// 失败后下次上报立即重试，不等待完整周期
+	fail = false
+	info = registry.Collect(context.Background())
+	if slow.calls != 3 || info.CpuInfo.Info[0] != "3" || len(info.Stale) != 0 {
+		t.Errorf("失败后应立即重试: calls=%d info=%+v stale=%v", slow.calls, info.CpuInfo, info.Stale)
+	}
+	registry.Collect(context.Background())
+	if slow.calls != 3 {
+		t.Errorf("成功后应恢复周期调度: calls=%d", slow.calls)
+	}
+}
+
+// TestRegisterBuiltinCollectors 测试内置采集器的默认周期
+func TestRegisterBuiltinCollectors(t *testing.T) {
+	registry, _ := newTestRegistry()
+	if err := RegisterBuiltinCollectors(registry, NewNetworkStatsCollector(nil), NewProbeCollector()); err != nil {
+		t.Fatalf("RegisterBuiltinCollectors() error = %v", err)
+	}
+
+	want := map[string]time.Duration{
+		CollectorHost:         DefaultInventoryInterval,
+		CollectorCPUInfo:      DefaultInventoryInterval,
+		CollectorDisk:         DefaultDiskInterval,
+		CollectorCPU:          0,
+		CollectorLoad:         0,
+		CollectorMemory:       0,
+		CollectorSwap:         0,
+		CollectorDiskIO:       0,
+		CollectorNetwork:      0,
+		CollectorSockets:      0,
+		CollectorProcesses:    DefaultProcessInterval,
+		CollectorProcessWatch: 0,
+		CollectorChecks:       0,
+		CollectorProbes:       0,
+		CollectorSensors:      DefaultSensorInterval,
+	}
+	if len(registry.Names()) != len(want) {
+		t.Errorf("Names() = %v", registry.Names())
+	}
+	for name, interval := range want {
+		if got := registry.byName[name].interval; got != interval {
+			t.Errorf("%s 周期 = %v; want %v", name, got, interval)
+		}
+	}
+}
+
+// TestCollectorRegistry_Errors 测试错误交由 ErrorHandler 处理、panic 被捕获
+func TestCollectorRegistry_Errors(t *testing.T) {
+	registry, errorHandler := newTestRegistry()
+
+	failing := &fakeCollector{name: "memory", enabled: true, collect: func(int) (PartialInfo, error) {
+		return nil, errors.New("boom")
+	}}
+	panicking := &fakeCollector{name: "disk", enabled: true, collect: func(int) (PartialInfo, error) {
+		var stats []float64
+		_ = stats[0] // 模拟越界访问
+		return nil, nil
+	}}
+	partial := &fakeCollector{name: "cpu", enabled: true, collect: func(int) (PartialInfo, error) {
+		return func(s *model.ServerInfo) { s.CpuInfo = &model.CpuInfo{Percent: 7} }, errors.New("型号获取失败")
+	}}
+	_ = registry.Register(failing)
+	_ = registry.Register(panicking)
+	_ = registry.Register(partial)
+
+	info := registry.Collect(context.Background())
+	if info.CpuInfo.Percent != 7 {
+		t.Errorf("部分失败时应保留已采集的结果: %v", info.CpuInfo.Percent)
+	}
+	if info.VirtualMemoryInfo == nil || info.DiskInfo == nil {
+		t.Error("失败的采集器对应部分应补全为空对象")
+	}
+
+	if got := errorHandler.GetErrorStats()[ErrorTypeSystem]; got != 3 {
+		t.Errorf("系统错误数 = %d; want 3", got)
+	}
+	recent := errorHandler.GetRecentErrors(3)
+	for _, e := range recent {
+		if e.Severity != SeverityMedium {
+			t.Errorf("首次失败应为 Medium: %v", e)
+		}
+	}
+
+	// 连续失败降级为 Low，避免日志刷屏
+	registry.Collect(context.Background())
+	if last := errorHandler.GetRecentErrors(1)[0]; last.Severity != SeverityLow {
+		t.Errorf("连续失败应为 Low: %v", last.Severity)
+	}
+}
+
+// TestCollectorRegistry_PartialError 测试部分成功不计为失败：按正常周期执行，相同错误不重复记录
+func TestCollectorRegistry_PartialError(t *testing.T) {
+	registry, errorHandler := newTestRegistry()
+	fc := &fakeCollector{name: "disk", enabled: true, interval: time.Hour, collect: func(calls int) (PartialInfo, error) {
+		return func(s *model.ServerInfo) { s.DiskInfo = &model.DiskInfo{Total: uint64(calls)} }, errors.New("/mnt/nfs: stale file handle")
+	}}
+	_ = registry.Register(fc)
+
+	registry.Collect(context.Background())
+	info := registry.Collect(context.Background())
+	if fc.calls != 1 {
+		t.Errorf("周期内采集次数 = %d; want 1", fc.calls)
+	}
+	if info.DiskInfo.Total != 1 || len(info.Stale) != 0 {
+		t.Errorf("部分成功的结果应正常复用: total = %d, stale = %v", info.DiskInfo.Total, info.Stale)
+	}
+
+	// 到期后再次部分成功，相同错误在记录间隔内不重复记录
+	_ = registry.SetInterval("disk", 0)
+	registry.Collect(context.Background())
+	registry.Collect(context.Background())
+	if got := errorHandler.GetErrorStats()[ErrorTypeSystem]; got != 1 {
+		t.Errorf("系统错误数 = %d; want 1", got)
+	}
+
+	// 超过记录间隔后再次记录
+	registry.entries[0].partialLogged = time.Now().Add(-partialErrorLogInterval)
+	registry.Collect(context.Background())
+	if got := errorHandler.GetErrorStats()[ErrorTypeSystem]; got != 2 {
+		t.Errorf("超过记录间隔后系统错误数 = %d; want 2", got)
+	}
+	if last := errorHandler.GetRecentErrors(1)[0]; last.Severity != SeverityLow {
+		t.Errorf("重复的部分失败应为 Low: %v", last.Severity)
+	}
+}
+
+// TestCollectorRegistry_Configure 测试从 AgentConfig 应用配置
+func TestCollectorRegistry_Configure(t *testing.T) {
+	registry, _ := newTestRegistry()
+	_ = RegisterBuiltinCollectors(registry, NewNetworkStatsCollector(nil), NewProbeCollector())
+
+	disabled := false
+	err := registry.Configure(map[string]config.CollectorConfig{
+		CollectorDisk: {Enabled: &disabled},
+		CollectorHost: {Interval: 600},
+	})
+	if err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	if registry.IsEnabled(CollectorDisk) {
+		t.Error("disk 应被禁用")
+	}
+	if !registry.IsEnabled(CollectorCPU) {
+		t.Error("未配置的采集器应保持启用")
+	}
+	if registry.byName[CollectorHost].interval != 600*time.Second {
+		t.Errorf("host 周期 = %v; want 10m", registry.byName[CollectorHost].interval)
+	}
+
+	if err := registry.Configure(map[string]config.CollectorConfig{"gpu": {Enabled: &disabled}}); err == nil {
+		t.Error("未知采集器应返回错误")
+	}
+}
+
+// TestBuiltinCollectors 测试内置采集器对异常结果的处理
+func TestBuiltinCollectors(t *testing.T) {
+	apply := func(p PartialInfo) *model.ServerInfo {
+		info := &model.ServerInfo{}
+		info.FillEmpty()
+		if p != nil {
+			p(info)
+		}
+		return info
+	}
+
+	t.Run("CPU 时间为空不越界", func(t *testing.T) {
+		c := NewCPUCollector()
+		c.timesFn = func(context.Context, bool) ([]cpu.TimesStat, error) { return []cpu.TimesStat{}, nil }
+		if p, err := c.Collect(context.Background()); p != nil || err == nil {
+			t.Error("空结果应返回错误且无结果")
+		}
+	})
+
+	t.Run("CPU 占用按差值计算", func(t *testing.T) {
+		// 第一次为启动以来的累计值，第二次的差值：user 30 system 10 iowait 20 steal 15 idle 25
+		samples := [][]cpu.TimesStat{
+			{{CPU: "cpu-total", User: 100, System: 50, Idle: 800, Iowait: 30, Steal: 20}},
+			{{CPU: "cpu-total", User: 130, System: 60, Idle: 825, Iowait: 50, Steal: 35}},
+		}
+		perCore := [][]cpu.TimesStat{
+			{{CPU: "cpu0", User: 50, Idle: 450}, {CPU: "cpu1", User: 50, Idle: 350}},
+			{{CPU: "cpu0", User: 50, Idle: 500}, {CPU: "cpu1", User: 90, Idle: 360}},
+		}
+		call := 0
+		c := NewCPUCollector()
+		c.timesFn = func(_ context.Context, perCPU bool) ([]cpu.TimesStat, error) {
+			if perCPU {
+				return perCore[call], nil
+			}
+			return samples[call], nil
+		}
+		c.mhzFn = func(context.Context) (float64, error) { return 2400, nil }
+
+		p, err := c.Collect(context.Background())
+		if err != nil {
+			t.Fatalf("Collect() error = %v", err)
+		}
+		if info := apply(p); math.Abs(info.CpuInfo.Percent-17) > 1e-9 {
+			t.Errorf("首次采集应为启动以来的平均值: %v", info.CpuInfo.Percent)
+		}
+
+		call = 1
+		p, err = c.Collect(context.Background())
+		if err != nil {
+			t.Fatalf("Collect() error = %v", err)
+		}
+		info := apply(p)
+		times := info.CpuInfo.Times
+		if times == nil || math.Abs(times.User-30) > 1e-9 || math.Abs(times.Iowait-20) > 1e-9 ||
+			math.Abs(times.Steal-15) > 1e-9 || math.Abs(times.Idle-25) > 1e-9 {
+			t.Errorf("各状态占比错误: %+v", times)
+		}
+		// 占用不含 idle 和 iowait
+		if math.Abs(info.CpuInfo.Percent-55) > 1e-9 {
+			t.Errorf("Percent = %v; want 55", info.CpuInfo.Percent)
+		}
+		if pc := info.CpuInfo.PerCore; len(pc) != 2 || pc[0] != 0 || math.Abs(pc[1]-80) > 1e-9 {
+			t.Errorf("PerCore = %v; want [0 80]", pc)
+		}
+		if info.CpuInfo.Mhz != 2400 {
+			t.Errorf("Mhz = %v", info.CpuInfo.Mhz)
+		}
+
+		// 时间没有变化时不除以 0
+		p, _ = c.Collect(context.Background())
+		if info := apply(p); info.CpuInfo.Percent != 0 || info.CpuInfo.Times.Idle != 100 {
+			t.Errorf("无变化时应视为空闲: %+v", info.CpuInfo)
+		}
+	})
+
+	t.Run("CPU 频率解析", func(t *testing.T) {
+		cpuinfo := "processor\t: 0\ncpu MHz\t\t: 2000.000\nprocessor\t: 1\ncpu MHz\t\t: 3000.500\nflags\t: fpu\n"
+		if mhz := parseCPUMhz(cpuinfo); mhz != 2500.25 {
+			t.Errorf("parseCPUMhz() = %v; want 2500.25", mhz)
+		}
+		if mhz := parseCPUMhz("processor\t: 0\n"); mhz != 0 {
+			t.Errorf("无频率信息时应为 0: %v", mhz)
+		}
+	})
+
+	t.Run("CPU 型号合并", func(t *testing.T) {
+		c := NewCPUInfoCollector()
+		c.infoFn = func(context.Context) ([]cpu.InfoStat, error) {
+			return []cpu.InfoStat{{ModelName: "Xeon"}, {ModelName: "Xeon"}, {ModelName: "ARM"}}, nil
+		}
+		c.countsFn = func(_ context.Context, logical bool) (int, error) {
+			if logical {
+				return 8, nil
+			}
+			return 4, nil
+		}
+		p, err := c.Collect(context.Background())
+		if err != nil {
+			t.Fatalf("Collect() error = %v", err)
+		}
+		info := apply(p)
+		if len(info.CpuInfo.Info) != 2 || info.CpuInfo.Info[0] != "Xeon x 2 " || info.CpuInfo.Info[1] != "ARM x 1 " {
+			t.Errorf("型号信息错误: %v", info.CpuInfo.Info)
+		}
+		if info.CpuInfo.LogicalCores != 8 || info.CpuInfo.PhysicalCores != 4 {
+			t.Errorf("核心数错误: %d/%d", info.CpuInfo.LogicalCores, info.CpuInfo.PhysicalCores)
+		}
+	})
+
+	t.Run("内存失败不解引用空指针", func(t *testing.T) {
+		c := NewMemoryCollector()
+		c.memFn = func(context.Context) (*mem.VirtualMemoryStat, error) { return nil, errors.New("x") }
+		if p, err := c.Collect(context.Background()); p != nil || err == nil {
+			t.Error("应返回错误且无结果")
+		}
+		c.memFn = func(context.Context) (*mem.VirtualMemoryStat, error) { return nil, nil }
+		if p, err := c.Collect(context.Background()); p != nil || err == nil {
+			t.Error("空结果应返回错误")
+		}
+	})
+
+	t.Run("内存明细", func(t *testing.T) {
+		c := NewMemoryCollector()
+		c.memFn = func(context.Context) (*mem.VirtualMemoryStat, error) {
+			return &mem.VirtualMemoryStat{Total: 1000, Used: 300, UsedPercent: 30, Available: 650, Free: 50,
+				Buffers: 20, Cached: 600, Slab: 30, Dirty: 5, CommittedAS: 1200, HugePagesTotal: 4, HugePageSize: 2 << 20}, nil
+		}
+		p, err := c.Collect(context.Background())
+		if err != nil {
+			t.Fatalf("Collect() error = %v", err)
+		}
+		m := apply(p).VirtualMemoryInfo
+		if m.Available != 650 || m.Cached != 600 || m.Buffers != 20 || m.Slab != 30 || m.Dirty != 5 ||
+			m.CommittedAS != 1200 || m.HugePagesTotal != 4 || m.HugePageSize != 2<<20 {
+			t.Errorf("内存明细错误: %+v", m)
+		}
+		if got := m.Percent(model.RAMPercentExcludeCache); got != 35 {
+			t.Errorf("不计缓存的占用 = %v; want 35", got)
+		}
+	})
+
+	t.Run("Swap 正常", func(t *testing.T) {
+		c := NewSwapCollector()
+		c.swapFn = func(context.Context) (*mem.SwapMemoryStat, error) {
+			return &mem.SwapMemoryStat{Total: 100, Used: 25, Free: 75, UsedPercent: 25}, nil
+		}
+		p, err := c.Collect(context.Background())
+		if err != nil || apply(p).SwapMemoryInfo.UsedPercent != 25 {
+			t.Errorf("Swap 结果错误: %v", err)
+		}
+	})
+
+	t.Run("主机信息与负载分别写入", func(t *testing.T) {
+		hc := NewHostCollector()
+		bootTime := uint64(time.Now().Add(-time.Hour).Unix())
+		hc.infoFn = func(context.Context) (*host.InfoStat, error) {
+			return &host.InfoStat{Platform: "debian", BootTime: bootTime, Uptime: 1}, nil
+		}
+		lc := NewLoadCollector()
+		lc.loadFn = func(context.Context) (*load.AvgStat, error) { return &load.AvgStat{Load1: 1.5}, nil }
+
+		hp, err := hc.Collect(context.Background())
+		if err != nil {
+			t.Fatalf("Collect() error = %v", err)
+		}
+		lp, _ := lc.Collect(context.Background())
+
+		// 与注册顺序无关，两者互不覆盖
+		info := apply(lp)
+		hp(info)
+		if info.HostInfo.Platform != "debian" || info.HostInfo.AvgStat.Load1 != 1.5 {
+			t.Errorf("主机信息被覆盖: %+v", info.HostInfo)
+		}
+		// 运行时间根据启动时间推算，缓存的结果不会停留在采集时刻
+		if info.HostInfo.Uptime < 3500 {
+			t.Errorf("Uptime = %d; want ~3600", info.HostInfo.Uptime)
+		}
+
+		lc.loadFn = func(context.Context) (*load.AvgStat, error) { return nil, errors.New("x") }
+		if p, err := lc.Collect(context.Background()); p != nil || err == nil {
+			t.Error("负载失败时应返回错误且无结果")
+		}
+	})
+
+	t.Run("磁盘跳过失败分区与过滤类型", func(t *testing.T) {
+		c := NewDiskCollector()
+		c.partitionsFn = func(context.Context) ([]disk.PartitionStat, error) {
+			return []disk.PartitionStat{
+				{Mountpoint: "/", Fstype: "ext4"},
+				{Mountpoint: "/mnt/nfs", Fstype: "xfs"},
+				{Mountpoint: "/run", Fstype: "tmpfs"},
+				{Mountpoint: "/var/lib/kubelet/pods/x", Fstype: "ext4"},
+			}, nil
+		}
+		c.usageFn = func(_ context.Context, path string) (*disk.UsageStat, error) {
+			if path == "/mnt/nfs" {
+				return nil, errors.New("stale file handle")
+			}
+			return &disk.UsageStat{Total: 200, Used: 50, Free: 150, UsedPercent: 25}, nil
+		}
+		p, err := c.Collect(context.Background())
+		if err == nil {
+			t.Error("失败的分区应返回错误")
+		}
+		info := apply(p)
+		if len(info.DiskInfo.Partitions) != 1 || info.DiskInfo.UsedPercent != 25 {
+			t.Errorf("磁盘结果错误: %+v", info.DiskInfo)
+		}
+	})
+
+	t.Run("无分区时占用为 0 而不是 NaN", func(t *testing.T) {
+		c := NewDiskCollector()
+		c.partitionsFn = func(context.Context) ([]disk.PartitionStat, error) { return nil, nil }
+		p, err := c.Collect(context.Background())
+		if err != nil || apply(p).DiskInfo.UsedPercent != 0 {
+			t.Errorf("结果错误: %v %v", err, apply(p).DiskInfo.UsedPercent)
+		}
+	})
+}
+
+// hangingCollector 第一次正常返回，之后阻塞到 release 关闭为止（不响应 ctx）
+type hangingCollector struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (h *hangingCollector) Name() string            { return CollectorHost }
+func (h *hangingCollector) Interval() time.Duration { return 0 }
+func (h *hangingCollector) Enabled() bool           { return true }
+func (h *hangingCollector) Collect(_ context.Context) (PartialInfo, error) {
+	n := h.calls.Add(1)
+	if n > 1 && n < 3 {
+		<-h.release
+		return nil, errors.New("released")
+	}
+	return func(s *model.ServerInfo) { s.HostInfo = &model.HostInfo{Uptime: uint64(n)} }, nil
+}
+
+// TestCollectorRegistry_Timeout 测试超时后沿用上次结果并标记为过期
+func TestCollectorRegistry_Timeout(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+	monitor := NewPerformanceMonitor(nil)
+	defer monitor.Close()
+	registry := NewCollectorRegistry(NewErrorHandler(logger, nil), monitor, logger)
+
+	hc := &hangingCollector{release: make(chan struct{})}
+	_ = registry.Register(hc)
+	_ = registry.Register(cpuPercentCollector(CollectorCPU, 42))
+	if err := registry.SetTimeout(CollectorHost, 50*time.Millisecond); err != nil {
+		t.Fatalf("SetTimeout() error = %v", err)
+	}
+	if err := registry.SetTimeout(CollectorHost, 0); err == nil {
+		t.Error("超时时间为 0 应返回错误")
+	}
+
+	if info := registry.Collect(context.Background()); len(info.Stale) != 0 || info.HostInfo.Uptime != 1 {
+		t.Fatalf("首次采集结果错误: stale=%v uptime=%d", info.Stale, info.HostInfo.Uptime)
+	}
+
+	// 第二次采集阻塞：超时返回，沿用上次结果，其他采集器不受影响
+	start := time.Now()
+	info := registry.Collect(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("超时未生效，耗时 %v", elapsed)
+	}
+	if info.HostInfo.Uptime != 1 || len(info.Stale) != 1 || info.Stale[0] != CollectorHost {
+		t.Errorf("应沿用上次结果并标记过期: stale=%v uptime=%d", info.Stale, info.HostInfo.Uptime)
+	}
+	if info.CpuInfo.Percent != 42 {
+		t.Errorf("其他采集器结果丢失: %v", info.CpuInfo.Percent)
+	}
+
+	// 上一次采集仍未结束时不再启动新的采集
+	info = registry.Collect(context.Background())
+	if hc.calls.Load() != 2 || len(info.Stale) != 1 {
+		t.Errorf("不应重复启动采集: calls=%d stale=%v", hc.calls.Load(), info.Stale)
+	}
+
+	latency := monitor.GetMetrics().Collectors[CollectorHost]
+	if latency.Runs != 3 || latency.Timeouts != 2 || latency.MaxMs < 50 {
+		t.Errorf("耗时统计错误: %+v", latency)
+	}
+
+	// 阻塞解除后恢复正常
+	close(hc.release)
+	deadline := time.Now().Add(time.Second)
+	for registry.byName[CollectorHost].running.Load() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	info = registry.Collect(context.Background())
+	if len(info.Stale) != 0 || info.HostInfo.Uptime != 3 {
+		t.Errorf("恢复后结果错误: stale=%v uptime=%d", info.Stale, info.HostInfo.Uptime)
+	}
+}
+
+// TestDiskCollector_Quarantine 测试挂载点超时与隔离
+func TestDiskCollector_Quarantine(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	var nfsCalls atomic.Int32
+	now := time.Now()
+
+	c := NewDiskCollector()
+	c.mountTimeout = 20 * time.Millisecond
+	c.now = func() time.Time { return now }
+	c.partitionsFn = func(context.Context) ([]disk.PartitionStat, error) {
+		return []disk.PartitionStat{
+			{Mountpoint: "/", Fstype: "ext4"},
+			{Mountpoint: "/mnt/rclone", Fstype: "fuse.rclone"},
+		}, nil
+	}
+	c.usageFn = func(_ context.Context, path string) (*disk.UsageStat, error) {
+		if path == "/mnt/rclone" {
+			nfsCalls.Add(1)
+			<-release // 模拟失效的挂载点，statfs 不返回
+		}
+		return &disk.UsageStat{Total: 100, Used: 10}, nil
+	}
+
+	for i := 1; i <= mountQuarantineAfter; i++ {
+		p, err := c.Collect(context.Background())
+		if err == nil {
+			t.Fatalf("第 %d 次采集应返回超时错误", i)
+		}
+		info := &model.ServerInfo{}
+		p(info)
+		if len(info.DiskInfo.Partitions) != 1 || info.DiskInfo.Total != 100 {
+			t.Errorf("正常分区应不受影响: %+v", info.DiskInfo)
+		}
+	}
+	// 上一次查询未返回时不重复发起
+	if got := nfsCalls.Load(); got != 1 {
+		t.Errorf("查询次数 = %d; want 1", got)
+	}
+	if mounts := c.QuarantinedMounts(); len(mounts) != 1 || mounts[0] != "/mnt/rclone" {
+		t.Fatalf("QuarantinedMounts() = %v", mounts)
+	}
+
+	// 隔离期内跳过该挂载点，不再报错
+	if _, err := c.Collect(context.Background()); err != nil {
+		t.Errorf("隔离期内不应报错: %v", err)
+	}
+
+	// 隔离期结束后重新尝试
+	now = now.Add(mountQuarantinePeriod + time.Second)
+	if len(c.QuarantinedMounts()) != 0 {
+		t.Error("隔离期结束后应解除隔离")
+	}
+	if _, err := c.Collect(context.Background()); err == nil {
+		t.Error("挂载点仍失效时应返回错误")
+	}
+}
+
+// TestDiskCollector_StaleFallback 测试挂载点超时或隔离时沿用最近一次成功的结果，全部失败时由注册表沿用上一次的结果
+func TestDiskCollector_StaleFallback(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	var hung sync.Map
+	now := time.Now()
+
+	c := NewDiskCollector()
+	c.mountTimeout = 20 * time.Millisecond
+	c.now = func() time.Time { return now }
+	c.partitionsFn = func(context.Context) ([]disk.PartitionStat, error) {
+		return []disk.PartitionStat{
+			{Device: "/dev/sda1", Mountpoint: "/", Fstype: "ext4"},
+			{Device: "nas:/export", Mountpoint: "/mnt/nas", Fstype: "nfs4"},
+		}, nil
+	}
+	c.usageFn = func(_ context.Context, path string) (*disk.UsageStat, error) {
+		if _, ok := hung.Load(path); ok {
+			<-release // 模拟失效的挂载点，statfs 不返回
+		}
+		return &disk.UsageStat{Total: 100, Used: 40}, nil
+	}
+	_ = c.ApplyConfig(&config.AgentConfig{Disk: config.DiskConfig{OnlyMountpoints: []string{"/", "/mnt/nas"}}})
+
+	registry, _ := newTestRegistry()
+	_ = registry.Register(c)
+	_ = registry.SetInterval(CollectorDisk, 0)
+	if info := registry.Collect(context.Background()); info.DiskInfo.Total != 200 || len(info.Stale) != 0 {
+		t.Fatalf("首次采集: total = %d, stale = %v", info.DiskInfo.Total, info.Stale)
+	}
+
+	// NFS 挂载点失效：沿用其上一次的结果并计入总量，标记为过期
+	hung.Store("/mnt/nas", true)
+	for i := 0; i <= mountQuarantineAfter; i++ {
+		info := registry.Collect(context.Background())
+		parts := info.DiskInfo.Partitions
+		if len(parts) != 2 || parts[0].Stale || !parts[1].Stale || parts[1].Used != 40 || info.DiskInfo.Total != 200 {
+			t.Fatalf("第 %d 次: partitions = %s, total = %d", i, sensorJSON(parts), info.DiskInfo.Total)
+		}
+		if !slices.Equal(info.Stale, []string{CollectorDisk}) {
+			t.Errorf("第 %d 次: Stale = %v; want [disk]", i, info.Stale)
+		}
+	}
+	if mounts := c.QuarantinedMounts(); len(mounts) != 1 {
+		t.Fatalf("QuarantinedMounts() = %v", mounts)
+	}
+
+	// 所有挂载点都失效：不返回结果，注册表沿用上一次的结果
+	hung.Store("/", true)
+	p, err := c.Collect(context.Background())
+	if p != nil || err == nil {
+		t.Errorf("全部失败时应返回 nil 与错误: err = %v", err)
+	}
+	info := registry.Collect(context.Background())
+	if info.DiskInfo.Total != 200 || !slices.Contains(info.Stale, CollectorDisk) {
+		t.Errorf("全部失败时: total = %d, stale = %v", info.DiskInfo.Total, info.Stale)
+	}
+}
+
+// TestDiskCollector_Options 测试分区筛选规则与按设备去重；去重只影响总容量，绑定挂载与子卷仍列在分区中
+func TestDiskCollector_Options(t *testing.T) {
+	partitions := []disk.PartitionStat{
+		{Device: "/dev/sda2", Mountpoint: "/", Fstype: "ext4"},
+		{Device: "/dev/sda2", Mountpoint: "/srv/docker", Fstype: "ext4"},
+		{Device: "/dev/sdb1", Mountpoint: "/home", Fstype: "btrfs"},
+		{Device: "/dev/sdb1", Mountpoint: "/data", Fstype: "btrfs"},
+		{Device: "/dev/sdc1", Mountpoint: "/boot", Fstype: "vfat"},
+		{Device: "nas:/export", Mountpoint: "/mnt/nas", Fstype: "nfs4"},
+		{Device: "/dev/sdd1", Mountpoint: "/var/lib/kubelet/pods/x", Fstype: "ext4"},
+	}
+	deviceIDs := map[string]string{"/": "8:2", "/srv/docker": "8:2", "/home": "0:31", "/data": "0:31"}
+	sizes := map[string]uint64{"/": 100, "/srv/docker": 100, "/home": 400, "/data": 400, "/boot": 1, "/mnt/nas": 1000, "/var/lib/kubelet/pods/x": 50}
+
+	tests := []struct {
+		name       string
+		cfg        config.DiskConfig
+		wantMounts []string
+		wantTotal  uint64
+	}{
+		{"默认按设备去重", config.DiskConfig{}, []string{"/", "/srv/docker", "/home", "/data"}, 500},
+		{"按挂载点相加", config.DiskConfig{TotalMode: DiskTotalMount}, []string{"/", "/srv/docker", "/home", "/data"}, 1000},
+		{"自定义文件系统类型", config.DiskConfig{FsTypes: []string{"ext*", "vfat"}}, []string{"/", "/srv/docker", "/boot"}, 101},
+		{"排除文件系统类型", config.DiskConfig{ExcludeFsTypes: []string{"btrfs"}}, []string{"/", "/srv/docker"}, 100},
+		{"挂载点规则", config.DiskConfig{Mountpoints: []string{"/", "re:^/(home|data)$"}, TotalMode: DiskTotalMount}, []string{"/", "/home", "/data"}, 900},
+		{"替换默认排除规则", config.DiskConfig{ExcludeMountpoints: []string{"/srv/*"}}, []string{"/", "/home", "/data", "/var/lib/kubelet/pods/x"}, 550},
+		{"只统计指定挂载点", config.DiskConfig{OnlyMountpoints: []string{"/", "/mnt/nas"}}, []string{"/", "/mnt/nas"}, 1100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewDiskCollector()
+			c.partitionsFn = func(context.Context) ([]disk.PartitionStat, error) { return partitions, nil }
+			c.deviceIDsFn = func() map[string]string { return deviceIDs }
+			c.usageFn = func(_ context.Context, path string) (*disk.UsageStat, error) {
+				return &disk.UsageStat{Total: sizes[path], Used: sizes[path] / 2}, nil
+			}
+			if err := c.ApplyConfig(&config.AgentConfig{Disk: tt.cfg}); err != nil {
+				t.Fatalf("ApplyConfig() error = %v", err)
+			}
+
+			p, err := c.Collect(context.Background())
+			if err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
+			info := &model.ServerInfo{}
+			p(info)
+			var mounts []string
+			for _, part := range info.DiskInfo.Partitions {
+				mounts = append(mounts, part.MountPoint)
+			}
+			if !slices.Equal(mounts, tt.wantMounts) || info.DiskInfo.Total != tt.wantTotal {
+				t.Errorf("分区 = %v, 总容量 = %d; want %v, %d", mounts, info.DiskInfo.Total, tt.wantMounts, tt.wantTotal)
+			}
+		})
+	}
+
+	t.Run("没有设备号时按设备路径去重", func(t *testing.T) {
+		got := dedupeByDevice([]disk.PartitionStat{
+			{Device: "/dev/sda1", Mountpoint: "/var/lib/docker"},
+			{Device: "/dev/sda1", Mountpoint: "/"},
+			{Device: "tmpfs", Mountpoint: "/run"},
+			{Device: "tmpfs", Mountpoint: "/tmp"},
+		}, nil)
+		if len(got) != 3 || got[0].Mountpoint != "/" {
+			t.Errorf("dedupeByDevice() = %+v", got)
+		}
+	})
+
+	registry, _ := newTestRegistry()
+	_ = registry.Register(NewDiskCollector())
+	_ = registry.Register(cpuPercentCollector(CollectorCPU, 1))
+	if err := registry.ApplyConfig(&config.AgentConfig{Disk: config.DiskConfig{TotalMode: "x"}}); err == nil {
+		t.Error("无效配置应返回错误")
+	}
+}
+
+// TestDiskCollector_Health 测试 inode、只读挂载与写入探测
+func TestDiskCollector_Health(t *testing.T) {
+	c := NewDiskCollector()
+	c.deviceIDsFn = func() map[string]string { return nil }
+	c.partitionsFn = func(context.Context) ([]disk.PartitionStat, error) {
+		return []disk.PartitionStat{
+			{Device: "/dev/sda1", Mountpoint: "/", Fstype: "ext4", Opts: []string{"rw", "relatime"}},
+			{Device: "/dev/sda2", Mountpoint: "/var", Fstype: "ext4", Opts: []string{"ro", "relatime"}},
+			{Device: "/dev/sda3", Mountpoint: "/boot/efi", Fstype: "ext4", Opts: []string{"ro"}},
+			{Device: "/dev/sdb1", Mountpoint: "/data", Fstype: "xfs", Opts: []string{"rw"}},
+			{Device: "/dev/sdc1", Mountpoint: "/backup", Fstype: "xfs", Opts: []string{"rw"}},
+			{Device: "/dev/sdd1", Mountpoint: "/srv", Fstype: "xfs", Opts: []string{"rw"}},
+		}, nil
+	}
+	c.usageFn = func(_ context.Context, path string) (*disk.UsageStat, error) {
+		stat := &disk.UsageStat{Total: 100, Used: 40, UsedPercent: 40, InodesTotal: 1000, InodesUsed: 100, InodesUsedPercent: 10}
+		if path == "/" {
+			stat.InodesUsed, stat.InodesUsedPercent = 1000, 100
+		}
+		return stat, nil
+	}
+	var probed []string
+	var mu sync.Mutex
+	c.probeFn = func(mountpoint string) error {
+		mu.Lock()
+		probed = append(probed, mountpoint)
+		mu.Unlock()
+		switch mountpoint {
+		case "/data":
+			return &os.PathError{Op: "open", Path: "/data/.sss-write-probe-1", Err: syscall.ENOSPC}
+		case "/backup":
+			return &os.PathError{Op: "open", Path: "/backup/.sss-write-probe-1", Err: syscall.EROFS}
+		}
+		return nil
+	}
+	cfg := &config.AgentConfig{Disk: config.DiskConfig{ReadOnlyMountpoints: []string{"/boot/*"}, WriteProbe: true}}
+	if err := c.ApplyConfig(cfg); err != nil {
+		t.Fatalf("ApplyConfig() error = %v", err)
+	}
+
+	p, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	info := &model.ServerInfo{}
+	p(info)
+
+	want := map[string]string{
+		"/":         model.PartitionHealthInodesCritical,
+		"/var":      model.PartitionHealthReadOnly,
+		"/boot/efi": model.PartitionHealthOK,
+		"/data":     model.PartitionHealthWriteFailed,
+		"/backup":   model.PartitionHealthReadOnly,
+		"/srv":      model.PartitionHealthOK,
+	}
+	for _, part := range info.DiskInfo.Partitions {
+		if got := part.Health(); got != want[part.MountPoint] {
+			t.Errorf("%s Health() = %s; want %s (%+v)", part.MountPoint, got, want[part.MountPoint], part)
+		}
+	}
+	if root := info.DiskInfo.Partitions[0]; root.InodesTotal != 1000 || root.InodesUsedPercent != 100 {
+		t.Errorf("inode 映射错误: %+v", root)
+	}
+	if data := info.DiskInfo.Partitions[3]; data.WriteError != syscall.ENOSPC.Error() {
+		t.Errorf("WriteError = %q; 不应包含临时文件路径", data.WriteError)
+	}
+	// 已知只读的分区不探测
+	sort.Strings(probed)
+	if !slices.Equal(probed, []string{"/", "/backup", "/data", "/srv"}) {
+		t.Errorf("探测的挂载点 = %v", probed)
+	}
+
+	// 预期只读的挂载点不标记为异常
+	resp := model.NewRespPartitions(info.DiskInfo.Partitions)
+	if len(resp) != 6 || resp[2].Health != model.PartitionHealthOK || resp[1].Health != model.PartitionHealthReadOnly {
+		t.Errorf("NewRespPartitions() 健康状态错误: %+v %+v", resp[1], resp[2])
+	}
+}
+
+// TestProbeWrite 测试写入探测不留下临时文件
+func TestProbeWrite(t *testing.T) {
+	dir := t.TempDir()
+	if err := probeWrite(dir); err != nil {
+		t.Fatalf("probeWrite() error = %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("探测后留下了文件: %v", entries)
+	}
+	if err := probeWrite(filepath.Join(dir, "missing")); err == nil {
+		t.Error("目录不存在时应返回错误")
+	}
+}
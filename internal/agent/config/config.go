@@ -1,16 +1,46 @@
 package config
 
 type AgentConfig struct {
-	//服务器地址
-	ServerAddr string `yaml:"serverAddr" validate:"required"`
+	//服务器地址；transport 为 pull 时可不填
+	ServerAddr string `yaml:"serverAddr"`
+	//上报方式：ws（WebSocket 长连接）、http（HTTP POST 批量推送）或 pull（仅由面板拉取）；为空时根据 serverAddr 的协议自动选择
+	Transport string `yaml:"transport"`
+	//HTTP 推送模式下每批上报的数据条数；默认5，最大100
+	PushBatchSize int `yaml:"pushBatchSize"`
+	//拉取服务监听地址，如 :8901；设置后面板可通过 http://host:port/server-info 拉取数据，transport 为 pull 时默认 :8901
+	PullListen string `yaml:"pullListen"`
 	//每台机子对应id；唯一；在服务端配置
 	ServerId string `yaml:"serverId" validate:"required"`
 	//对应服务器配置的；做授权
 	AuthSecret string `yaml:"authSecret" validate:"required"`
 	//上报间隔，单位秒；默认2秒，最小值2
 	ReportTimeInterval int `yaml:"reportTimeInterval"`
+	//高频采样间隔，单位毫秒；0 表示不开启（每次上报采集一次）。开启后按该间隔采集，
+	//每个上报周期发送最新一次采样及周期内 CPU、内存、负载、网速等的 min/avg/max/p95
+	SampleIntervalMs int `yaml:"sampleIntervalMs"`
+	//网速平滑的时间常数，单位秒；0 表示不平滑（默认）。开启后网速按指数加权移动平均（EWMA）计算，
+	//时间常数越大曲线越平滑，对突发流量的反应也越慢
+	NetRateSmoothing int `yaml:"netRateSmoothing"`
 	//禁用根据IP查询服务器区域信息，默认false
 	DisableIP2Region bool `yaml:"disableIP2Region"`
+	//采集器配置，key 为采集器名称（host、load、cpu、cpu_info、memory、swap、disk、diskio、network、sockets、processes、process_watch、checks、sensors）；未配置的采集器使用默认值
+	Collectors map[string]CollectorConfig `yaml:"collectors"`
+	//磁盘分区的筛选与总容量统计方式
+	Disk DiskConfig `yaml:"disk"`
+	//进程数量统计与 CPU、内存占用排行
+	Processes ProcessConfig `yaml:"processes"`
+	//硬件传感器（温度、风扇转速、功耗）采集
+	Sensors SensorConfig `yaml:"sensors"`
+	//需要保持运行的进程，每次上报都会检查其运行状态、实例数、CPU/内存与重启情况
+	WatchProcesses []WatchProcess `yaml:"watchProcesses"`
+	//本机发起的可用性检查（HTTP、TCP、TLS 证书），结果随上报发送并由面板保存历史
+	Checks []CheckConfig `yaml:"checks"`
+	//WebSocket 上报编码：json、msgpack 或 cbor；默认 json，面板不支持时自动回退到 json
+	Encoding string `yaml:"encoding"`
+	//禁用 WebSocket 压缩（permessage-deflate），默认启用
+	DisableCompression bool `yaml:"disableCompression"`
+	//出站代理地址，支持 http://、https://、socks5://，可带 user:password@；为空时读取 HTTPS_PROXY/NO_PROXY 等环境变量
+	Proxy string `yaml:"proxy"`
 
 	//日志配置,日志级别
 	LogPath string `yaml:"logPath"`
@@ -18,6 +48,99 @@ type AgentConfig struct {
 	LogLevel string `yaml:"logLevel"`
 }
 
+// CollectorConfig 单个采集器配置
+type CollectorConfig struct {
+	//是否启用；默认启用
+	Enabled *bool `yaml:"enabled"`
+	//采集周期，单位秒；0 使用采集器默认周期
+	Interval int `yaml:"interval"`
+	//采集超时，单位秒；0 使用默认值 5 秒，超时后沿用上一次结果并标记为过期
+	Timeout int `yaml:"timeout"`
+	//只采集名称匹配的对象（磁盘设备、网卡等），支持 * ? [] 通配符，以 re: 开头时按正则表达式匹配；为空时使用采集器的默认规则
+	Include []string `yaml:"include"`
+	//排除名称匹配的对象，规则同 include
+	Exclude []string `yaml:"exclude"`
+}
+
+// DiskConfig 磁盘分区采集配置
+// 文件系统类型与挂载点规则支持 * ? [] 通配符，以 re: 开头时按正则表达式匹配
+type DiskConfig struct {
+	//只统计这些文件系统类型；为空时使用默认列表（ext4、xfs、btrfs、zfs、ntfs 等）
+	FsTypes []string `yaml:"fsTypes"`
+	//排除的文件系统类型
+	ExcludeFsTypes []string `yaml:"excludeFsTypes"`
+	//只统计匹配的挂载点；为空时不限制
+	Mountpoints []string `yaml:"mountpoints"`
+	//排除的挂载点；为空时排除 /var/lib/kubelet 下的挂载点
+	ExcludeMountpoints []string `yaml:"excludeMountpoints"`
+	//只统计这些挂载点（完整路径），设置后忽略以上规则，可用于统计 nfs 等默认不统计的文件系统
+	OnlyMountpoints []string `yaml:"onlyMountpoints"`
+	//总容量统计方式：device 按设备去重（默认，绑定挂载与 btrfs 子卷只计一次），mount 将各挂载点相加（旧版本行为）
+	TotalMode string `yaml:"totalMode"`
+	//预期以只读方式挂载的挂载点（如 /boot/efi），这些挂载点只读时不标记为异常
+	ReadOnlyMountpoints []string `yaml:"readOnlyMountpoints"`
+	//写入探测：每次采集时在各挂载点创建并删除一个临时文件，验证文件系统确实可写；默认关闭
+	WriteProbe bool `yaml:"writeProbe"`
+}
+
+// ProcessConfig 进程采集配置
+type ProcessConfig struct {
+	//CPU 与内存占用排行各上报的进程数；默认5，最大50
+	TopN int `yaml:"topN"`
+	//命令行最大长度（字符），超出部分截断；默认200
+	CmdlineMaxLength int `yaml:"cmdlineMaxLength"`
+	//不上报命令行，只上报进程名；默认false
+	HideCmdline bool `yaml:"hideCmdline"`
+	//命令行中需要隐藏的内容（正则表达式），含捕获组时只替换第一个捕获组，否则替换整个匹配；
+	//为空时隐藏 password、token、secret 等参数的值
+	Redact []string `yaml:"redact"`
+}
+
+// SensorConfig 硬件传感器采集配置
+type SensorConfig struct {
+	//sysfs 挂载点，读取其下的 class/hwmon 与 class/thermal；为空时使用环境变量 HOST_SYS，再为空时使用 /sys。
+	//在容器中运行时可将宿主机的 /sys 挂载到其他路径（如 /host/sys）后在此指定
+	SysfsRoot string `yaml:"sysfsRoot"`
+}
+
+// WatchProcess 受监控的进程，process 与 pidfile 二选一
+type WatchProcess struct {
+	//显示名称；为空时使用 process，或 pidfile 的文件名（去掉 .pid 后缀）
+	Name string `yaml:"name"`
+	//进程名，如 nginx、mysqld；匹配所有同名进程
+	Process string `yaml:"process"`
+	//pidfile 路径，如 /run/nginx.pid；只检查其中记录的进程
+	Pidfile string `yaml:"pidfile"`
+	//运行的实例数少于该值时视为异常；默认1
+	MinCount int `yaml:"minCount"`
+}
+
+// CheckConfig 可用性检查配置
+type CheckConfig struct {
+	//显示名称；为空时使用 url 或 target
+	Name string `yaml:"name"`
+	//检查类型：http（GET 请求）、tcp（建立连接）或 tls（证书有效期）
+	Type string `yaml:"type"`
+	//http 检查的地址，如 https://example.com/health
+	URL string `yaml:"url"`
+	//tcp、tls 检查的地址，格式 host:port
+	Target string `yaml:"target"`
+	//检查周期，单位秒；默认60
+	Interval int `yaml:"interval"`
+	//超时时间，单位秒；默认10，不能大于检查周期
+	Timeout int `yaml:"timeout"`
+	//http 检查期望的状态码，跟随重定向后按最终响应判断；为空时 200-399 视为正常
+	ExpectStatus []int `yaml:"expectStatus"`
+	//http 检查的响应内容需匹配的正则表达式（只读取前 1MB）
+	BodyRegex string `yaml:"bodyRegex"`
+	//tls 检查：证书剩余有效期少于该天数时标记为警告；默认14
+	WarnDays int `yaml:"warnDays"`
+	//tls 检查使用的 SNI 主机名；为空时使用 target 的主机名
+	ServerName string `yaml:"serverName"`
+	//不校验证书链与主机名（自签名证书），仍然检查有效期
+	InsecureSkipVerify bool `yaml:"insecureSkipVerify"`
+}
+
 // Validate 实现 ConfigLoader 接口 - 验证配置
 func (c *AgentConfig) Validate() error {
 	// 基础验证会在配置加载时自动完成
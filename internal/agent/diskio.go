@@ -0,0 +1,129 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ruanun/simple-server-status/pkg/model"
+	"github.com/shirou/gopsutil/v4/disk"
+)
+
+// defaultDiskIOExclude 未配置过滤规则时排除的虚拟设备
+// dm-* 为 LVM/加密卷，其 I/O 已计入下层物理磁盘
+var defaultDiskIOExclude = []string{"loop*", "ram*", "zram*", "sr*", "fd*", "dm-*"}
+
+// DiskIOCollector 磁盘 I/O 采集器
+// 根据两次采集之间 disk.IOCounters 的差值计算各物理磁盘的吞吐、IOPS、await 和忙碌占比；分区的 I/O 已计入所属磁盘，不单独上报
+type DiskIOCollector struct {
+	baseCollector
+	countersFn func(ctx context.Context) (map[string]disk.IOCountersStat, error)
+	now        func() time.Time
+
+	mu       sync.Mutex
+	filter   *NameFilter
+	last     map[string]disk.IOCountersStat
+	lastTime time.Time
+}
+
+// NewDiskIOCollector 创建磁盘 I/O 采集器
+func NewDiskIOCollector() *DiskIOCollector {
+	filter, _ := NewNameFilter(nil, defaultDiskIOExclude)
+	return &DiskIOCollector{
+		baseCollector: baseCollector{name: CollectorDiskIO},
+		countersFn: func(ctx context.Context) (map[string]disk.IOCountersStat, error) {
+			return disk.IOCountersWithContext(ctx)
+		},
+		now:    time.Now,
+		filter: filter,
+	}
+}
+
+// SetFilter 设置设备过滤规则；nil 时恢复默认规则
+func (c *DiskIOCollector) SetFilter(filter *NameFilter) {
+	if filter == nil {
+		filter, _ = NewNameFilter(nil, defaultDiskIOExclude)
+	}
+	c.mu.Lock()
+	c.filter = filter
+	c.mu.Unlock()
+}
+
+// Collect 采集磁盘 I/O；首次采集只记录计数器，从第二次开始上报
+func (c *DiskIOCollector) Collect(ctx context.Context) (PartialInfo, error) {
+	counters, err := c.countersFn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取磁盘 I/O 统计失败: %w", err)
+	}
+	now := c.now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elapsed := now.Sub(c.lastTime)
+	var result []*model.DiskIO
+	if c.last != nil && elapsed > 0 {
+		for name, cur := range counters {
+			if !c.filter.Match(name) || isDiskPartition(name, counters) {
+				continue
+			}
+			last, ok := c.last[name]
+			if !ok {
+				continue
+			}
+			if io, ok := diskIODelta(name, last, cur, elapsed); ok {
+				result = append(result, io)
+			}
+		}
+		sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	}
+	c.last = counters
+	c.lastTime = now
+
+	return func(s *model.ServerInfo) { s.DiskIO = result }, nil
+}
+
+// diskIODelta 计算单个设备的 I/O 负载；计数器回绕或设备重置时返回 false
+func diskIODelta(name string, last, cur disk.IOCountersStat, elapsed time.Duration) (*model.DiskIO, bool) {
+	if cur.ReadBytes < last.ReadBytes || cur.WriteBytes < last.WriteBytes ||
+		cur.ReadCount < last.ReadCount || cur.WriteCount < last.WriteCount ||
+		cur.ReadTime < last.ReadTime || cur.WriteTime < last.WriteTime || cur.IoTime < last.IoTime {
+		return nil, false
+	}
+
+	seconds := elapsed.Seconds()
+	ops := (cur.ReadCount - last.ReadCount) + (cur.WriteCount - last.WriteCount)
+	io := &model.DiskIO{
+		Name:        name,
+		ReadSpeed:   uint64(float64(cur.ReadBytes-last.ReadBytes) / seconds),
+		WriteSpeed:  uint64(float64(cur.WriteBytes-last.WriteBytes) / seconds),
+		ReadIOPS:    float64(cur.ReadCount-last.ReadCount) / seconds,
+		WriteIOPS:   float64(cur.WriteCount-last.WriteCount) / seconds,
+		BusyPercent: math.Min(100, float64(cur.IoTime-last.IoTime)/(seconds*1000)*100),
+	}
+	if ops > 0 {
+		io.AwaitMs = float64((cur.ReadTime-last.ReadTime)+(cur.WriteTime-last.WriteTime)) / float64(ops)
+	}
+	return io, true
+}
+
+// isDiskPartition 判断设备是否为其他设备的分区，如 sda1、nvme0n1p1、mmcblk0p2
+func isDiskPartition(name string, counters map[string]disk.IOCountersStat) bool {
+	base := strings.TrimRight(name, "0123456789")
+	if base == name || base == "" {
+		return false
+	}
+	if _, ok := counters[base]; ok {
+		return true
+	}
+	// nvme0n1p1、mmcblk0p1 形式的分区名
+	if strings.HasSuffix(base, "p") {
+		_, ok := counters[strings.TrimSuffix(base, "p")]
+		return ok
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package internal
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ruanun/simple-server-status/internal/agent/config"
+	"github.com/ruanun/simple-server-status/pkg/model"
+	"github.com/shirou/gopsutil/v4/disk"
+)
+
+// TestNameFilter 测试名称过滤规则
+func TestNameFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		input   string
+		want    bool
+	}{
+		{"无规则", nil, nil, "sda", true},
+		{"排除通配符", nil, []string{"loop*"}, "loop0", false},
+		{"未被排除", nil, []string{"loop*"}, "sda", true},
+		{"包含规则", []string{"nvme*", "sd?"}, nil, "sdb", true},
+		{"不在包含规则中", []string{"nvme*"}, nil, "sda", false},
+		{"包含后排除", []string{"sd*"}, []string{"sdc"}, "sdc", false},
+		{"正则包含", []string{`re:^(eth|ens)\d+$`}, nil, "ens18", true},
+		{"正则不匹配", []string{`re:^(eth|ens)\d+$`}, nil, "eth0.100", false},
+		{"正则排除", nil, []string{"re:^veth"}, "veth1a2b", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewNameFilter(tt.include, tt.exclude)
+			if err != nil {
+				t.Fatalf("NewNameFilter() error = %v", err)
+			}
+			if got := f.Match(tt.input); got != tt.want {
+				t.Errorf("Match(%s) = %v; want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewNameFilter([]string{"sd["}, nil); err == nil {
+		t.Error("无效规则应返回错误")
+	}
+	if _, err := NewNameFilter(nil, []string{"re:(eth"}); err == nil {
+		t.Error("无效正则应返回错误")
+	}
+	var nilFilter *NameFilter
+	if !nilFilter.Match("sda") {
+		t.Error("nil 过滤器应保留所有名称")
+	}
+}
+
+// TestCollectorRegistry_SetFilter 测试过滤规则的配置
+func TestCollectorRegistry_SetFilter(t *testing.T) {
+	registry, _ := newTestRegistry()
+	diskIO := NewDiskIOCollector()
+	_ = registry.Register(diskIO)
+	_ = registry.Register(cpuPercentCollector(CollectorCPU, 1))
+
+	err := registry.Configure(map[string]config.CollectorConfig{CollectorDiskIO: {Include: []string{"nvme*"}}})
+	if err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	if diskIO.filter.Match("sda") || !diskIO.filter.Match("nvme0n1") {
+		t.Error("过滤规则未生效")
+	}
+	if err := registry.SetFilter(CollectorCPU, []string{"x"}, nil); err == nil {
+		t.Error("不支持过滤的采集器应返回错误")
+	}
+	if err := registry.SetFilter(CollectorDiskIO, nil, []string{"["}); err == nil {
+		t.Error("无效规则应返回错误")
+	}
+}
+
+// TestDiskIOCollector 测试磁盘 I/O 的差值计算
+func TestDiskIOCollector(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	samples := []map[string]disk.IOCountersStat{
+		{
+			"sda":     {ReadBytes: 1000, WriteBytes: 2000, ReadCount: 10, WriteCount: 20, ReadTime: 100, WriteTime: 200, IoTime: 1000},
+			"sda1":    {ReadBytes: 1000, WriteBytes: 2000},
+			"nvme0n1": {ReadBytes: 0, IoTime: 0},
+			"loop0":   {ReadBytes: 0},
+		},
+		{
+			// 2 秒内：读 4MiB/写 2MiB，读 100 次/写 300 次，读写耗时共 2000ms，忙碌 1500ms
+			"sda":       {ReadBytes: 1000 + 4<<20, WriteBytes: 2000 + 2<<20, ReadCount: 110, WriteCount: 320, ReadTime: 600, WriteTime: 1700, IoTime: 2500},
+			"sda1":      {ReadBytes: 1000 + 4<<20, WriteBytes: 2000 + 2<<20},
+			"nvme0n1":   {IoTime: 5000}, // 忙碌时间超过采集间隔时不超过 100
+			"nvme0n1p1": {},
+			"loop0":     {ReadBytes: 1 << 20},
+		},
+	}
+	call := 0
+	c := NewDiskIOCollector()
+	c.countersFn = func(context.Context) (map[string]disk.IOCountersStat, error) { return samples[call], nil }
+	c.now = func() time.Time { return now.Add(time.Duration(call) * 2 * time.Second) }
+
+	apply := func(p PartialInfo) *model.ServerInfo {
+		info := &model.ServerInfo{}
+		info.FillEmpty()
+		p(info)
+		return info
+	}
+
+	p, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if info := apply(p); info.DiskIO != nil {
+		t.Errorf("首次采集不应有结果: %+v", info.DiskIO)
+	}
+
+	call = 1
+	p, err = c.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	io := apply(p).DiskIO
+	if len(io) != 2 || io[0].Name != "nvme0n1" || io[1].Name != "sda" {
+		t.Fatalf("应只包含物理磁盘且按名称排序: %+v", io)
+	}
+	sda := io[1]
+	if sda.ReadSpeed != 2<<20 || sda.WriteSpeed != 1<<20 || sda.ReadIOPS != 50 || sda.WriteIOPS != 150 {
+		t.Errorf("吞吐或 IOPS 错误: %+v", sda)
+	}
+	if math.Abs(sda.AwaitMs-5) > 1e-9 || math.Abs(sda.BusyPercent-75) > 1e-9 {
+		t.Errorf("await 或忙碌占比错误: %+v", sda)
+	}
+	if io[0].BusyPercent != 100 {
+		t.Errorf("忙碌占比应不超过 100: %v", io[0].BusyPercent)
+	}
+
+	// 计数器重置（如设备重新挂载）时跳过该设备
+	call = 0
+	c.now = func() time.Time { return now.Add(4 * time.Second) }
+	p, _ = c.Collect(context.Background())
+	if info := apply(p); len(info.DiskIO) != 0 {
+		t.Errorf("计数器重置时应跳过: %+v", info.DiskIO)
+	}
+}
@@ -0,0 +1,89 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// regexPrefix 以此前缀开头的过滤规则按正则表达式匹配，如 "re:^(eth|ens)\d+$"
+const regexPrefix = "re:"
+
+// NameFilter 按名称过滤采集对象（磁盘设备、网卡、挂载点等），支持 path.Match 通配符与 re: 前缀的正则表达式
+// include 非空时只保留匹配其中任一规则的名称，之后排除匹配 exclude 的名称
+type NameFilter struct {
+	include []namePattern
+	exclude []namePattern
+}
+
+// namePattern 单条过滤规则，re 非 nil 时按正则匹配，否则按通配符匹配
+type namePattern struct {
+	glob string
+	re   *regexp.Regexp
+}
+
+// NewNameFilter 创建名称过滤器，规则无效时返回错误
+func NewNameFilter(include, exclude []string) (*NameFilter, error) {
+	inc, err := compilePatterns(include)
+	if err != nil {
+		return nil, err
+	}
+	exc, err := compilePatterns(exclude)
+	if err != nil {
+		return nil, err
+	}
+	return &NameFilter{include: inc, exclude: exc}, nil
+}
+
+func compilePatterns(patterns []string) ([]namePattern, error) {
+	result := make([]namePattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		if expr, ok := strings.CutPrefix(pattern, regexPrefix); ok {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("无效的过滤规则 %q: %w", pattern, err)
+			}
+			result = append(result, namePattern{re: re})
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("无效的过滤规则 %q: %w", pattern, err)
+		}
+		result = append(result, namePattern{glob: pattern})
+	}
+	return result, nil
+}
+
+// Match 判断名称是否保留；nil 过滤器保留所有名称
+func (f *NameFilter) Match(name string) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.include) > 0 && !matchAny(f.include, name) {
+		return false
+	}
+	return !matchAny(f.exclude, name)
+}
+
+func matchAny(patterns []namePattern, name string) bool {
+	for _, p := range patterns {
+		if p.re != nil {
+			if p.re.MatchString(name) {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(p.glob, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// FilteredCollector 支持按名称过滤采集对象的采集器
+type FilteredCollector interface {
+	Collector
+	// SetFilter 设置过滤器；nil 表示恢复采集器的默认过滤规则
+	SetFilter(filter *NameFilter)
+}
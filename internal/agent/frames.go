@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"sync"
+
+	"github.com/ruanun/simple-server-status/pkg/model"
+)
+
+// FrameEncoder 将 ServerInfo 拆分为完整帧与指标帧
+// 连接建立后的第一帧以及硬件与系统信息变化（哈希不同）时发送完整帧，其余只发送指标
+type FrameEncoder struct {
+	mu       sync.Mutex
+	lastHash string
+
+	inventorySize int // 最近一次完整帧编码后的字节数
+
+	inventoryFrames int64
+	metricFrames    int64
+	bytesSaved      int64 // 指标帧比完整帧少发送的字节数
+}
+
+// NewFrameEncoder 创建分帧编码器
+func NewFrameEncoder() *FrameEncoder {
+	return &FrameEncoder{}
+}
+
+// Reset 使下一帧发送完整数据（连接建立时调用）
+func (e *FrameEncoder) Reset() {
+	e.mu.Lock()
+	e.lastHash = ""
+	e.mu.Unlock()
+}
+
+// Encode 生成上报帧
+func (e *FrameEncoder) Encode(info *model.ServerInfo) *model.ReportFrame {
+	hash := info.InventoryHash()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if hash != e.lastHash {
+		return &model.ReportFrame{Type: model.FrameTypeInventory, Hash: hash, Data: info}
+	}
+
+	return &model.ReportFrame{Type: model.FrameTypeMetrics, Hash: hash, Data: info.MetricsOnly()}
+}
+
+// Sent 记录已加入发送队列的帧及其编码后的字节数
+// 完整帧加入队列后才记录哈希，未能发送的完整帧会在下一次上报时重新发送；
+// 节省的字节数按最近一次完整帧与本次指标帧的实际大小之差计算
+func (e *FrameEncoder) Sent(frame *model.ReportFrame, size int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if frame.Type == model.FrameTypeInventory {
+		e.lastHash = frame.Hash
+		e.inventoryFrames++
+		e.inventorySize = size
+		return
+	}
+	e.metricFrames++
+	if saved := e.inventorySize - size; saved > 0 {
+		e.bytesSaved += int64(saved)
+	}
+}
+
+// Stats 获取分帧统计
+func (e *FrameEncoder) Stats() (inventoryFrames, metricFrames, bytesSaved int64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.inventoryFrames, e.metricFrames, e.bytesSaved
+}
@@ -0,0 +1,293 @@
+package internal
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ruanun/simple-server-status/internal/agent/config"
+	"github.com/ruanun/simple-server-status/pkg/model"
+	"github.com/shirou/gopsutil/v4/load"
+	"go.uber.org/zap"
+)
+
+func newFrameTestInfo(platform string, percent float64) *model.ServerInfo {
+	return &model.ServerInfo{
+		HostInfo: &model.HostInfo{
+			Platform: platform, PlatformVersion: "12", KernelVersion: "6.1.0", KernelArch: "x86_64",
+			Uptime: 3600, BootTime: 1700000000, AvgStat: &load.AvgStat{Load1: 0.5},
+		},
+		CpuInfo:           &model.CpuInfo{Percent: percent, Info: []string{"Intel(R) Xeon(R) CPU E5-2680 v4 @ 2.40GHz x 2 "}},
+		VirtualMemoryInfo: &model.VirtualMemoryInfo{Total: 1024, Used: 512, UsedPercent: 50},
+		SwapMemoryInfo:    &model.SwapMemoryInfo{},
+		DiskInfo: &model.DiskInfo{Total: 100, Used: 10, UsedPercent: 10, Partitions: []*model.Partition{
+			{MountPoint: "/", Fstype: "ext4", Total: 100, Used: 10, Free: 90, UsedPercent: 10},
+		}},
+		NetworkInfo: &model.NetworkInfo{NetInSpeed: 100},
+	}
+}
+
+// encodeSent 生成上报帧并按 JSON 大小记录为已发送
+func encodeSent(e *FrameEncoder, info *model.ServerInfo) *model.ReportFrame {
+	frame := e.Encode(info)
+	data, _ := json.Marshal(frame)
+	e.Sent(frame, len(data))
+	return frame
+}
+
+// TestFrameEncoder 测试完整帧与指标帧的切换
+func TestFrameEncoder(t *testing.T) {
+	e := NewFrameEncoder()
+
+	first := encodeSent(e, newFrameTestInfo("debian", 10))
+	if first.Type != model.FrameTypeInventory || first.Hash == "" {
+		t.Fatalf("第一帧应为完整帧: %+v", first)
+	}
+
+	second := encodeSent(e, newFrameTestInfo("debian", 20))
+	if second.Type != model.FrameTypeMetrics || second.Hash != first.Hash {
+		t.Fatalf("硬件信息未变化时应为指标帧: %+v", second)
+	}
+	data := second.Data
+	if data.CpuInfo.Percent != 20 || data.HostInfo.Uptime != 3600 || data.HostInfo.AvgStat.Load1 != 0.5 ||
+		data.DiskInfo.UsedPercent != 10 || data.NetworkInfo.NetInSpeed != 100 {
+		t.Errorf("指标帧缺少指标: %+v", data)
+	}
+	if data.HostInfo.Platform != "" || data.CpuInfo.Info != nil {
+		t.Errorf("指标帧不应包含硬件与系统信息: %+v", data)
+	}
+	if len(data.DiskInfo.Partitions) != 1 || data.DiskInfo.Partitions[0].Used != 10 {
+		t.Errorf("指标帧应包含分区用量: %+v", data.DiskInfo)
+	}
+	if p := data.DiskInfo.Partitions[0]; p.MountPoint != "" || p.Fstype != "" {
+		t.Errorf("指标帧不应包含挂载点与文件系统类型: %+v", p)
+	}
+
+	full, _ := json.Marshal(first)
+	delta, _ := json.Marshal(second)
+	if len(delta) >= len(full) {
+		t.Errorf("指标帧应更小: %d >= %d", len(delta), len(full))
+	}
+	if _, _, bytesSaved := e.Stats(); bytesSaved != int64(len(full)-len(delta)) {
+		t.Errorf("bytesSaved = %d; want %d", bytesSaved, len(full)-len(delta))
+	}
+
+	// 硬件与系统信息变化时重新发送完整帧
+	if third := encodeSent(e, newFrameTestInfo("ubuntu", 20)); third.Type != model.FrameTypeInventory || third.Hash == first.Hash {
+		t.Errorf("信息变化后应为完整帧: %+v", third)
+	}
+
+	// 未加入发送队列的完整帧在下一次上报时重新发送
+	if dropped := e.Encode(newFrameTestInfo("centos", 20)); dropped.Type != model.FrameTypeInventory {
+		t.Errorf("信息变化后应为完整帧: %+v", dropped)
+	}
+	if resent := encodeSent(e, newFrameTestInfo("centos", 20)); resent.Type != model.FrameTypeInventory {
+		t.Errorf("完整帧未发送时应重新发送: %+v", resent)
+	}
+
+	// 重新连接后第一帧为完整帧
+	e.Reset()
+	if fourth := encodeSent(e, newFrameTestInfo("centos", 20)); fourth.Type != model.FrameTypeInventory {
+		t.Errorf("Reset 后应为完整帧: %+v", fourth)
+	}
+
+	inventoryFrames, metricFrames, _ := e.Stats()
+	if inventoryFrames != 4 || metricFrames != 1 {
+		t.Errorf("Stats() = %d, %d", inventoryFrames, metricFrames)
+	}
+}
+
+// TestFrameEncoder_Partitions 测试分区用量变化不影响哈希，挂载点或容量变化时重新发送完整帧
+func TestFrameEncoder_Partitions(t *testing.T) {
+	e := NewFrameEncoder()
+	first := encodeSent(e, newFrameTestInfo("debian", 10))
+
+	info := newFrameTestInfo("debian", 10)
+	p := info.DiskInfo.Partitions[0]
+	p.Used, p.Free, p.UsedPercent, p.InodesUsed, p.ReadOnly = 60, 40, 60, 1000, true
+	if second := encodeSent(e, info); second.Type != model.FrameTypeMetrics || second.Hash != first.Hash {
+		t.Errorf("分区用量变化时应为指标帧: %+v", second)
+	}
+
+	info = newFrameTestInfo("debian", 10)
+	info.DiskInfo.Partitions = append(info.DiskInfo.Partitions, &model.Partition{MountPoint: "/data", Fstype: "xfs", Total: 500})
+	if third := encodeSent(e, info); third.Type != model.FrameTypeInventory {
+		t.Errorf("新增分区时应为完整帧: %+v", third)
+	}
+
+	// 按顺序补全挂载点与文件系统类型，用量取自指标帧
+	m := newFrameTestInfo("debian", 10)
+	m.DiskInfo.Partitions = append(m.DiskInfo.Partitions, &model.Partition{MountPoint: "/data", Fstype: "xfs", Total: 500, Used: 50})
+	m = m.MetricsOnly()
+	m.MergeInventory(info)
+	if p := m.DiskInfo.Partitions[1]; p.MountPoint != "/data" || p.Fstype != "xfs" || p.Used != 50 {
+		t.Errorf("合并后分区 = %+v", p)
+	}
+	if info.DiskInfo.Partitions[1].Used != 0 {
+		t.Error("合并不应修改已存储的分区")
+	}
+
+	// 旧版本 Agent 的指标帧不含分区时沿用已存储的分区
+	m = newFrameTestInfo("debian", 10).MetricsOnly()
+	m.DiskInfo.Partitions = nil
+	m.MergeInventory(info)
+	if len(m.DiskInfo.Partitions) != 2 {
+		t.Errorf("合并后分区 = %d; want 2", len(m.DiskInfo.Partitions))
+	}
+}
+
+// TestFrameEncoder_CPU 测试核心数属于硬件信息，各核心占用与各状态占比属于指标
+func TestFrameEncoder_CPU(t *testing.T) {
+	info := newFrameTestInfo("debian", 10)
+	info.CpuInfo.LogicalCores, info.CpuInfo.PhysicalCores = 8, 4
+	info.CpuInfo.PerCore = []float64{5, 15}
+	info.CpuInfo.Times = &model.CpuTimes{User: 8, Iowait: 1, Steal: 1, Idle: 90}
+	info.CpuInfo.Mhz = 2400
+
+	m := info.MetricsOnly()
+	if len(m.CpuInfo.PerCore) != 2 || m.CpuInfo.Times == nil || m.CpuInfo.Mhz != 2400 {
+		t.Errorf("指标帧缺少 CPU 指标: %+v", m.CpuInfo)
+	}
+	if m.CpuInfo.LogicalCores != 0 || m.CpuInfo.PhysicalCores != 0 {
+		t.Errorf("指标帧不应包含核心数: %+v", m.CpuInfo)
+	}
+	m.MergeInventory(info)
+	if m.CpuInfo.LogicalCores != 8 || m.CpuInfo.PhysicalCores != 4 || m.CpuInfo.Times.Steal != 1 {
+		t.Errorf("合并后 CPU 信息错误: %+v", m.CpuInfo)
+	}
+
+	before := info.InventoryHash()
+	info.CpuInfo.LogicalCores = 16
+	if after := info.InventoryHash(); after == before {
+		t.Error("核心数变化时哈希应变化")
+	}
+}
+
+// TestCodecRoundTrip 测试各编码的编解码结果一致
+func TestCodecRoundTrip(t *testing.T) {
+	for _, name := range []string{model.EncodingJSON, model.EncodingMsgpack, model.EncodingCBOR} {
+		t.Run(name, func(t *testing.T) {
+			codec, err := model.NewCodec(name)
+			if err != nil {
+				t.Fatalf("NewCodec() error = %v", err)
+			}
+			data, err := codec.Marshal(NewFrameEncoder().Encode(newFrameTestInfo("debian", 12.5)))
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			var frame model.ReportFrame
+			if err := codec.Unmarshal(data, &frame); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			info := frame.Data
+			if frame.Type != model.FrameTypeInventory || info.CpuInfo.Percent != 12.5 || info.HostInfo.Platform != "debian" ||
+				info.HostInfo.AvgStat.Load1 != 0.5 || info.DiskInfo.Partitions[0].MountPoint != "/" || info.VirtualMemoryInfo.Total != 1024 {
+				t.Errorf("解码结果不一致: %+v", info)
+			}
+		})
+	}
+
+	if _, err := model.NewCodec("protobuf"); err == nil {
+		t.Error("不支持的编码应返回错误")
+	}
+}
+
+// TestWsClient_Resync 测试面板要求重新同步后下一帧为完整帧
+func TestWsClient_Resync(t *testing.T) {
+	cfg := &config.AgentConfig{ServerAddr: "ws://127.0.0.1:8900/ws-report"}
+	c := NewWsClient(cfg, http.ProxyFromEnvironment, zap.NewNop().Sugar(), nil, nil, nil)
+	var received []string
+	c.OnCommand = func(cmd *model.AgentCommand) { received = append(received, cmd.Type) }
+
+	encodeSent(c.encoder, newFrameTestInfo("debian", 10))
+	if frame := c.encoder.Encode(newFrameTestInfo("debian", 10)); frame.Type != model.FrameTypeMetrics {
+		t.Fatalf("硬件信息未变化时应为指标帧: %+v", frame)
+	}
+
+	c.dispatchCommand([]byte(`{"type":"resync"}`))
+	if frame := c.encoder.Encode(newFrameTestInfo("debian", 10)); frame.Type != model.FrameTypeInventory {
+		t.Errorf("重新同步后应为完整帧: %+v", frame)
+	}
+	if len(received) != 0 {
+		t.Errorf("重新同步指令不应交给 OnCommand: %v", received)
+	}
+}
+
+// TestWsClient_NegotiateCodec 测试根据握手响应确定编码
+func TestWsClient_NegotiateCodec(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested string
+		confirmed string
+		want      string
+	}{
+		{"面板确认", model.EncodingMsgpack, model.EncodingMsgpack, model.EncodingMsgpack},
+		{"旧版本面板不确认", model.EncodingCBOR, "", model.EncodingJSON},
+		{"未请求", "", "", model.EncodingJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.AgentConfig{ServerAddr: "ws://127.0.0.1:8900/ws-report", Encoding: tt.requested}
+			c := NewWsClient(cfg, http.ProxyFromEnvironment, zap.NewNop().Sugar(), nil, nil, nil)
+			resp := &http.Response{Header: http.Header{}}
+			if tt.confirmed != "" {
+				resp.Header.Set(model.EncodingHeader, tt.confirmed)
+			}
+			if got := c.negotiateCodec(resp).Name(); got != tt.want {
+				t.Errorf("negotiateCodec() = %s; want %s", got, tt.want)
+			}
+			if !c.dialer.EnableCompression {
+				t.Error("默认应启用压缩")
+			}
+		})
+	}
+}
+
+// deflateSize 返回 permessage-deflate 压缩后的近似大小
+func deflateSize(data []byte) int {
+	var buf bytes.Buffer
+	w, _ := flate.NewWriter(&buf, flate.BestSpeed)
+	_, _ = w.Write(data)
+	_ = w.Close()
+	return buf.Len()
+}
+
+// benchmarkCodec 比较各编码的编码耗时与传输大小
+func benchmarkCodec(b *testing.B, name string, metricsOnly bool) {
+	codec, _ := model.NewCodec(name)
+	info := newFrameTestInfo("debian", 12.5)
+	for i := 0; i < 20; i++ {
+		info.DiskInfo.Partitions = append(info.DiskInfo.Partitions,
+			&model.Partition{MountPoint: fmt.Sprintf("/data/%02d", i), Fstype: "ext4", Total: 1 << 40, Used: 1 << 38, Free: 3 << 38, UsedPercent: 25})
+	}
+	encoder := NewFrameEncoder()
+	frame := encodeSent(encoder, info)
+	if metricsOnly {
+		frame = encoder.Encode(info)
+	}
+
+	var size int
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		data, err := codec.Marshal(frame)
+		if err != nil {
+			b.Fatal(err)
+		}
+		size = len(data)
+		if i == 0 {
+			b.ReportMetric(float64(deflateSize(data)), "deflate-bytes")
+		}
+	}
+	b.ReportMetric(float64(size), "bytes")
+}
+
+func BenchmarkEncodeInventory_JSON(b *testing.B)    { benchmarkCodec(b, model.EncodingJSON, false) }
+func BenchmarkEncodeInventory_Msgpack(b *testing.B) { benchmarkCodec(b, model.EncodingMsgpack, false) }
+func BenchmarkEncodeInventory_CBOR(b *testing.B)    { benchmarkCodec(b, model.EncodingCBOR, false) }
+func BenchmarkEncodeMetrics_JSON(b *testing.B)      { benchmarkCodec(b, model.EncodingJSON, true) }
+func BenchmarkEncodeMetrics_Msgpack(b *testing.B)   { benchmarkCodec(b, model.EncodingMsgpack, true) }
+func BenchmarkEncodeMetrics_CBOR(b *testing.B)      { benchmarkCodec(b, model.EncodingCBOR, true) }
@@ -1,9 +1,20 @@
 package internal
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/ruanun/simple-server-status/internal/agent/config"
 	"github.com/ruanun/simple-server-status/pkg/model"
 	"github.com/shirou/gopsutil/v4/cpu"
 	"github.com/shirou/gopsutil/v4/disk"
@@ -12,159 +23,883 @@ import (
 	"github.com/shirou/gopsutil/v4/mem"
 )
 
-// GetServerInfo 获取服务器信息
-// hostIp: 服务器IP地址（可选，传空字符串表示未设置）
-// hostLocation: 服务器地理位置（可选，传空字符串表示未设置）
-func GetServerInfo(hostIp, hostLocation string) *model.ServerInfo {
-	return &model.ServerInfo{
-		//Name:              "win",
-		HostInfo:          getHostInfo(),
-		CpuInfo:           getCpuInfo(),
-		VirtualMemoryInfo: getMemInfo(),
-		SwapMemoryInfo:    getSwapMemInfo(),
-		DiskInfo:          getDiskInfo(),
-		NetworkInfo:       getNetInfo(),
+// 内置采集器名称
+const (
+	CollectorHost         = "host"
+	CollectorLoad         = "load"
+	CollectorCPU          = "cpu"
+	CollectorCPUInfo      = "cpu_info"
+	CollectorMemory       = "memory"
+	CollectorSwap         = "swap"
+	CollectorDisk         = "disk"
+	CollectorDiskIO       = "diskio"
+	CollectorNetwork      = "network"
+	CollectorSockets      = "sockets"
+	CollectorProcesses    = "processes"
+	CollectorProcessWatch = "process_watch"
+	CollectorChecks       = "checks"
+	CollectorProbes       = "probes"
+	CollectorSensors      = "sensors"
+)
+
+// BuiltinCollectorNames 内置采集器名称列表（用于配置校验）
+var BuiltinCollectorNames = []string{
+	CollectorHost, CollectorLoad, CollectorCPU, CollectorCPUInfo,
+	CollectorMemory, CollectorSwap, CollectorDisk, CollectorDiskIO, CollectorNetwork,
+	CollectorSockets, CollectorProcesses, CollectorProcessWatch, CollectorChecks,
+	CollectorProbes, CollectorSensors,
+}
+
+// FilterableCollectorNames 支持 include/exclude 过滤的采集器
+var FilterableCollectorNames = []string{CollectorDiskIO, CollectorNetwork}
+
+// InventoryCollectorNames 采集几乎不变的硬件与系统信息的采集器，连接建立时会立即刷新
+var InventoryCollectorNames = []string{CollectorHost, CollectorCPUInfo}
+
+// 内置采集器默认周期；CPU、负载、内存、磁盘 I/O、网络、套接字、受监控进程每次上报都采集，可用性检查与网络探测按各自的周期执行
+const (
+	DefaultDiskInterval      = 30 * time.Second
+	DefaultInventoryInterval = 10 * time.Minute
+	DefaultProcessInterval   = 15 * time.Second
+	DefaultSensorInterval    = 10 * time.Second
+)
+
+// baseCollector 采集器公共字段
+type baseCollector struct {
+	name     string
+	interval time.Duration
+}
+
+func (b *baseCollector) Name() string            { return b.name }
+func (b *baseCollector) Interval() time.Duration { return b.interval }
+func (b *baseCollector) Enabled() bool           { return true }
+
+// RegisterBuiltinCollectors 注册基于 gopsutil 的内置采集器
+// probes 执行面板下发的网络探测任务，由上报客户端收到指令后更新
+func RegisterBuiltinCollectors(registry *CollectorRegistry, netStats *NetworkStatsCollector, probes *ProbeCollector) error {
+	collectors := []Collector{
+		NewHostCollector(),
+		NewLoadCollector(),
+		NewCPUCollector(),
+		NewCPUInfoCollector(),
+		NewMemoryCollector(),
+		NewSwapCollector(),
+		NewDiskCollector(),
+		NewDiskIOCollector(),
+		NewNetworkCollector(netStats),
+		NewSocketCollector(),
+		NewProcessCollector(),
+		NewProcessWatchCollector(),
+		NewCheckCollector(),
+		probes,
+		NewSensorCollector(),
+	}
+	for _, c := range collectors {
+		if err := registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HostCollector 主机信息采集器（内核、平台、启动时间等）
+type HostCollector struct {
+	baseCollector
+	infoFn func(ctx context.Context) (*host.InfoStat, error)
+}
 
-		Ip:  hostIp,
-		Loc: hostLocation,
+// NewHostCollector 创建主机信息采集器
+func NewHostCollector() *HostCollector {
+	return &HostCollector{
+		baseCollector: baseCollector{name: CollectorHost, interval: DefaultInventoryInterval},
+		infoFn:        host.InfoWithContext,
 	}
 }
 
-// @brief：耗时统计函数
-func timeCost() func() {
-	//start := time.Now()
-	return func() {
-		//tc := time.Since(start)
-		//fmt.Printf("time cost = %v\n", tc)
+// Collect 采集主机信息
+func (c *HostCollector) Collect(ctx context.Context) (PartialInfo, error) {
+	info, err := c.infoFn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取主机信息失败: %w", err)
+	}
+	if info == nil {
+		return nil, errEmptyResult("主机信息")
 	}
+
+	hostInfo := model.HostInfo{
+		KernelArch:           info.KernelArch,
+		KernelVersion:        info.KernelVersion,
+		VirtualizationSystem: info.VirtualizationSystem,
+		Uptime:               info.Uptime,
+		BootTime:             info.BootTime,
+		OS:                   info.OS,
+		Platform:             info.Platform,
+		PlatformVersion:      info.PlatformVersion,
+		PlatformFamily:       info.PlatformFamily,
+	}
+	return func(s *model.ServerInfo) {
+		h := hostInfo
+		// 负载由 LoadCollector 单独采集，保留已写入的值
+		h.AvgStat = s.HostInfo.AvgStat
+		// 主机信息按较长周期采集，运行时间根据启动时间推算
+		if h.BootTime > 0 {
+			if now := uint64(time.Now().Unix()); now > h.BootTime {
+				h.Uptime = now - h.BootTime
+			}
+		}
+		*s.HostInfo = h
+	}, nil
 }
 
-func getHostInfo() *model.HostInfo {
-	defer timeCost()()
+// LoadCollector 系统负载采集器
+type LoadCollector struct {
+	baseCollector
+	loadFn func(ctx context.Context) (*load.AvgStat, error)
+}
 
-	info, err := host.Info()
+// NewLoadCollector 创建系统负载采集器
+func NewLoadCollector() *LoadCollector {
+	return &LoadCollector{
+		baseCollector: baseCollector{name: CollectorLoad},
+		loadFn:        load.AvgWithContext,
+	}
+}
+
+// Collect 采集系统负载
+func (c *LoadCollector) Collect(ctx context.Context) (PartialInfo, error) {
+	loadInfo, err := c.loadFn(ctx)
 	if err != nil {
-		fmt.Println("get host info fail, error: ", err)
-	}
-	var hostInfo model.HostInfo
-	hostInfo.KernelArch = info.KernelArch
-	hostInfo.KernelVersion = info.KernelVersion
-	hostInfo.VirtualizationSystem = info.VirtualizationSystem
-	hostInfo.Uptime = info.Uptime
-	hostInfo.BootTime = info.BootTime
-	hostInfo.OS = info.OS
-	hostInfo.Platform = info.Platform
-	hostInfo.PlatformVersion = info.PlatformVersion
-	hostInfo.PlatformFamily = info.PlatformFamily
-
-	loadInfo, err := load.Avg()
+		return nil, fmt.Errorf("获取系统负载失败: %w", err)
+	}
+	if loadInfo == nil {
+		return nil, errEmptyResult("系统负载")
+	}
+	return func(s *model.ServerInfo) { s.HostInfo.AvgStat = loadInfo }, nil
+}
+
+// CPUCollector CPU 占用采集器
+// 根据两次采集之间 cpu.Times 的差值计算总占用、各核心占用和各状态（iowait、steal 等）的时间占比
+type CPUCollector struct {
+	baseCollector
+	timesFn func(ctx context.Context, perCPU bool) ([]cpu.TimesStat, error)
+	mhzFn   func(ctx context.Context) (float64, error)
+
+	mu          sync.Mutex
+	lastTotal   *cpu.TimesStat
+	lastPerCore []cpu.TimesStat
+}
+
+// NewCPUCollector 创建 CPU 占用采集器
+func NewCPUCollector() *CPUCollector {
+	return &CPUCollector{
+		baseCollector: baseCollector{name: CollectorCPU},
+		timesFn:       cpu.TimesWithContext,
+		mhzFn:         currentCPUMhz,
+	}
+}
+
+// Collect 采集 CPU 占用；首次采集时与启动时刻比较，得到启动以来的平均值
+func (c *CPUCollector) Collect(ctx context.Context) (PartialInfo, error) {
+	total, err := c.timesFn(ctx, false)
 	if err != nil {
-		fmt.Println("get average load fail. err: ", err)
+		return nil, fmt.Errorf("获取 CPU 时间失败: %w", err)
+	}
+	if len(total) == 0 {
+		return nil, errEmptyResult("CPU 时间")
 	}
-	hostInfo.AvgStat = loadInfo
-	return &hostInfo
+	// 单个核心的数据只是补充信息，获取失败时不影响总占用
+	perCore, _ := c.timesFn(ctx, true)
+	mhz, _ := c.mhzFn(ctx)
+
+	c.mu.Lock()
+	times := cpuTimesDelta(c.lastTotal, &total[0])
+	var perCorePercent []float64
+	if len(perCore) > 0 {
+		perCorePercent = make([]float64, len(perCore))
+		for i := range perCore {
+			var last *cpu.TimesStat
+			if i < len(c.lastPerCore) && c.lastPerCore[i].CPU == perCore[i].CPU {
+				last = &c.lastPerCore[i]
+			}
+			perCorePercent[i] = cpuBusy(cpuTimesDelta(last, &perCore[i]))
+		}
+	}
+	c.lastTotal = &total[0]
+	c.lastPerCore = perCore
+	c.mu.Unlock()
+
+	percent := cpuBusy(times)
+	return func(s *model.ServerInfo) {
+		s.CpuInfo.Percent = percent
+		s.CpuInfo.PerCore = perCorePercent
+		s.CpuInfo.Times = &times
+		s.CpuInfo.Mhz = mhz
+	}, nil
 }
 
-func getCpuInfo() *model.CpuInfo {
-	defer timeCost()()
+// cpuBusy 总占用：除 idle 和 iowait 以外的时间占比，与 cpu.Percent 的算法一致
+func cpuBusy(t model.CpuTimes) float64 {
+	return math.Max(0, math.Min(100, 100-t.Idle-t.Iowait))
+}
 
-	var cpuInfo model.CpuInfo
+// cpuTimesDelta 计算 last 到 cur 之间各状态的时间占比；last 为空时使用 cur 的累计值
+// guest 时间已计入 user，不单独相加
+func cpuTimesDelta(last, cur *cpu.TimesStat) model.CpuTimes {
+	d := *cur
+	if last != nil {
+		d.User -= last.User
+		d.System -= last.System
+		d.Nice -= last.Nice
+		d.Idle -= last.Idle
+		d.Iowait -= last.Iowait
+		d.Irq -= last.Irq
+		d.Softirq -= last.Softirq
+		d.Steal -= last.Steal
+	}
+	total := d.User + d.System + d.Nice + d.Idle + d.Iowait + d.Irq + d.Softirq + d.Steal
+	if total <= 0 {
+		// 两次采集之间没有时间变化（采集过于频繁或计数器重置），视为空闲
+		return model.CpuTimes{Idle: 100}
+	}
+	pct := func(v float64) float64 { return math.Max(0, v) / total * 100 }
+	return model.CpuTimes{
+		User:    pct(d.User),
+		System:  pct(d.System),
+		Nice:    pct(d.Nice),
+		Idle:    pct(d.Idle),
+		Iowait:  pct(d.Iowait),
+		Irq:     pct(d.Irq),
+		Softirq: pct(d.Softirq),
+		Steal:   pct(d.Steal),
+	}
+}
 
-	ci, err := cpu.Info()
+// currentCPUMhz 读取各核心当前频率的平均值；/proc/cpuinfo 不存在（非 Linux）时返回 0
+func currentCPUMhz(context.Context) (float64, error) {
+	data, err := os.ReadFile("/proc/cpuinfo")
 	if err != nil {
-		println("cpu.Info error:", err)
-	} else {
-		cpuModelCount := make(map[string]int)
-		for i := 0; i < len(ci); i++ {
-			cpuModelCount[ci[i].ModelName]++
+		return 0, err
+	}
+	return parseCPUMhz(string(data)), nil
+}
+
+// parseCPUMhz 解析 /proc/cpuinfo 中的 "cpu MHz" 行并取平均值
+func parseCPUMhz(cpuinfo string) float64 {
+	var sum float64
+	var n int
+	for _, line := range strings.Split(cpuinfo, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) != "cpu MHz" {
+			continue
 		}
-		for m, count := range cpuModelCount {
-			cpuInfo.Info = append(cpuInfo.Info, fmt.Sprintf("%s x %d ", m, count))
+		if mhz, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+			sum += mhz
+			n++
 		}
 	}
-	cpuPercent, _ := cpu.Percent(0, false)
-	cpuInfo.Percent = cpuPercent[0]
-	return &cpuInfo
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
 }
 
-func getMemInfo() *model.VirtualMemoryInfo {
-	defer timeCost()()
+// CPUInfoCollector CPU 型号采集器
+type CPUInfoCollector struct {
+	baseCollector
+	infoFn   func(ctx context.Context) ([]cpu.InfoStat, error)
+	countsFn func(ctx context.Context, logical bool) (int, error)
+}
 
-	memInfo, err := mem.VirtualMemory()
+// NewCPUInfoCollector 创建 CPU 型号采集器
+func NewCPUInfoCollector() *CPUInfoCollector {
+	return &CPUInfoCollector{
+		baseCollector: baseCollector{name: CollectorCPUInfo, interval: DefaultInventoryInterval},
+		infoFn:        cpu.InfoWithContext,
+		countsFn:      cpu.CountsWithContext,
+	}
+}
+
+// Collect 采集 CPU 型号和核心数，相同型号合并为 "型号 x 数量"
+func (c *CPUInfoCollector) Collect(ctx context.Context) (PartialInfo, error) {
+	ci, err := c.infoFn(ctx)
 	if err != nil {
-		fmt.Println("get memory info fail. err： ", err)
+		return nil, fmt.Errorf("获取 CPU 型号失败: %w", err)
 	}
 
-	var memRet model.VirtualMemoryInfo
-	memRet.Total = memInfo.Total
-	//memRet.Available = memInfo.Available
-	//memRet.Free = memInfo.Free
-	memRet.Used = memInfo.Used
-	memRet.UsedPercent = memInfo.UsedPercent
-	return &memRet
+	cpuModelCount := make(map[string]int)
+	var models []string
+	for _, stat := range ci {
+		if cpuModelCount[stat.ModelName] == 0 {
+			models = append(models, stat.ModelName)
+		}
+		cpuModelCount[stat.ModelName]++
+	}
+	var info []string
+	for _, m := range models {
+		info = append(info, fmt.Sprintf("%s x %d ", m, cpuModelCount[m]))
+	}
+	// 核心数获取失败（如容器内无权限）时为 0，不影响型号信息
+	logical, _ := c.countsFn(ctx, true)
+	physical, _ := c.countsFn(ctx, false)
+	return func(s *model.ServerInfo) {
+		s.CpuInfo.Info = info
+		s.CpuInfo.LogicalCores = logical
+		s.CpuInfo.PhysicalCores = physical
+	}, nil
+}
+
+// MemoryCollector 内存采集器
+type MemoryCollector struct {
+	baseCollector
+	memFn func(ctx context.Context) (*mem.VirtualMemoryStat, error)
 }
 
-func getSwapMemInfo() *model.SwapMemoryInfo {
-	defer timeCost()()
+// NewMemoryCollector 创建内存采集器
+func NewMemoryCollector() *MemoryCollector {
+	return &MemoryCollector{
+		baseCollector: baseCollector{name: CollectorMemory},
+		memFn:         mem.VirtualMemoryWithContext,
+	}
+}
 
-	ms, err := mem.SwapMemory()
+// Collect 采集内存信息
+func (c *MemoryCollector) Collect(ctx context.Context) (PartialInfo, error) {
+	memInfo, err := c.memFn(ctx)
 	if err != nil {
-		println("mem.SwapMemory error:", err)
+		return nil, fmt.Errorf("获取内存信息失败: %w", err)
+	}
+	if memInfo == nil {
+		return nil, errEmptyResult("内存信息")
 	}
 
-	var swapInfo model.SwapMemoryInfo
-	swapInfo.Total = ms.Total
-	swapInfo.Free = ms.Free
-	swapInfo.Used = ms.Used
-	swapInfo.UsedPercent = ms.UsedPercent
-	return &swapInfo
+	memRet := model.VirtualMemoryInfo{
+		Total:       memInfo.Total,
+		Used:        memInfo.Used,
+		UsedPercent: memInfo.UsedPercent,
+
+		Available:   memInfo.Available,
+		Free:        memInfo.Free,
+		Buffers:     memInfo.Buffers,
+		Cached:      memInfo.Cached,
+		Shared:      memInfo.Shared,
+		Slab:        memInfo.Slab,
+		Dirty:       memInfo.Dirty,
+		CommittedAS: memInfo.CommittedAS,
+
+		HugePagesTotal: memInfo.HugePagesTotal,
+		HugePagesFree:  memInfo.HugePagesFree,
+		HugePageSize:   memInfo.HugePageSize,
+	}
+	return func(s *model.ServerInfo) { s.VirtualMemoryInfo = &memRet }, nil
 }
 
-func getDiskInfo() *model.DiskInfo {
-	defer timeCost()()
+// SwapCollector Swap 采集器
+type SwapCollector struct {
+	baseCollector
+	swapFn func(ctx context.Context) (*mem.SwapMemoryStat, error)
+}
+
+// NewSwapCollector 创建 Swap 采集器
+func NewSwapCollector() *SwapCollector {
+	return &SwapCollector{
+		baseCollector: baseCollector{name: CollectorSwap},
+		swapFn:        mem.SwapMemoryWithContext,
+	}
+}
 
-	diskPart, err := disk.Partitions(false)
+// Collect 采集 Swap 信息
+func (c *SwapCollector) Collect(ctx context.Context) (PartialInfo, error) {
+	ms, err := c.swapFn(ctx)
 	if err != nil {
-		fmt.Println(err)
+		return nil, fmt.Errorf("获取 Swap 信息失败: %w", err)
 	}
-	var diskInfo model.DiskInfo
-	var total, used uint64
+	if ms == nil {
+		return nil, errEmptyResult("Swap 信息")
+	}
+
+	swapInfo := model.SwapMemoryInfo{
+		Total:       ms.Total,
+		Free:        ms.Free,
+		Used:        ms.Used,
+		UsedPercent: ms.UsedPercent,
+	}
+	return func(s *model.ServerInfo) { s.SwapMemoryInfo = &swapInfo }, nil
+}
+
+// 磁盘挂载点超时与隔离参数
+const (
+	DefaultMountTimeout   = 2 * time.Second  // 单个挂载点查询超时
+	mountQuarantineAfter  = 3                // 连续超时多少次后隔离
+	mountQuarantinePeriod = 10 * time.Minute // 隔离时长
+)
+
+// mountState 挂载点查询状态
+type mountState struct {
+	timeouts         int              // 连续超时次数
+	running          bool             // 上一次查询是否仍未返回
+	quarantinedUntil time.Time        // 隔离截止时间
+	last             *model.Partition // 最近一次成功查询的结果，查询失败或隔离期内沿用
+}
+
+// 磁盘总容量统计方式
+const (
+	DiskTotalDevice = "device" // 按设备去重，绑定挂载与 btrfs 子卷只计一次
+	DiskTotalMount  = "mount"  // 各挂载点直接相加
+)
+
+// defaultDiskMountExclude 未配置排除规则时排除的挂载点
+// 不统计 K8s 的虚拟挂载点：https://github.com/shirou/gopsutil/issues/1007
+var defaultDiskMountExclude = []string{"re:/var/lib/kubelet"}
+
+// diskOptions 分区筛选规则
+type diskOptions struct {
+	customFsTypes bool        // 是否配置了 fsTypes，未配置时使用 expectDiskFsTypes
+	fsTypes       *NameFilter // 文件系统类型规则，nil 表示不额外过滤
+	mountpoints   *NameFilter
+	only          []string // 只统计这些挂载点
+	totalMode     string
+	readOnly      *NameFilter // 预期只读的挂载点，nil 表示没有
+	writeProbe    bool
+}
+
+// newDiskOptions 根据配置生成分区筛选规则，规则无效时返回错误
+func newDiskOptions(cfg config.DiskConfig) (diskOptions, error) {
+	opts := diskOptions{
+		customFsTypes: len(cfg.FsTypes) > 0,
+		only:          cfg.OnlyMountpoints,
+		totalMode:     strings.ToLower(cfg.TotalMode),
+	}
+	switch opts.totalMode {
+	case "":
+		opts.totalMode = DiskTotalDevice
+	case DiskTotalDevice, DiskTotalMount:
+	default:
+		return opts, fmt.Errorf("无效的总容量统计方式 %q，可选 %s、%s", cfg.TotalMode, DiskTotalDevice, DiskTotalMount)
+	}
+
+	var err error
+	if len(cfg.FsTypes) > 0 || len(cfg.ExcludeFsTypes) > 0 {
+		if opts.fsTypes, err = NewNameFilter(cfg.FsTypes, cfg.ExcludeFsTypes); err != nil {
+			return opts, fmt.Errorf("文件系统类型: %w", err)
+		}
+	}
+	exclude := cfg.ExcludeMountpoints
+	if len(exclude) == 0 {
+		exclude = defaultDiskMountExclude
+	}
+	if opts.mountpoints, err = NewNameFilter(cfg.Mountpoints, exclude); err != nil {
+		return opts, fmt.Errorf("挂载点: %w", err)
+	}
+	if len(cfg.ReadOnlyMountpoints) > 0 {
+		if opts.readOnly, err = NewNameFilter(cfg.ReadOnlyMountpoints, nil); err != nil {
+			return opts, fmt.Errorf("只读挂载点: %w", err)
+		}
+	}
+	opts.writeProbe = cfg.WriteProbe
+	return opts, nil
+}
+
+// unexpectedReadOnly 判断分区是否以只读方式挂载且不在预期只读的挂载点中
+func (o diskOptions) unexpectedReadOnly(dp disk.PartitionStat) bool {
+	if !slices.Contains(dp.Opts, "ro") {
+		return false
+	}
+	return !o.expectedReadOnly(dp.Mountpoint)
+}
+
+// expectedReadOnly 判断挂载点是否预期以只读方式挂载
+func (o diskOptions) expectedReadOnly(mountpoint string) bool {
+	return o.readOnly != nil && o.readOnly.Match(mountpoint)
+}
 
+// match 判断分区是否需要统计
+func (o diskOptions) match(dp disk.PartitionStat) bool {
+	if len(o.only) > 0 {
+		return slices.Contains(o.only, dp.Mountpoint)
+	}
+	fsType := strings.ToLower(dp.Fstype)
+	if !o.customFsTypes && !isListContainsStr(expectDiskFsTypes, fsType) {
+		return false
+	}
+	return o.fsTypes.Match(fsType) && o.mountpoints.Match(dp.Mountpoint)
+}
+
+// DiskCollector 磁盘分区采集器
+// 各挂载点并发查询并单独超时；失效的 NFS/FUSE 挂载点连续超时后会被隔离一段时间，不再拖慢采集
+type DiskCollector struct {
+	baseCollector
+	partitionsFn func(ctx context.Context) ([]disk.PartitionStat, error)
+	usageFn      func(ctx context.Context, path string) (*disk.UsageStat, error)
+	mountTimeout time.Duration
+	now          func() time.Time
+
+	deviceIDsFn func() map[string]string // 挂载点到设备号的映射，用于按设备去重
+	probeFn     func(mountpoint string) error
+
+	mu     sync.Mutex
+	mounts map[string]*mountState
+	opts   diskOptions
+}
+
+// NewDiskCollector 创建磁盘采集器
+func NewDiskCollector() *DiskCollector {
+	opts, _ := newDiskOptions(config.DiskConfig{})
+	return &DiskCollector{
+		baseCollector: baseCollector{name: CollectorDisk, interval: DefaultDiskInterval},
+		partitionsFn: func(ctx context.Context) ([]disk.PartitionStat, error) {
+			return disk.PartitionsWithContext(ctx, false)
+		},
+		usageFn:      disk.UsageWithContext,
+		mountTimeout: DefaultMountTimeout,
+		now:          time.Now,
+		deviceIDsFn:  readMountDeviceIDs,
+		probeFn:      probeWrite,
+		mounts:       make(map[string]*mountState),
+		opts:         opts,
+	}
+}
+
+// ApplyConfig 应用 AgentConfig.Disk 中的分区筛选规则
+func (c *DiskCollector) ApplyConfig(cfg *config.AgentConfig) error {
+	opts, err := newDiskOptions(cfg.Disk)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.opts = opts
+	c.mu.Unlock()
+	return nil
+}
+
+// Collect 采集磁盘信息；单个分区失败、超时或处于隔离期时沿用其最近一次成功的结果并标记为过期
+func (c *DiskCollector) Collect(ctx context.Context) (PartialInfo, error) {
+	diskPart, err := c.partitionsFn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取磁盘分区失败: %w", err)
+	}
+
+	c.mu.Lock()
+	opts := c.opts
+	c.mu.Unlock()
+
+	var targets []disk.PartitionStat
 	for _, dp := range diskPart {
-		diskUsed, _ := disk.Usage(dp.Mountpoint)
-		//fmt.Printf("%s %d %f %d \n", usage.Path, usage.Total, usage.UsedPercent, usage.Used)
-		fsType := strings.ToLower(dp.Fstype)
-		// 不统计 K8s 的虚拟挂载点：https://github.com/shirou/gopsutil/issues/1007
-		if isListContainsStr(expectDiskFsTypes, fsType) && !strings.Contains(dp.Mountpoint, "/var/lib/kubelet") {
-			p := model.Partition{
-				MountPoint: dp.Mountpoint, Fstype: dp.Fstype,
-				Total: diskUsed.Total, Free: diskUsed.Free,
-				Used: diskUsed.Used, UsedPercent: diskUsed.UsedPercent,
+		if opts.match(dp) {
+			targets = append(targets, dp)
+		}
+	}
+
+	usages := make([]*mountUsage, len(targets))
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, dp := range targets {
+		// 隔离期内不查询，沿用最近一次成功的结果
+		if c.isQuarantined(dp.Mountpoint) {
+			continue
+		}
+		// 已知只读的分区无需探测
+		probe := opts.writeProbe && !slices.Contains(dp.Opts, "ro")
+		wg.Add(1)
+		go func(i int, mountpoint string) {
+			defer wg.Done()
+			usages[i], errs[i] = c.usage(ctx, mountpoint, probe)
+		}(i, dp.Mountpoint)
+	}
+	wg.Wait()
+
+	var diskInfo model.DiskInfo
+	var listed []disk.PartitionStat
+	fresh := 0
+	for i, dp := range targets {
+		if usages[i] == nil {
+			// 查询失败、超时或处于隔离期：沿用该挂载点最近一次成功的结果并标记为过期
+			if last := c.lastPartition(dp.Mountpoint); last != nil {
+				last.Stale = true
+				diskInfo.Partitions = append(diskInfo.Partitions, last)
+				listed = append(listed, dp)
 			}
-			diskInfo.Partitions = append(diskInfo.Partitions, &p)
-			total += diskUsed.Total
-			used += diskUsed.Used
+			continue
 		}
+		fresh++
+		diskUsed := usages[i].stat
+		part := &model.Partition{
+			MountPoint: dp.Mountpoint, Fstype: dp.Fstype,
+			Total: diskUsed.Total, Free: diskUsed.Free,
+			Used: diskUsed.Used, UsedPercent: diskUsed.UsedPercent,
+		}
+		part.InodesTotal, part.InodesUsed, part.InodesUsedPercent = diskUsed.InodesTotal, diskUsed.InodesUsed, diskUsed.InodesUsedPercent
+		part.ReadOnly = opts.unexpectedReadOnly(dp)
+		if err := usages[i].writeErr; err != nil {
+			if errors.Is(err, syscall.EROFS) {
+				// 挂载选项尚未更新但文件系统已拒绝写入
+				part.ReadOnly = !opts.expectedReadOnly(dp.Mountpoint)
+			} else {
+				part.WriteError = probeErrorMessage(err)
+			}
+		}
+		c.setLastPartition(dp.Mountpoint, part)
+		diskInfo.Partitions = append(diskInfo.Partitions, part)
+		listed = append(listed, dp)
+	}
+
+	// 所有分区都未能查询时由注册表沿用上一次的结果
+	if fresh == 0 && len(targets) > 0 {
+		err := errors.Join(errs...)
+		if err == nil {
+			err = fmt.Errorf("所有分区均处于隔离期")
+		}
+		return nil, err
+	}
+
+	// 总容量按设备去重时，绑定挂载与 btrfs 子卷仍然列在分区中，只是不重复计入总量
+	counted := listed
+	if opts.totalMode == DiskTotalDevice {
+		counted = dedupeByDevice(listed, c.deviceIDsFn())
+	}
+	countedMounts := make(map[string]bool, len(counted))
+	for _, dp := range counted {
+		countedMounts[dp.Mountpoint] = true
+	}
+	stale := false
+	for _, part := range diskInfo.Partitions {
+		stale = stale || part.Stale
+		if countedMounts[part.MountPoint] {
+			diskInfo.Total += part.Total
+			diskInfo.Used += part.Used
+		}
+	}
+
+	// 计算占用百分比
+	if diskInfo.Total > 0 {
+		diskInfo.UsedPercent = float64(diskInfo.Used) / float64(diskInfo.Total) * 100
 	}
-	diskInfo.Used = used
-	diskInfo.Total = total
-	//计算占用百分比
-	diskInfo.UsedPercent = float64(used) / float64(total) * 100
-	return &diskInfo
+	return func(s *model.ServerInfo) {
+		s.DiskInfo = &diskInfo
+		if stale && !slices.Contains(s.Stale, CollectorDisk) {
+			s.Stale = append(s.Stale, CollectorDisk)
+		}
+	}, errors.Join(errs...)
 }
 
-// StatNetworkSpeed 更新网络统计（线程安全）
-func StatNetworkSpeed() {
-	defer timeCost()()
+// dedupeByDevice 同一设备的多个挂载点只保留路径最短的一个，如 / 与绑定挂载的 /var/lib/docker/...
+func dedupeByDevice(parts []disk.PartitionStat, deviceIDs map[string]string) []disk.PartitionStat {
+	index := make(map[string]int, len(parts))
+	result := make([]disk.PartitionStat, 0, len(parts))
+	for _, dp := range parts {
+		key := deviceKey(dp, deviceIDs)
+		if i, ok := index[key]; ok {
+			if len(dp.Mountpoint) < len(result[i].Mountpoint) {
+				result[i] = dp
+			}
+			continue
+		}
+		index[key] = len(result)
+		result = append(result, dp)
+	}
+	return result
+}
 
-	if err := globalNetworkStats.Update(); err != nil {
-		fmt.Println("更新网络统计失败: ", err)
+// deviceKey 返回分区所在设备的标识
+// 优先使用 mountinfo 中的设备号；没有时使用块设备路径，tmpfs 等没有设备路径的分区不去重
+func deviceKey(dp disk.PartitionStat, deviceIDs map[string]string) string {
+	if id, ok := deviceIDs[dp.Mountpoint]; ok {
+		return id
+	}
+	if strings.HasPrefix(dp.Device, "/") {
+		return dp.Device
 	}
+	return "mount:" + dp.Mountpoint
 }
 
-// 网络信息  参考 nezha（线程安全）
-func getNetInfo() *model.NetworkInfo {
-	return globalNetworkStats.GetStats()
+// mountUsage 单个挂载点的查询结果
+type mountUsage struct {
+	stat     *disk.UsageStat
+	writeErr error // 写入探测的错误，未探测或探测成功时为 nil
+}
+
+// usage 在超时时间内查询单个挂载点，probe 为 true 时同时进行写入探测
+// statfs 等系统调用不响应 ctx，超时后不等待其返回；上一次查询未返回前不会再次发起
+func (c *DiskCollector) usage(ctx context.Context, mountpoint string, probe bool) (*mountUsage, error) {
+	c.mu.Lock()
+	state := c.stateOf(mountpoint)
+	if state.running {
+		c.mu.Unlock()
+		return nil, c.onTimeout(mountpoint, fmt.Errorf("分区 %s 上一次查询仍未返回", mountpoint))
+	}
+	state.running = true
+	c.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, c.mountTimeout)
+	defer cancel()
+
+	type usageResult struct {
+		usage *mountUsage
+		err   error
+	}
+	done := make(chan usageResult, 1)
+	go func() {
+		stat, err := c.usageFn(ctx, mountpoint)
+		// 失效的挂载点上写入同样会阻塞，探测与查询共用超时和隔离
+		var writeErr error
+		if err == nil && stat != nil && probe {
+			writeErr = c.probeFn(mountpoint)
+		}
+		c.mu.Lock()
+		state.running = false
+		c.mu.Unlock()
+		done <- usageResult{&mountUsage{stat: stat, writeErr: writeErr}, err}
+	}()
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			return nil, fmt.Errorf("获取分区 %s 使用情况失败: %w", mountpoint, res.err)
+		}
+		if res.usage.stat == nil {
+			return nil, errEmptyResult("分区 " + mountpoint + " 使用情况")
+		}
+		c.mu.Lock()
+		state.timeouts = 0
+		c.mu.Unlock()
+		return res.usage, nil
+	case <-ctx.Done():
+		return nil, c.onTimeout(mountpoint, fmt.Errorf("获取分区 %s 使用情况超时 (%v)", mountpoint, c.mountTimeout))
+	}
+}
+
+// probeWriteName 写入探测的临时文件名前缀
+const probeWriteName = ".sss-write-probe-*"
+
+// probeWrite 在挂载点创建、写入并删除一个临时文件，验证文件系统可写
+// Agent 没有写权限时返回 nil，只报告文件系统本身的问题（只读、空间不足、I/O 错误等）
+func probeWrite(mountpoint string) error {
+	f, err := os.CreateTemp(mountpoint, probeWriteName)
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return nil
+		}
+		return err
+	}
+	_, err = f.WriteString("ok")
+	if syncErr := f.Sync(); err == nil {
+		err = syncErr
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	_ = os.Remove(f.Name())
+	return err
+}
+
+// probeErrorMessage 返回探测错误的系统错误描述，不含临时文件路径
+func probeErrorMessage(err error) string {
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err.Error()
+	}
+	return err.Error()
+}
+
+// onTimeout 记录挂载点超时，连续超时达到阈值时隔离该挂载点
+func (c *DiskCollector) onTimeout(mountpoint string, err error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := c.stateOf(mountpoint)
+	state.timeouts++
+	if state.timeouts >= mountQuarantineAfter {
+		state.quarantinedUntil = c.now().Add(mountQuarantinePeriod)
+		return fmt.Errorf("%w，连续超时 %d 次，隔离 %v", err, state.timeouts, mountQuarantinePeriod)
+	}
+	return err
+}
+
+// isQuarantined 判断挂载点是否处于隔离期
+func (c *DiskCollector) isQuarantined(mountpoint string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state, ok := c.mounts[mountpoint]
+	return ok && c.now().Before(state.quarantinedUntil)
+}
+
+// QuarantinedMounts 返回处于隔离期的挂载点（排序后）
+func (c *DiskCollector) QuarantinedMounts() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var mounts []string
+	now := c.now()
+	for mountpoint, state := range c.mounts {
+		if now.Before(state.quarantinedUntil) {
+			mounts = append(mounts, mountpoint)
+		}
+	}
+	sort.Strings(mounts)
+	return mounts
+}
+
+// lastPartition 返回挂载点最近一次成功查询结果的副本，没有时返回 nil
+func (c *DiskCollector) lastPartition(mountpoint string) *model.Partition {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state, ok := c.mounts[mountpoint]
+	if !ok || state.last == nil {
+		return nil
+	}
+	last := *state.last
+	return &last
+}
+
+// setLastPartition 记录挂载点最近一次成功查询的结果
+func (c *DiskCollector) setLastPartition(mountpoint string, part *model.Partition) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	last := *part
+	c.stateOf(mountpoint).last = &last
+}
+
+// stateOf 获取挂载点状态，调用方需持有 c.mu
+func (c *DiskCollector) stateOf(mountpoint string) *mountState {
+	state, ok := c.mounts[mountpoint]
+	if !ok {
+		state = &mountState{}
+		c.mounts[mountpoint] = state
+	}
+	return state
+}
+
+// NetworkCollector 网络速度与流量采集器
+// 速度由 NetworkStatsCollector 按秒更新，这里只读取最新结果
+type NetworkCollector struct {
+	baseCollector
+	stats *NetworkStatsCollector
+}
+
+// NewNetworkCollector 创建网络采集器
+func NewNetworkCollector(stats *NetworkStatsCollector) *NetworkCollector {
+	return &NetworkCollector{
+		baseCollector: baseCollector{name: CollectorNetwork},
+		stats:         stats,
+	}
+}
+
+// SetFilter 设置单独上报的网卡过滤规则
+func (c *NetworkCollector) SetFilter(filter *NameFilter) {
+	c.stats.SetFilter(filter)
+}
+
+// Collect 读取网络统计
+func (c *NetworkCollector) Collect(_ context.Context) (PartialInfo, error) {
+	netInfo := c.stats.GetStats()
+	return func(s *model.ServerInfo) { s.NetworkInfo = netInfo }, nil
+}
+
+// errEmptyResult 采集接口未返回错误但结果为空
+func errEmptyResult(what string) error {
+	return fmt.Errorf("获取%s失败: 返回结果为空", what)
 }
 
 // FormatFileSize 字节的单位转换 保留两位小数
@@ -194,9 +929,6 @@ var expectDiskFsTypes = []string{
 	"fuseblk", "zfs", "simfs", "ntfs", "fat32", "exfat", "xfs", "fuse.rclone",
 }
 
-// 全局网络统计收集器（线程安全）
-var globalNetworkStats = NewNetworkStatsCollector(excludeNetInterfaces)
-
 func isListContainsStr(list []string, str string) bool {
 	for i := 0; i < len(list); i++ {
 		if strings.Contains(str, list[i]) {
@@ -0,0 +1,328 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ruanun/simple-server-status/internal/agent/config"
+	"github.com/ruanun/simple-server-status/pkg/model"
+	"go.uber.org/zap"
+)
+
+const (
+	// TransportWebSocket WebSocket 长连接上报
+	TransportWebSocket = "ws"
+	// TransportHTTP HTTP POST 批量推送上报
+	TransportHTTP = "http"
+
+	// maxPushFlushInterval HTTP 推送的最长刷新间隔，须小于面板默认的离线判定时间（reportTimeIntervalMax 默认 30 秒）
+	maxPushFlushInterval = 20 * time.Second
+	// maxPendingReports HTTP 推送失败时最多缓存的上报条数
+	maxPendingReports = 100
+	// maxCommandSize 推送响应中读取的最大字节数
+	maxCommandSize = 1 << 20
+)
+
+// ReportClient 上报客户端接口
+// WebSocket 与 HTTP 推送两种传输方式均实现此接口，AgentService 不关心具体传输方式
+type ReportClient interface {
+	Start()
+	SendJsonMsg(obj interface{})
+	GetStats() map[string]int64
+	Close()
+}
+
+// ResolveTransport 根据配置和服务器地址确定上报方式及实际使用的地址
+// 显式配置 transport 时，会把 serverAddr 的协议映射到对应传输方式（ws<->http，wss<->https）
+func ResolveTransport(cfg *config.AgentConfig) (transport string, serverAddr string) {
+	addr := cfg.ServerAddr
+	lowerAddr := strings.ToLower(addr)
+	isHTTP := strings.HasPrefix(lowerAddr, "http://") || strings.HasPrefix(lowerAddr, "https://")
+
+	switch strings.ToLower(cfg.Transport) {
+	case TransportPull:
+		return TransportPull, addr
+	case TransportHTTP:
+		if !isHTTP {
+			addr = swapScheme(addr, map[string]string{"wss://": "https://", "ws://": "http://"})
+		}
+		return TransportHTTP, addr
+	case TransportWebSocket:
+		if isHTTP {
+			addr = swapScheme(addr, map[string]string{"https://": "wss://", "http://": "ws://"})
+		}
+		return TransportWebSocket, addr
+	default:
+		if isHTTP {
+			return TransportHTTP, addr
+		}
+		return TransportWebSocket, addr
+	}
+}
+
+// swapScheme 替换地址的协议前缀
+func swapScheme(addr string, mapping map[string]string) string {
+	lowerAddr := strings.ToLower(addr)
+	for from, to := range mapping {
+		if strings.HasPrefix(lowerAddr, from) {
+			return to + addr[len(from):]
+		}
+	}
+	return addr
+}
+
+// HTTPPushClient HTTP POST 推送客户端
+// 适用于会主动断开 WebSocket 长连接的网络环境：上报数据按批次通过普通 HTTP 请求发送
+type HTTPPushClient struct {
+	// 服务器地址
+	ServerAddr string
+	// 认证头（与 WebSocket 握手使用相同的头）
+	AuthHeader http.Header
+	// 推送响应中携带面板指令（如网络探测任务）时的回调，需在 Start 前设置
+	OnCommand func(cmd *model.AgentCommand)
+	// 批量配置
+	batchSize     int
+	flushInterval time.Duration
+	// HTTP 客户端（遵循代理配置）
+	client *http.Client
+	// 上下文管理
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	// 发送队列
+	sendChan chan []byte
+	mu       sync.RWMutex
+	closed   bool
+	// 推送统计
+	messagesSent int64
+	batchesSent  int64
+	bytesSent    int64
+	sendFailures int64
+	dropped      int64
+	// 依赖注入
+	logger       *zap.SugaredLogger
+	errorHandler *ErrorHandler
+	memoryPool   *MemoryPoolManager
+}
+
+// NewHTTPPushClient 创建 HTTP 推送客户端
+func NewHTTPPushClient(
+	cfg *config.AgentConfig,
+	serverAddr string,
+	httpClient *http.Client,
+	logger *zap.SugaredLogger,
+	errorHandler *ErrorHandler,
+	memoryPool *MemoryPoolManager,
+) *HTTPPushClient {
+	authHeader := make(http.Header)
+	authHeader.Add("X-AUTH-SECRET", cfg.AuthSecret)
+	authHeader.Add("X-SERVER-ID", cfg.ServerId)
+	authHeader.Set("Content-Type", "application/json")
+
+	batchSize := cfg.PushBatchSize
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	reportInterval := cfg.ReportTimeInterval
+	if reportInterval <= 0 {
+		reportInterval = 2
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &HTTPPushClient{
+		ServerAddr: serverAddr,
+		AuthHeader: authHeader,
+		batchSize:  batchSize,
+		// 批次满或到达刷新间隔时发送（以先到者为准），刷新间隔不超过 maxPushFlushInterval，避免面板在两批之间误判离线
+		flushInterval: min(time.Duration(batchSize*reportInterval)*time.Second, maxPushFlushInterval),
+		client:        httpClient,
+		ctx:           ctx,
+		cancel:        cancel,
+		sendChan:      make(chan []byte, 100),
+		logger:        logger,
+		errorHandler:  errorHandler,
+		memoryPool:    memoryPool,
+	}
+}
+
+// Start 启动推送循环
+func (c *HTTPPushClient) Start() {
+	c.logger.Infof("HTTP 推送模式已启用，服务器地址：%s，批量大小：%d", c.ServerAddr, c.batchSize)
+	c.wg.Add(1)
+	go c.pushLoop()
+}
+
+// SendJsonMsg 序列化并加入发送队列
+func (c *HTTPPushClient) SendJsonMsg(obj interface{}) {
+	c.mu.RLock()
+	if c.closed {
+		c.mu.RUnlock()
+		return
+	}
+	c.mu.RUnlock()
+
+	data, err := c.memoryPool.OptimizedJSONMarshal(obj)
+	if err != nil {
+		jsonErr := NewAppError(ErrorTypeData, SeverityMedium, "JSON序列化失败", err)
+		c.errorHandler.HandleError(jsonErr)
+		return
+	}
+
+	select {
+	case c.sendChan <- data:
+	case <-time.After(time.Second * 5):
+		timeoutErr := NewAppError(ErrorTypeNetwork, SeverityMedium, "发送队列已满，消息发送超时", nil)
+		c.errorHandler.HandleError(timeoutErr)
+	}
+}
+
+// pushLoop 收集上报数据，批次满或到达刷新间隔时推送
+func (c *HTTPPushClient) pushLoop() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.flushInterval)
+	defer ticker.Stop()
+
+	var pending [][]byte
+	for {
+		select {
+		case <-c.ctx.Done():
+			// 退出前尽力推送剩余数据
+			if len(pending) > 0 {
+				ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+				_ = c.push(ctx, pending)
+				cancel()
+			}
+			return
+		case data := <-c.sendChan:
+			pending = c.enqueue(pending, data)
+			if len(pending) >= c.batchSize {
+				pending = c.flush(pending)
+			}
+		case <-ticker.C:
+			if len(pending) > 0 {
+				pending = c.flush(pending)
+			}
+		}
+	}
+}
+
+// enqueue 加入待发送列表，超出上限时丢弃最旧的数据
+func (c *HTTPPushClient) enqueue(pending [][]byte, data []byte) [][]byte {
+	pending = append(pending, data)
+	if over := len(pending) - maxPendingReports; over > 0 {
+		pending = pending[over:]
+		c.mu.Lock()
+		c.dropped += int64(over)
+		c.mu.Unlock()
+	}
+	return pending
+}
+
+// flush 推送待发送数据，成功返回空列表，失败保留数据等待下次重试
+func (c *HTTPPushClient) flush(pending [][]byte) [][]byte {
+	ctx, cancel := context.WithTimeout(c.ctx, 30*time.Second)
+	defer cancel()
+
+	if err := c.push(ctx, pending); err != nil {
+		pushErr := NewAppError(ErrorTypeNetwork, SeverityMedium,
+			fmt.Sprintf("HTTP 推送失败 (%d 条数据待重试)", len(pending)), err)
+		c.errorHandler.HandleError(pushErr)
+		c.mu.Lock()
+		c.sendFailures++
+		c.mu.Unlock()
+		return pending
+	}
+	return pending[:0]
+}
+
+// push 以 JSON 数组的形式发送一批上报数据
+func (c *HTTPPushClient) push(ctx context.Context, batch [][]byte) error {
+	body := c.memoryPool.GetBuffer()
+	defer c.memoryPool.PutBuffer(body)
+
+	body.WriteByte('[')
+	for i, item := range batch {
+		if i > 0 {
+			body.WriteByte(',')
+		}
+		body.Write(item)
+	}
+	body.WriteByte(']')
+	size := body.Len()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.ServerAddr, bytes.NewReader(body.Bytes()))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header = c.AuthHeader.Clone()
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body) // 读完响应体以复用连接
+		return fmt.Errorf("服务器返回状态码 %d", resp.StatusCode)
+	}
+	c.dispatchCommand(resp.Body)
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	c.mu.Lock()
+	c.messagesSent += int64(len(batch))
+	c.batchesSent++
+	c.bytesSent += int64(size)
+	c.mu.Unlock()
+	return nil
+}
+
+// dispatchCommand 解析推送响应中面板下发的指令（data.command）
+func (c *HTTPPushClient) dispatchCommand(body io.Reader) {
+	var result struct {
+		Data struct {
+			Command *model.AgentCommand `json:"command"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(body, maxCommandSize)).Decode(&result); err != nil {
+		return
+	}
+	if cmd := result.Data.Command; cmd != nil && c.OnCommand != nil {
+		c.OnCommand(cmd)
+	}
+}
+
+// GetStats 获取推送统计信息
+func (c *HTTPPushClient) GetStats() map[string]int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return map[string]int64{
+		"messages_sent": c.messagesSent,
+		"batches_sent":  c.batchesSent,
+		"bytes_sent":    c.bytesSent,
+		"send_failures": c.sendFailures,
+		"dropped":       c.dropped,
+	}
+}
+
+// Close 关闭推送客户端
+func (c *HTTPPushClient) Close() {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.closed = true
+	c.mu.Unlock()
+
+	c.cancel()
+	c.wg.Wait()
+}
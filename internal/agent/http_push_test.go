@@ -0,0 +1,196 @@
+package internal
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ruanun/simple-server-status/internal/agent/config"
+	"github.com/ruanun/simple-server-status/pkg/model"
+	"go.uber.org/zap"
+)
+
+// TestResolveTransport 测试上报方式选择
+func TestResolveTransport(t *testing.T) {
+	tests := []struct {
+		name          string
+		transport     string
+		serverAddr    string
+		wantTransport string
+		wantAddr      string
+	}{
+		{"自动 - ws", "", "ws://host:8900/ws-report", TransportWebSocket, "ws://host:8900/ws-report"},
+		{"自动 - wss", "", "wss://host/ws-report", TransportWebSocket, "wss://host/ws-report"},
+		{"自动 - http", "", "http://host:8900/ws-report", TransportHTTP, "http://host:8900/ws-report"},
+		{"自动 - https", "", "https://host/ws-report", TransportHTTP, "https://host/ws-report"},
+		{"显式 http - 映射 ws", "http", "ws://host:8900/ws-report", TransportHTTP, "http://host:8900/ws-report"},
+		{"显式 http - 映射 wss", "HTTP", "wss://host/ws-report", TransportHTTP, "https://host/ws-report"},
+		{"显式 ws - 映射 https", "ws", "https://host/ws-report", TransportWebSocket, "wss://host/ws-report"},
+		{"显式 ws - 保持 ws", "ws", "ws://host/ws-report", TransportWebSocket, "ws://host/ws-report"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.AgentConfig{Transport: tt.transport, ServerAddr: tt.serverAddr}
+			transport, addr := ResolveTransport(cfg)
+			if transport != tt.wantTransport {
+				t.Errorf("transport = %s; want %s", transport, tt.wantTransport)
+			}
+			if addr != tt.wantAddr {
+				t.Errorf("addr = %s; want %s", addr, tt.wantAddr)
+			}
+		})
+	}
+}
+
+// TestHTTPPushClient_FlushInterval 测试刷新间隔不超过面板的离线判定时间
+func TestHTTPPushClient_FlushInterval(t *testing.T) {
+	tests := []struct {
+		name           string
+		reportInterval int
+		batchSize      int
+		want           time.Duration
+	}{
+		{"默认配置", 2, 5, 10 * time.Second},
+		{"未设置批量", 2, 0, 2 * time.Second},
+		{"大批量封顶", 2, 100, maxPushFlushInterval},
+		{"长上报间隔封顶", 60, 1, maxPushFlushInterval},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.AgentConfig{ReportTimeInterval: tt.reportInterval, PushBatchSize: tt.batchSize}
+			c := NewHTTPPushClient(cfg, "http://127.0.0.1:8900/ws-report", nil, zap.NewNop().Sugar(), nil, nil)
+			if c.flushInterval != tt.want {
+				t.Errorf("flushInterval = %s; want %s", c.flushInterval, tt.want)
+			}
+		})
+	}
+}
+
+// TestHTTPPushClient_BatchPush 测试批量推送
+func TestHTTPPushClient_BatchPush(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]model.ServerInfo
+	var gotHeader http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var batch []model.ServerInfo
+		if err := json.Unmarshal(body, &batch); err != nil {
+			t.Errorf("请求体不是 ServerInfo 数组: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		batches = append(batches, batch)
+		gotHeader = r.Header.Clone()
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"code":200,"data":{"accepted":3,"command":{"type":"probes","probes":[{"name":"gw","type":"icmp","host":"10.0.0.1"}]}}}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.AgentConfig{
+		ServerId:           "web-01",
+		AuthSecret:         "secret-123456",
+		ReportTimeInterval: 60, // 刷新间隔足够长，确保由批量大小触发推送
+		PushBatchSize:      3,
+	}
+	logger := zap.NewNop().Sugar()
+	client := NewHTTPPushClient(cfg, server.URL, NewHTTPClient(nil, 5*time.Second), logger,
+		NewErrorHandler(logger, nil), NewMemoryPoolManager())
+	var gotCommand *model.AgentCommand
+	client.OnCommand = func(cmd *model.AgentCommand) {
+		mu.Lock()
+		gotCommand = cmd
+		mu.Unlock()
+	}
+	client.Start()
+
+	for i := 0; i < 3; i++ {
+		client.SendJsonMsg(&model.ServerInfo{CpuInfo: &model.CpuInfo{Percent: float64(i)}})
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if client.GetStats()["batches_sent"] == 1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	client.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(batches) != 1 {
+		t.Fatalf("推送批次 = %d; want 1", len(batches))
+	}
+	if len(batches[0]) != 3 {
+		t.Fatalf("批次大小 = %d; want 3", len(batches[0]))
+	}
+	if batches[0][2].CpuInfo.Percent != 2 {
+		t.Errorf("上报顺序错误: %+v", batches[0][2].CpuInfo)
+	}
+	if gotHeader.Get("X-SERVER-ID") != "web-01" || gotHeader.Get("X-AUTH-SECRET") != "secret-123456" {
+		t.Errorf("认证头缺失: %v", gotHeader)
+	}
+	// 推送响应中的面板指令
+	if gotCommand == nil || gotCommand.Type != model.CommandProbes || len(gotCommand.Probes) != 1 || gotCommand.Probes[0].Host != "10.0.0.1" {
+		t.Errorf("面板指令 = %+v", gotCommand)
+	}
+
+	stats := client.GetStats()
+	if stats["messages_sent"] != 3 {
+		t.Errorf("messages_sent = %d; want 3", stats["messages_sent"])
+	}
+}
+
+// TestHTTPPushClient_RetryOnFailure 测试推送失败后保留数据重试
+func TestHTTPPushClient_RetryOnFailure(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		n := calls
+		mu.Unlock()
+		if n == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := &config.AgentConfig{ServerId: "web-01", AuthSecret: "secret-123456", ReportTimeInterval: 60, PushBatchSize: 1}
+	logger := zap.NewNop().Sugar()
+	client := NewHTTPPushClient(cfg, server.URL, NewHTTPClient(nil, 5*time.Second), logger,
+		NewErrorHandler(logger, nil), NewMemoryPoolManager())
+	client.Start()
+
+	// 第一条失败后保留，第二条触发时一并重试
+	client.SendJsonMsg(&model.ServerInfo{})
+	client.SendJsonMsg(&model.ServerInfo{})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if client.GetStats()["messages_sent"] == 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	client.Close()
+
+	stats := client.GetStats()
+	if stats["send_failures"] != 1 {
+		t.Errorf("send_failures = %d; want 1", stats["send_failures"])
+	}
+	if stats["messages_sent"] != 2 {
+		t.Errorf("messages_sent = %d; want 2", stats["messages_sent"])
+	}
+}
@@ -28,6 +28,17 @@ type PerformanceMetrics struct {
 	Errors            int64     `json:"errors"`
 	Uptime            float64   `json:"uptime_seconds"`
 	LastUpdate        time.Time `json:"last_update"`
+
+	// 采集器耗时
+	Collectors map[string]CollectorLatency `json:"collectors,omitempty"`
+}
+
+// CollectorLatency 单个采集器的耗时统计
+type CollectorLatency struct {
+	LastMs   float64 `json:"last_ms"`  // 最近一次耗时
+	MaxMs    float64 `json:"max_ms"`   // 最大耗时
+	Runs     int64   `json:"runs"`     // 采集次数
+	Timeouts int64   `json:"timeouts"` // 超时次数
 }
 
 // PerformanceMonitor 性能监控器
@@ -45,6 +56,7 @@ type PerformanceMonitor struct {
 	dataCollectionCount   int64
 	webSocketMessageCount int64
 	errorCount            int64
+	collectorLatency      map[string]CollectorLatency
 
 	// 网络基线
 	lastNetworkSent     uint64
@@ -58,12 +70,13 @@ func NewPerformanceMonitor(logger interface{ Infof(string, ...interface{}) }) *P
 		metrics: &PerformanceMetrics{
 			LastUpdate: time.Now(),
 		},
-		startTime:       time.Now(),
-		ctx:             ctx,
-		cancel:          cancel,
-		collectInterval: time.Second * 30, // 每30秒收集一次指标
-		logInterval:     time.Minute * 5,  // 每5分钟记录一次日志
-		logger:          logger,
+		startTime:        time.Now(),
+		collectorLatency: make(map[string]CollectorLatency),
+		ctx:              ctx,
+		cancel:           cancel,
+		collectInterval:  time.Second * 30, // 每30秒收集一次指标
+		logInterval:      time.Minute * 5,  // 每5分钟记录一次日志
+		logger:           logger,
 	}
 
 	// 启动监控
@@ -152,6 +165,13 @@ func (pm *PerformanceMonitor) logMetrics() {
 	pm.logger.Infof("应用指标 - 数据收集: %d次, WebSocket消息: %d条, 错误: %d个",
 		metrics.DataCollections, metrics.WebSocketMessages, metrics.Errors)
 
+	for name, l := range pm.GetMetrics().Collectors {
+		if l.Timeouts > 0 {
+			pm.logger.Infof("采集器指标 - %s: 最近耗时 %.1fms, 最大耗时 %.1fms, 超时 %d/%d次",
+				name, l.LastMs, l.MaxMs, l.Timeouts, l.Runs)
+		}
+	}
+
 	if metrics.NetworkSent > 0 || metrics.NetworkReceived > 0 {
 		pm.logger.Infof("网络指标 - 发送: %d字节, 接收: %d字节",
 			metrics.NetworkSent, metrics.NetworkReceived)
@@ -165,9 +185,33 @@ func (pm *PerformanceMonitor) GetMetrics() *PerformanceMetrics {
 
 	// 返回指标的副本
 	metricsCopy := *pm.metrics
+	if len(pm.collectorLatency) > 0 {
+		metricsCopy.Collectors = make(map[string]CollectorLatency, len(pm.collectorLatency))
+		for name, l := range pm.collectorLatency {
+			metricsCopy.Collectors[name] = l
+		}
+	}
 	return &metricsCopy
 }
 
+// RecordCollectorLatency 记录采集器耗时
+func (pm *PerformanceMonitor) RecordCollectorLatency(name string, elapsed time.Duration, timedOut bool) {
+	ms := float64(elapsed) / float64(time.Millisecond)
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	l := pm.collectorLatency[name]
+	l.LastMs = ms
+	if ms > l.MaxMs {
+		l.MaxMs = ms
+	}
+	l.Runs++
+	if timedOut {
+		l.Timeouts++
+	}
+	pm.collectorLatency[name] = l
+}
+
 // IncrementDataCollection 增加数据收集计数
 func (pm *PerformanceMonitor) IncrementDataCollection() {
 	pm.mu.Lock()
@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// mountInfoPath 当前进程可见的挂载信息，仅 Linux 存在
+const mountInfoPath = "/proc/self/mountinfo"
+
+// readMountDeviceIDs 读取各挂载点所在文件系统的设备号（major:minor）
+// 同一设备的绑定挂载、同一 btrfs 文件系统的各子卷设备号相同，可据此去重；读取失败时返回 nil
+func readMountDeviceIDs() map[string]string {
+	f, err := os.Open(mountInfoPath)
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+	return parseMountInfo(f)
+}
+
+// parseMountInfo 解析 mountinfo，返回挂载点到设备号的映射
+// 每行格式：36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+func parseMountInfo(r io.Reader) map[string]string {
+	ids := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || !strings.Contains(fields[2], ":") {
+			continue
+		}
+		ids[unescapeMountPath(fields[4])] = fields[2]
+	}
+	return ids
+}
+
+// unescapeMountPath 还原 mountinfo 中以八进制转义的空格、制表符、换行和反斜杠
+func unescapeMountPath(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
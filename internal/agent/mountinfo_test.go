@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseMountInfo 测试 mountinfo 解析
+func TestParseMountInfo(t *testing.T) {
+	data := `22 1 8:2 / / rw,relatime shared:1 - ext4 /dev/sda2 rw
+23 22 8:2 /var/lib/docker /srv/docker rw,relatime shared:1 - ext4 /dev/sda2 rw
+24 22 0:31 /@home /home rw,relatime shared:2 - btrfs /dev/sdb1 rw,subvol=/@home
+25 22 0:31 /@data /mnt/my\040data rw,relatime shared:3 - btrfs /dev/sdb1 rw,subvol=/@data
+26 22 0:45 / /run rw,nosuid - tmpfs tmpfs rw
+broken line
+`
+	ids := parseMountInfo(strings.NewReader(data))
+
+	tests := []struct {
+		mountpoint string
+		want       string
+	}{
+		{"/", "8:2"},
+		{"/srv/docker", "8:2"},
+		{"/home", "0:31"},
+		{"/mnt/my data", "0:31"},
+		{"/run", "0:45"},
+	}
+	for _, tt := range tests {
+		if got := ids[tt.mountpoint]; got != tt.want {
+			t.Errorf("ids[%q] = %q; want %q", tt.mountpoint, got, tt.want)
+		}
+	}
+	if len(ids) != len(tests) {
+		t.Errorf("len(ids) = %d; want %d", len(ids), len(tests))
+	}
+}
+
+// TestUnescapeMountPath 测试挂载路径的转义还原
+func TestUnescapeMountPath(t *testing.T) {
+	tests := map[string]string{
+		`/mnt/plain`:        "/mnt/plain",
+		`/mnt/a\040b`:       "/mnt/a b",
+		`/mnt/tab\011x`:     "/mnt/tab\tx",
+		`/mnt/back\134path`: `/mnt/back\path`,
+		`/mnt/bad\09`:       `/mnt/bad\09`,
+	}
+	for in, want := range tests {
+		if got := unescapeMountPath(in); got != want {
+			t.Errorf("unescapeMountPath(%q) = %q; want %q", in, got, want)
+		}
+	}
+}
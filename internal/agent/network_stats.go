@@ -2,6 +2,13 @@ package internal
 
 import (
 	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -10,15 +17,53 @@ import (
 	"github.com/shirou/gopsutil/v4/net"
 )
 
+// defaultNetInterfaceExclude 未配置过滤规则时不单独上报的网卡，与聚合统计排除的虚拟网卡一致
+var defaultNetInterfaceExclude = []string{"lo", "tun*", "docker*", "veth*", "br-*", "vmbr*", "vnet*", "kube*"}
+
+// netLinkRefreshInterval 链路状态、MTU、速率与地址的刷新周期，这些信息很少变化，不必每秒读取
+const netLinkRefreshInterval = 30 * time.Second
+
+// netMinRateInterval 两次更新的最小间隔，间隔过短时计数器增量太小，算出的速度误差大，此时跳过本次更新
+const netMinRateInterval = 100 * time.Millisecond
+
 // NetworkStatsCollector 线程安全的网络统计收集器
+// 速度由各网卡计数器的增量除以两次更新之间的单调时钟间隔得到，单个网卡的计数器回绕、重置或网卡增减不影响其他网卡
 type NetworkStatsCollector struct {
-	mu                 sync.RWMutex
-	netInSpeed         uint64
-	netOutSpeed        uint64
-	netInTransfer      uint64
-	netOutTransfer     uint64
-	lastUpdateNetStats uint64
-	excludeInterfaces  []string
+	mu                sync.RWMutex
+	netInRate         float64
+	netOutRate        float64
+	hasRate           bool // 是否已算出过聚合速度，第一个速度直接作为平滑的初值
+	netInTransfer     uint64
+	netOutTransfer    uint64
+	excludeInterfaces []string
+
+	ioCountersFn func() ([]net.IOCountersStat, error)
+	interfacesFn func() (net.InterfaceStatList, error)
+	sysfsRoot    string // 链路状态与速率的来源，仅 Linux 存在
+	now          func() time.Time
+	smoothing    time.Duration // EWMA 时间常数，0 表示不平滑
+
+	filter       *NameFilter
+	interfaces   []*model.NetInterface
+	lastCounters map[string]net.IOCountersStat
+	lastTime     time.Time
+	rates        map[string]netRate // 各网卡（平滑后）的速率，只包含已有有效增量的网卡
+	links        map[string]netLink
+	linksTime    time.Time
+}
+
+// netRate 网卡的每秒速率
+type netRate struct {
+	in, out               float64
+	inPackets, outPackets float64
+}
+
+// netLink 网卡的链路信息
+type netLink struct {
+	operState string
+	mtu       int
+	speed     int
+	addrs     []string
 }
 
 // NewNetworkStatsCollector 创建网络统计收集器
@@ -28,62 +73,208 @@ func NewNetworkStatsCollector(excludeInterfaces []string) *NetworkStatsCollector
 			"lo", "tun", "docker", "veth", "br-", "vmbr", "vnet", "kube",
 		}
 	}
+	filter, _ := NewNameFilter(nil, defaultNetInterfaceExclude)
 	return &NetworkStatsCollector{
 		excludeInterfaces: excludeInterfaces,
+		ioCountersFn:      func() ([]net.IOCountersStat, error) { return net.IOCounters(true) },
+		interfacesFn:      net.Interfaces,
+		sysfsRoot:         "/sys/class/net",
+		now:               time.Now,
+		filter:            filter,
 	}
 }
 
+// SetFilter 设置单独上报的网卡过滤规则；nil 时恢复默认规则
+// 只影响各网卡的明细，聚合的速度与流量仍按 excludeInterfaces 统计，与旧版本保持一致
+func (nsc *NetworkStatsCollector) SetFilter(filter *NameFilter) {
+	if filter == nil {
+		filter, _ = NewNameFilter(nil, defaultNetInterfaceExclude)
+	}
+	nsc.mu.Lock()
+	nsc.filter = filter
+	nsc.mu.Unlock()
+}
+
+// SetSmoothing 设置 EWMA 平滑的时间常数，0 表示不平滑
+// 权重按实际间隔计算（1 - e^(-Δt/τ)），更新间隔不规则时平滑程度保持一致
+func (nsc *NetworkStatsCollector) SetSmoothing(tau time.Duration) {
+	nsc.mu.Lock()
+	nsc.smoothing = tau
+	nsc.mu.Unlock()
+}
+
 // Update 更新网络统计（在单独的 goroutine 中调用）
 func (nsc *NetworkStatsCollector) Update() error {
-	netIOs, err := net.IOCounters(true)
+	netIOs, err := nsc.ioCountersFn()
 	if err != nil {
 		return fmt.Errorf("获取网络IO统计失败: %w", err)
 	}
+	// time.Now 带单调时钟读数，系统时间被调整时间隔依然准确
+	now := nsc.now()
+
+	// 使用写锁保护并发写入
+	nsc.mu.Lock()
+	defer nsc.mu.Unlock()
+
+	elapsed := now.Sub(nsc.lastTime)
+	if nsc.lastCounters != nil && elapsed < netMinRateInterval {
+		return nil
+	}
+	nsc.refreshLinks(now)
 
-	var innerNetInTransfer, innerNetOutTransfer uint64
+	seconds := elapsed.Seconds()
+	alpha := nsc.smoothingAlpha(elapsed)
+	var inTransfer, outTransfer uint64
+	var inRate, outRate float64
+	interfaces := make([]*model.NetInterface, 0, len(netIOs))
+	counters := make(map[string]net.IOCountersStat, len(netIOs))
+	rates := make(map[string]netRate, len(netIOs))
 	for _, v := range netIOs {
-		if isListContainsStr(nsc.excludeInterfaces, v.Name) {
+		counters[v.Name] = v
+
+		// 新出现的网卡或计数器回绕、重置的网卡本次没有有效增量，不计入速度，也不影响其他网卡
+		raw, ok := interfaceRate(nsc.lastCounters, v, seconds)
+		var rate netRate
+		if ok {
+			rate = raw
+			if prev, had := nsc.rates[v.Name]; had {
+				rate = netRate{
+					in:         ewma(prev.in, raw.in, alpha),
+					out:        ewma(prev.out, raw.out, alpha),
+					inPackets:  ewma(prev.inPackets, raw.inPackets, alpha),
+					outPackets: ewma(prev.outPackets, raw.outPackets, alpha),
+				}
+			}
+			rates[v.Name] = rate
+		}
+
+		if !isListContainsStr(nsc.excludeInterfaces, v.Name) {
+			inTransfer += v.BytesRecv
+			outTransfer += v.BytesSent
+			inRate += raw.in
+			outRate += raw.out
+		}
+
+		if !nsc.filter.Match(v.Name) {
 			continue
 		}
-		innerNetInTransfer += v.BytesRecv
-		innerNetOutTransfer += v.BytesSent
+		iface := &model.NetInterface{
+			Name:        v.Name,
+			InSpeed:     roundRate(rate.in),
+			OutSpeed:    roundRate(rate.out),
+			InTransfer:  v.BytesRecv,
+			OutTransfer: v.BytesSent,
+			InPackets:   roundRate(rate.inPackets),
+			OutPackets:  roundRate(rate.outPackets),
+			InErrors:    v.Errin,
+			OutErrors:   v.Errout,
+			InDrops:     v.Dropin,
+			OutDrops:    v.Dropout,
+		}
+		if link, ok := nsc.links[v.Name]; ok {
+			iface.OperState = link.operState
+			iface.MTU = link.mtu
+			iface.LinkSpeed = link.speed
+			iface.Addrs = link.addrs
+		}
+		interfaces = append(interfaces, iface)
 	}
+	sort.Slice(interfaces, func(i, j int) bool { return interfaces[i].Name < interfaces[j].Name })
 
-	// 获取当前时间戳并安全转换为 uint64
-	timestamp := time.Now().Unix()
-	if timestamp < 0 {
-		// 理论上不会发生（Unix时间戳始终为正），但为安全起见进行检查
-		timestamp = 0
+	// 聚合速度单独平滑：网卡增减时各网卡的平滑状态不同，直接相加会产生跳变
+	if nsc.lastCounters != nil {
+		if !nsc.hasRate {
+			alpha = 1
+		}
+		nsc.netInRate = ewma(nsc.netInRate, inRate, alpha)
+		nsc.netOutRate = ewma(nsc.netOutRate, outRate, alpha)
+		nsc.hasRate = true
 	}
-	//nolint:gosec // G115: 已在上方进行负数检查，转换安全
-	now := uint64(timestamp)
+	nsc.netInTransfer = inTransfer
+	nsc.netOutTransfer = outTransfer
+	nsc.interfaces = interfaces
+	nsc.lastCounters = counters
+	nsc.lastTime = now
+	nsc.rates = rates
 
-	// 使用写锁保护并发写入
-	nsc.mu.Lock()
-	defer nsc.mu.Unlock()
+	return nil
+}
 
-	diff := now - nsc.lastUpdateNetStats
-	if diff > 0 {
-		// 检测计数器回绕或网络接口重置
-		if innerNetInTransfer >= nsc.netInTransfer {
-			nsc.netInSpeed = (innerNetInTransfer - nsc.netInTransfer) / diff
-		} else {
-			// 发生回绕或重置，从新值开始计算
-			nsc.netInSpeed = 0
-		}
+// interfaceRate 根据上次的计数器计算网卡的每秒速率
+// 网卡首次出现或任一计数器减少（回绕、驱动重载、网卡重建）时返回 false
+func interfaceRate(last map[string]net.IOCountersStat, cur net.IOCountersStat, seconds float64) (netRate, bool) {
+	prev, ok := last[cur.Name]
+	if !ok || seconds <= 0 {
+		return netRate{}, false
+	}
+	if cur.BytesRecv < prev.BytesRecv || cur.BytesSent < prev.BytesSent ||
+		cur.PacketsRecv < prev.PacketsRecv || cur.PacketsSent < prev.PacketsSent {
+		return netRate{}, false
+	}
+	return netRate{
+		in:         float64(cur.BytesRecv-prev.BytesRecv) / seconds,
+		out:        float64(cur.BytesSent-prev.BytesSent) / seconds,
+		inPackets:  float64(cur.PacketsRecv-prev.PacketsRecv) / seconds,
+		outPackets: float64(cur.PacketsSent-prev.PacketsSent) / seconds,
+	}, true
+}
+
+// smoothingAlpha 计算本次采样的 EWMA 权重，未开启平滑时为 1（直接使用本次速率）
+func (nsc *NetworkStatsCollector) smoothingAlpha(elapsed time.Duration) float64 {
+	if nsc.smoothing <= 0 || elapsed <= 0 {
+		return 1
+	}
+	return 1 - math.Exp(-elapsed.Seconds()/nsc.smoothing.Seconds())
+}
+
+func ewma(prev, cur, alpha float64) float64 {
+	return prev + alpha*(cur-prev)
+}
+
+// roundRate 将速率四舍五入为整数上报
+func roundRate(rate float64) uint64 {
+	if rate <= 0 {
+		return 0
+	}
+	return uint64(math.Round(rate))
+}
+
+// refreshLinks 按 netLinkRefreshInterval 刷新链路信息，获取失败时保留各网卡的流量统计
+func (nsc *NetworkStatsCollector) refreshLinks(now time.Time) {
+	if nsc.links != nil && now.Sub(nsc.linksTime) < netLinkRefreshInterval {
+		return
+	}
+	nsc.linksTime = now
 
-		if innerNetOutTransfer >= nsc.netOutTransfer {
-			nsc.netOutSpeed = (innerNetOutTransfer - nsc.netOutTransfer) / diff
+	ifaces, err := nsc.interfacesFn()
+	if err != nil {
+		nsc.links = map[string]netLink{}
+		return
+	}
+	links := make(map[string]netLink, len(ifaces))
+	for _, iface := range ifaces {
+		link := netLink{mtu: iface.MTU}
+		for _, addr := range iface.Addrs {
+			link.addrs = append(link.addrs, addr.Addr)
+		}
+		dir := filepath.Join(nsc.sysfsRoot, iface.Name)
+		if state, err := os.ReadFile(filepath.Join(dir, "operstate")); err == nil {
+			link.operState = strings.TrimSpace(string(state))
+		} else if slices.Contains(iface.Flags, "up") {
+			// 非 Linux 系统没有 sysfs，根据接口标志判断
+			link.operState = "up"
 		} else {
-			// 发生回绕或重置，从新值开始计算
-			nsc.netOutSpeed = 0
+			link.operState = "down"
 		}
+		// 虚拟网卡读取 speed 会失败或返回 -1
+		if data, err := os.ReadFile(filepath.Join(dir, "speed")); err == nil {
+			if speed, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil && speed > 0 {
+				link.speed = speed
+			}
+		}
+		links[iface.Name] = link
 	}
-	nsc.netInTransfer = innerNetInTransfer
-	nsc.netOutTransfer = innerNetOutTransfer
-	nsc.lastUpdateNetStats = now
-
-	return nil
+	nsc.links = links
 }
 
 // GetStats 获取当前网络统计（线程安全）
@@ -93,9 +284,10 @@ func (nsc *NetworkStatsCollector) GetStats() *model.NetworkInfo {
 	defer nsc.mu.RUnlock()
 
 	return &model.NetworkInfo{
-		NetInSpeed:     nsc.netInSpeed,
-		NetOutSpeed:    nsc.netOutSpeed,
+		NetInSpeed:     roundRate(nsc.netInRate),
+		NetOutSpeed:    roundRate(nsc.netOutRate),
 		NetInTransfer:  nsc.netInTransfer,
 		NetOutTransfer: nsc.netOutTransfer,
+		Interfaces:     nsc.interfaces,
 	}
 }
@@ -1,9 +1,13 @@
 package internal
 
 import (
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/shirou/gopsutil/v4/net"
 )
 
 // TestNewNetworkStatsCollector 测试创建网络统计收集器
@@ -263,6 +267,228 @@ func TestNetworkStatsCollector_ZeroTimeInterval(t *testing.T) {
 	t.Logf("速度: In=%d, Out=%d（时间间隔可能为0）", stats.NetInSpeed, stats.NetOutSpeed)
 }
 
+// newFakeNetworkStatsCollector 创建使用固定计数器、固定时间与模拟 sysfs 目录的网络统计收集器
+func newFakeNetworkStatsCollector(t *testing.T, counters *[]net.IOCountersStat, now *time.Time) *NetworkStatsCollector {
+	t.Helper()
+	root := t.TempDir()
+	for name, files := range map[string]map[string]string{
+		"eth0":  {"operstate": "up\n", "speed": "1000\n"},
+		"wg0":   {"operstate": "unknown\n", "speed": "-1\n"},
+		"veth1": {"operstate": "up\n"},
+	} {
+		dir := filepath.Join(root, name)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		for file, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	nsc := NewNetworkStatsCollector(nil)
+	nsc.sysfsRoot = root
+	nsc.now = func() time.Time { return *now }
+	nsc.ioCountersFn = func() ([]net.IOCountersStat, error) { return *counters, nil }
+	nsc.interfacesFn = func() (net.InterfaceStatList, error) {
+		return net.InterfaceStatList{
+			{Name: "eth0", MTU: 1500, Flags: []string{"up"}, Addrs: net.InterfaceAddrList{{Addr: "10.0.0.2/24"}, {Addr: "fe80::1/64"}}},
+			{Name: "wg0", MTU: 1420, Flags: []string{"up"}, Addrs: net.InterfaceAddrList{{Addr: "10.8.0.1/24"}}},
+			{Name: "veth1", MTU: 1500},
+			{Name: "en0", MTU: 1500, Flags: []string{"up"}},
+		}, nil
+	}
+	return nsc
+}
+
+// TestNetworkStatsCollector_Interfaces 测试各网卡的速度、包速率与链路信息
+func TestNetworkStatsCollector_Interfaces(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	counters := []net.IOCountersStat{
+		{Name: "eth0", BytesRecv: 1000, BytesSent: 500, PacketsRecv: 10, PacketsSent: 5},
+		{Name: "wg0", BytesRecv: 100, BytesSent: 100},
+		{Name: "veth1", BytesRecv: 100, BytesSent: 100},
+		{Name: "en0", BytesRecv: 0},
+	}
+	nsc := newFakeNetworkStatsCollector(t, &counters, &now)
+
+	if err := nsc.Update(); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	first := nsc.GetStats().Interfaces
+	if len(first) != 3 || first[0].InSpeed != 0 {
+		t.Fatalf("首次更新应只记录计数器: %+v", first)
+	}
+
+	now = now.Add(2 * time.Second)
+	counters = []net.IOCountersStat{
+		{Name: "eth0", BytesRecv: 5000, BytesSent: 2500, PacketsRecv: 50, PacketsSent: 25, Errin: 1, Dropout: 2},
+		{Name: "wg0", BytesRecv: 50, BytesSent: 300},
+		{Name: "veth1", BytesRecv: 900, BytesSent: 900},
+		{Name: "en0", BytesRecv: 400},
+	}
+	if err := nsc.Update(); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	stats := nsc.GetStats()
+	byName := map[string]int{}
+	for i, iface := range stats.Interfaces {
+		byName[iface.Name] = i
+	}
+	if _, ok := byName["veth1"]; ok || len(stats.Interfaces) != 3 {
+		t.Fatalf("默认应排除虚拟网卡: %+v", stats.Interfaces)
+	}
+
+	eth0 := stats.Interfaces[byName["eth0"]]
+	if eth0.InSpeed != 2000 || eth0.OutSpeed != 1000 || eth0.InPackets != 20 || eth0.OutPackets != 10 ||
+		eth0.InErrors != 1 || eth0.OutDrops != 2 || eth0.InTransfer != 5000 {
+		t.Errorf("eth0 统计错误: %+v", eth0)
+	}
+	if eth0.OperState != "up" || eth0.MTU != 1500 || eth0.LinkSpeed != 1000 || len(eth0.Addrs) != 2 || eth0.Addrs[1] != "fe80::1/64" {
+		t.Errorf("eth0 链路信息错误: %+v", eth0)
+	}
+	// 计数器减少视为重置，本次没有有效速度；协商速率未知时不上报
+	wg0 := stats.Interfaces[byName["wg0"]]
+	if wg0.InSpeed != 0 || wg0.OutSpeed != 0 || wg0.OperState != "unknown" || wg0.LinkSpeed != 0 {
+		t.Errorf("wg0 统计错误: %+v", wg0)
+	}
+	// 没有 sysfs 时根据接口标志判断链路状态
+	if en0 := stats.Interfaces[byName["en0"]]; en0.OperState != "up" || en0.InSpeed != 200 {
+		t.Errorf("en0 统计错误: %+v", en0)
+	}
+	// 聚合值仍按原有规则统计（eth0、wg0、en0）
+	if stats.NetInTransfer != 5450 || stats.NetOutTransfer != 2800 {
+		t.Errorf("聚合流量 = %d/%d; want 5450/2800", stats.NetInTransfer, stats.NetOutTransfer)
+	}
+
+	// 过滤规则只影响各网卡明细
+	filter, _ := NewNameFilter([]string{"re:^(eth|wg)\\d+$"}, nil)
+	nsc.SetFilter(filter)
+	now = now.Add(time.Second)
+	_ = nsc.Update()
+	if got := nsc.GetStats(); len(got.Interfaces) != 2 || got.NetInTransfer != 5450 {
+		t.Errorf("过滤后网卡 = %+v, 聚合 = %d", got.Interfaces, got.NetInTransfer)
+	}
+	nsc.SetFilter(nil)
+	now = now.Add(time.Second)
+	_ = nsc.Update()
+	if got := nsc.GetStats(); len(got.Interfaces) != 3 {
+		t.Errorf("恢复默认规则后网卡数 = %d; want 3", len(got.Interfaces))
+	}
+}
+
+// netStep 一次网络计数器更新
+type netStep struct {
+	after    time.Duration        // 距上次更新的间隔
+	counters map[string][2]uint64 // 网卡 -> {接收字节, 发送字节}
+	wantIn   uint64               // 期望的聚合下载速度
+	wantOut  uint64               // 期望的聚合上传速度
+	wantEth0 uint64               // 期望的 eth0 下载速度
+}
+
+// TestNetworkStatsCollector_Rates 测试速度计算对回绕、网卡增减与不规则间隔的处理
+func TestNetworkStatsCollector_Rates(t *testing.T) {
+	tests := []struct {
+		name      string
+		smoothing time.Duration
+		steps     []netStep
+	}{
+		{
+			name: "不足一秒的间隔",
+			steps: []netStep{
+				{counters: map[string][2]uint64{"eth0": {0, 0}}},
+				{after: 500 * time.Millisecond, counters: map[string][2]uint64{"eth0": {1000, 500}}, wantIn: 2000, wantOut: 1000, wantEth0: 2000},
+			},
+		},
+		{
+			name: "不规则间隔",
+			steps: []netStep{
+				{counters: map[string][2]uint64{"eth0": {0, 0}}},
+				{after: 1500 * time.Millisecond, counters: map[string][2]uint64{"eth0": {3000, 0}}, wantIn: 2000, wantEth0: 2000},
+				{after: 2500 * time.Millisecond, counters: map[string][2]uint64{"eth0": {8000, 0}}, wantIn: 2000, wantEth0: 2000},
+				{after: 700 * time.Millisecond, counters: map[string][2]uint64{"eth0": {9400, 0}}, wantIn: 2000, wantEth0: 2000},
+			},
+		},
+		{
+			name: "间隔过短时跳过",
+			steps: []netStep{
+				{counters: map[string][2]uint64{"eth0": {0, 0}}},
+				{after: time.Second, counters: map[string][2]uint64{"eth0": {1000, 0}}, wantIn: 1000, wantEth0: 1000},
+				{after: 10 * time.Millisecond, counters: map[string][2]uint64{"eth0": {1100, 0}}, wantIn: 1000, wantEth0: 1000},
+				{after: 990 * time.Millisecond, counters: map[string][2]uint64{"eth0": {3000, 0}}, wantIn: 2000, wantEth0: 2000},
+			},
+		},
+		{
+			name: "单个网卡计数器回绕不影响其他网卡",
+			steps: []netStep{
+				{counters: map[string][2]uint64{"eth0": {1 << 32, 0}, "eth1": {0, 0}}},
+				{after: time.Second, counters: map[string][2]uint64{"eth0": {100, 0}, "eth1": {500, 0}}, wantIn: 500, wantEth0: 0},
+				{after: time.Second, counters: map[string][2]uint64{"eth0": {1100, 0}, "eth1": {1000, 0}}, wantIn: 1500, wantEth0: 1000},
+			},
+		},
+		{
+			name: "新增网卡从下次开始计入",
+			steps: []netStep{
+				{counters: map[string][2]uint64{"eth0": {0, 0}}},
+				{after: time.Second, counters: map[string][2]uint64{"eth0": {1000, 0}, "eth1": {1 << 40, 0}}, wantIn: 1000, wantEth0: 1000},
+				{after: time.Second, counters: map[string][2]uint64{"eth0": {2000, 0}, "eth1": {1<<40 + 300, 0}}, wantIn: 1300, wantEth0: 1000},
+			},
+		},
+		{
+			name: "移除网卡不产生负值或归零",
+			steps: []netStep{
+				{counters: map[string][2]uint64{"eth0": {0, 0}, "eth1": {1 << 40, 1 << 40}}},
+				{after: time.Second, counters: map[string][2]uint64{"eth0": {1000, 100}, "eth1": {1<<40 + 1000, 1<<40 + 1000}}, wantIn: 2000, wantOut: 1100, wantEth0: 1000},
+				{after: time.Second, counters: map[string][2]uint64{"eth0": {2000, 200}}, wantIn: 1000, wantOut: 100, wantEth0: 1000},
+			},
+		},
+		{
+			name:      "EWMA 平滑",
+			smoothing: 2 * time.Second,
+			steps: []netStep{
+				{counters: map[string][2]uint64{"eth0": {0, 0}}},
+				{after: time.Second, counters: map[string][2]uint64{"eth0": {1000, 0}}, wantIn: 1000, wantEth0: 1000},
+				// alpha = 1 - e^(-1/2) ≈ 0.3935，1000 + 0.3935 * (5000 - 1000) ≈ 2574
+				{after: time.Second, counters: map[string][2]uint64{"eth0": {6000, 0}}, wantIn: 2574, wantEth0: 2574},
+				// 间隔越长权重越大：alpha = 1 - e^(-2) ≈ 0.8647，2574 + 0.8647 * (1000 - 2574) ≈ 1213
+				{after: 4 * time.Second, counters: map[string][2]uint64{"eth0": {10000, 0}}, wantIn: 1213, wantEth0: 1213},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Unix(1700000000, 0)
+			var counters []net.IOCountersStat
+			nsc := NewNetworkStatsCollector(nil)
+			nsc.now = func() time.Time { return now }
+			nsc.ioCountersFn = func() ([]net.IOCountersStat, error) { return counters, nil }
+			nsc.interfacesFn = func() (net.InterfaceStatList, error) { return nil, nil }
+			nsc.SetSmoothing(tt.smoothing)
+
+			for i, step := range tt.steps {
+				now = now.Add(step.after)
+				counters = counters[:0]
+				for name, c := range step.counters {
+					counters = append(counters, net.IOCountersStat{Name: name, BytesRecv: c[0], BytesSent: c[1]})
+				}
+				if err := nsc.Update(); err != nil {
+					t.Fatalf("第 %d 次 Update() error = %v", i+1, err)
+				}
+				stats := nsc.GetStats()
+				if stats.NetInSpeed != step.wantIn || stats.NetOutSpeed != step.wantOut {
+					t.Errorf("第 %d 次聚合速度 = %d/%d; want %d/%d", i+1, stats.NetInSpeed, stats.NetOutSpeed, step.wantIn, step.wantOut)
+				}
+				for _, iface := range stats.Interfaces {
+					if iface.Name == "eth0" && iface.InSpeed != step.wantEth0 {
+						t.Errorf("第 %d 次 eth0 速度 = %d; want %d", i+1, iface.InSpeed, step.wantEth0)
+					}
+				}
+			}
+		})
+	}
+}
+
 // BenchmarkNetworkStatsCollector_Update 基准测试：更新性能
 func BenchmarkNetworkStatsCollector_Update(b *testing.B) {
 	nsc := NewNetworkStatsCollector(nil)
@@ -0,0 +1,407 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ruanun/simple-server-status/pkg/model"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// 网络探测的默认值与上限
+const (
+	defaultProbeInterval = 60 * time.Second
+	defaultProbeCount    = 5
+	defaultProbeTimeout  = time.Second
+	maxProbeCount        = 100
+	maxProbeTasks        = 100
+	probeSpacing         = 200 * time.Millisecond // 每轮中相邻两次探测的间隔
+)
+
+// errICMPNotPermitted 非特权 ICMP 与原始套接字都无法创建
+var errICMPNotPermitted = errors.New("没有发送 ICMP 的权限")
+
+// probeSpec 一项探测任务的规则
+type probeSpec struct {
+	name     string
+	typ      string
+	host     string
+	port     int
+	interval time.Duration
+	count    int
+	timeout  time.Duration
+}
+
+// probeRound 一轮探测的原始结果
+type probeRound struct {
+	method string
+	target string
+	sent   int
+	rtts   []time.Duration // 收到响应的各次往返时间
+	err    error           // 本轮无法执行的原因，如解析失败、没有权限
+}
+
+// probeState 一项探测任务的调度状态与最近一轮结果
+type probeState struct {
+	spec    probeSpec
+	next    time.Time
+	running bool
+	result  *model.ProbeResult
+}
+
+// newProbeSpec 校验面板下发的探测任务并应用默认值
+func newProbeSpec(task *model.ProbeTask) (probeSpec, error) {
+	spec := probeSpec{
+		name:     strings.TrimSpace(task.Name),
+		typ:      strings.ToLower(task.Type),
+		host:     strings.TrimSpace(task.Host),
+		port:     task.Port,
+		interval: time.Duration(task.Interval) * time.Second,
+		count:    task.Count,
+		timeout:  time.Duration(task.TimeoutMs) * time.Millisecond,
+	}
+	if spec.typ != model.ProbeTypeTCP && spec.typ != model.ProbeTypeICMP {
+		return spec, fmt.Errorf("不支持的探测类型 %q", task.Type)
+	}
+	if spec.name == "" || spec.host == "" {
+		return spec, errors.New("探测任务缺少名称或目标主机")
+	}
+	if spec.port < 0 || spec.port > 65535 || (spec.typ == model.ProbeTypeTCP && spec.port == 0) {
+		return spec, fmt.Errorf("探测任务 %s 的端口无效", spec.name)
+	}
+	if spec.interval <= 0 {
+		spec.interval = defaultProbeInterval
+	}
+	if spec.count <= 0 {
+		spec.count = defaultProbeCount
+	}
+	spec.count = min(spec.count, maxProbeCount)
+	if spec.timeout <= 0 {
+		spec.timeout = defaultProbeTimeout
+	}
+	return spec, nil
+}
+
+// ProbeCollector 网络探测采集器
+// 执行面板下发的探测任务：每轮向目标发送若干次 ICMP echo 或建立若干次 TCP 连接，计算往返时间、抖动与丢包率；
+// 各任务按自己的周期在后台执行，每次上报携带各任务最近一轮的结果
+type ProbeCollector struct {
+	baseCollector
+	roundFn func(ctx context.Context, spec probeSpec) probeRound
+	now     func() time.Time
+
+	mu     sync.Mutex
+	probes []*probeState
+	wg     sync.WaitGroup // 正在执行的探测
+}
+
+// NewProbeCollector 创建网络探测采集器；面板未下发任务时不上报
+func NewProbeCollector() *ProbeCollector {
+	return &ProbeCollector{
+		baseCollector: baseCollector{name: CollectorProbes},
+		roundFn:       runProbeRound,
+		now:           time.Now,
+	}
+}
+
+// SetTasks 替换全部探测任务；无效的任务被跳过并返回错误，其余任务照常生效
+// 名称、类型、目标与端口不变的任务保留最近一轮结果和调度时间
+func (c *ProbeCollector) SetTasks(tasks []*model.ProbeTask) error {
+	var errs []error
+	if len(tasks) > maxProbeTasks {
+		errs = append(errs, fmt.Errorf("探测任务过多，只执行前 %d 个", maxProbeTasks))
+		tasks = tasks[:maxProbeTasks]
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	probes := make([]*probeState, 0, len(tasks))
+	names := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		if task == nil {
+			continue
+		}
+		spec, err := newProbeSpec(task)
+		if err == nil && names[spec.name] {
+			err = fmt.Errorf("探测任务名称重复: %s", spec.name)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		names[spec.name] = true
+		st := &probeState{spec: spec}
+		for _, old := range c.probes {
+			if o := old.spec; o.name == spec.name && o.typ == spec.typ && o.host == spec.host && o.port == spec.port {
+				st.next, st.result = old.next, old.result
+			}
+		}
+		probes = append(probes, st)
+	}
+	c.probes = probes
+	return errors.Join(errs...)
+}
+
+// Collect 启动到期的探测，返回各任务最近一轮的结果；尚未完成首轮的任务不上报
+func (c *ProbeCollector) Collect(_ context.Context) (PartialInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.probes) == 0 {
+		return func(s *model.ServerInfo) { s.Probes = nil }, nil
+	}
+	now := c.now()
+	results := make([]*model.ProbeResult, 0, len(c.probes))
+	for _, st := range c.probes {
+		if !st.running && !now.Before(st.next) {
+			st.running = true
+			st.next = now.Add(st.spec.interval)
+			c.wg.Add(1)
+			go c.run(st)
+		}
+		if st.result != nil {
+			results = append(results, st.result)
+		}
+	}
+	return func(s *model.ServerInfo) { s.Probes = results }, nil
+}
+
+// run 执行一轮探测并保存结果
+func (c *ProbeCollector) run(st *probeState) {
+	defer c.wg.Done()
+	spec := st.spec
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(spec.count)*(spec.timeout+probeSpacing))
+	defer cancel()
+	result := newProbeResult(spec, c.roundFn(ctx, spec), c.now())
+
+	c.mu.Lock()
+	st.result = result
+	st.running = false
+	c.mu.Unlock()
+}
+
+// newProbeResult 根据一轮的往返时间计算统计值
+func newProbeResult(spec probeSpec, round probeRound, now time.Time) *model.ProbeResult {
+	r := &model.ProbeResult{
+		Name:     spec.name,
+		Target:   round.target,
+		Method:   round.method,
+		Sent:     round.sent,
+		Received: len(round.rtts),
+		ProbedAt: now.Unix(),
+	}
+	if round.err != nil {
+		r.Error = round.err.Error()
+	}
+	if r.Sent > 0 {
+		r.LossPercent = math.Round(float64(r.Sent-r.Received)/float64(r.Sent)*10000) / 100
+	} else if round.err != nil {
+		r.LossPercent = 100
+	}
+	if len(round.rtts) == 0 {
+		return r
+	}
+
+	minRTT, maxRTT, sum := round.rtts[0], round.rtts[0], time.Duration(0)
+	var jitter time.Duration
+	for i, rtt := range round.rtts {
+		minRTT, maxRTT = min(minRTT, rtt), max(maxRTT, rtt)
+		sum += rtt
+		if i > 0 {
+			d := rtt - round.rtts[i-1]
+			jitter += max(d, -d)
+		}
+	}
+	r.RTTMs = durationMs(sum / time.Duration(len(round.rtts)))
+	r.MinMs = durationMs(minRTT)
+	r.MaxMs = durationMs(maxRTT)
+	if len(round.rtts) > 1 {
+		r.JitterMs = durationMs(jitter / time.Duration(len(round.rtts)-1))
+	}
+	return r
+}
+
+// durationMs 转换为毫秒，保留两位小数
+func durationMs(d time.Duration) float64 {
+	return math.Round(float64(d.Microseconds())/10) / 100
+}
+
+// runProbeRound 执行一轮探测；icmp 探测没有权限且配置了端口时回退为 TCP 连接
+func runProbeRound(ctx context.Context, spec probeSpec) probeRound {
+	if spec.typ == model.ProbeTypeICMP {
+		round := icmpRound(ctx, spec)
+		if !errors.Is(round.err, errICMPNotPermitted) || spec.port == 0 {
+			return round
+		}
+	}
+	return tcpRound(ctx, spec)
+}
+
+// resolveProbeHost 解析目标主机，解析耗时不计入往返时间
+func resolveProbeHost(ctx context.Context, host string) (net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return ip, nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return nil, fmt.Errorf("解析 %s 失败", host)
+	}
+	return addrs[0].IP, nil
+}
+
+// waitProbeSpacing 等待下一次探测，上下文结束时返回 false
+func waitProbeSpacing(ctx context.Context, i int) bool {
+	if i == 0 {
+		return true
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(probeSpacing):
+		return true
+	}
+}
+
+// tcpRound 建立若干次 TCP 连接，以握手完成的耗时作为往返时间
+func tcpRound(ctx context.Context, spec probeSpec) probeRound {
+	round := probeRound{method: model.ProbeTypeTCP, target: net.JoinHostPort(spec.host, strconv.Itoa(spec.port))}
+	ip, err := resolveProbeHost(ctx, spec.host)
+	if err != nil {
+		round.err = err
+		return round
+	}
+	addr := net.JoinHostPort(ip.String(), strconv.Itoa(spec.port))
+	d := net.Dialer{Timeout: spec.timeout}
+	for i := 0; i < spec.count && waitProbeSpacing(ctx, i); i++ {
+		round.sent++
+		start := time.Now()
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			continue
+		}
+		round.rtts = append(round.rtts, time.Since(start))
+		_ = conn.Close()
+	}
+	return round
+}
+
+// icmpRound 发送若干次 ICMP echo
+// 优先使用非特权 ICMP 套接字（Linux 需 net.ipv4.ping_group_range 包含 Agent 所在的组），失败时使用原始套接字（需要 root 或 CAP_NET_RAW）
+func icmpRound(ctx context.Context, spec probeSpec) probeRound {
+	round := probeRound{method: model.ProbeTypeICMP, target: spec.host}
+	ip, err := resolveProbeHost(ctx, spec.host)
+	if err != nil {
+		round.err = err
+		return round
+	}
+	p, err := newICMPPinger(ip)
+	if err != nil {
+		round.err = err
+		return round
+	}
+	defer func() { _ = p.conn.Close() }()
+
+	for i := 0; i < spec.count && waitProbeSpacing(ctx, i); i++ {
+		round.sent++
+		deadline := time.Now().Add(spec.timeout)
+		if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+			deadline = d
+		}
+		if rtt, err := p.ping(i+1, deadline); err == nil {
+			round.rtts = append(round.rtts, rtt)
+		}
+	}
+	return round
+}
+
+// icmpPinger 一轮 ICMP 探测使用的套接字
+type icmpPinger struct {
+	conn       *icmp.PacketConn
+	dst        net.Addr
+	ip         net.IP
+	proto      int
+	echo       icmp.Type
+	reply      icmp.Type
+	id         int
+	privileged bool
+}
+
+// newICMPPinger 创建 ICMP 套接字，非特权与原始套接字都不可用时返回 errICMPNotPermitted
+func newICMPPinger(ip net.IP) (*icmpPinger, error) {
+	p := &icmpPinger{ip: ip, id: os.Getpid() & 0xffff}
+	network, rawNetwork, listen := "udp4", "ip4:icmp", "0.0.0.0"
+	p.proto, p.echo, p.reply = 1, ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	if ip.To4() == nil {
+		network, rawNetwork, listen = "udp6", "ip6:ipv6-icmp", "::"
+		p.proto, p.echo, p.reply = 58, ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+	}
+
+	conn, err := icmp.ListenPacket(network, listen)
+	if err == nil {
+		p.conn, p.dst = conn, &net.UDPAddr{IP: ip}
+		return p, nil
+	}
+	conn, err = icmp.ListenPacket(rawNetwork, listen)
+	if err != nil {
+		return nil, errICMPNotPermitted
+	}
+	p.conn, p.dst, p.privileged = conn, &net.IPAddr{IP: ip}, true
+	return p, nil
+}
+
+// ping 发送一次 echo 并等待对应的回复
+// 非特权套接字的标识符由内核改写，只按序号匹配；原始套接字会收到本机所有 ICMP 报文，还需匹配标识符与来源
+func (p *icmpPinger) ping(seq int, deadline time.Time) (time.Duration, error) {
+	msg := icmp.Message{Type: p.echo, Body: &icmp.Echo{ID: p.id, Seq: seq, Data: []byte("simple-server-status")}}
+	data, err := msg.Marshal(nil)
+	if err != nil {
+		return 0, err
+	}
+	if err := p.conn.SetReadDeadline(deadline); err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	if _, err := p.conn.WriteTo(data, p.dst); err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, peer, err := p.conn.ReadFrom(buf)
+		if err != nil {
+			return 0, err
+		}
+		reply, err := icmp.ParseMessage(p.proto, buf[:n])
+		if err != nil || reply.Type != p.reply {
+			continue
+		}
+		echo, ok := reply.Body.(*icmp.Echo)
+		if !ok || echo.Seq != seq {
+			continue
+		}
+		if p.privileged && (echo.ID != p.id || !peerIP(peer).Equal(p.ip)) {
+			continue
+		}
+		return time.Since(start), nil
+	}
+}
+
+// peerIP 返回报文来源的 IP
+func peerIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.IPAddr:
+		return a.IP
+	case *net.UDPAddr:
+		return a.IP
+	}
+	return nil
+}
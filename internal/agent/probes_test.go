@@ -0,0 +1,199 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ruanun/simple-server-status/pkg/model"
+)
+
+// TestNewProbeResult 测试往返时间、抖动与丢包率的统计
+func TestNewProbeResult(t *testing.T) {
+	ms := func(v ...float64) []time.Duration {
+		d := make([]time.Duration, len(v))
+		for i, x := range v {
+			d[i] = time.Duration(x * float64(time.Millisecond))
+		}
+		return d
+	}
+	spec := probeSpec{name: "gw"}
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name  string
+		round probeRound
+		want  model.ProbeResult
+	}{
+		{
+			"全部收到",
+			probeRound{method: "icmp", target: "10.0.0.1", sent: 4, rtts: ms(10, 12, 11, 15)},
+			model.ProbeResult{Sent: 4, Received: 4, RTTMs: 12, MinMs: 10, MaxMs: 15, JitterMs: 2.33},
+		},
+		{
+			"部分丢包",
+			probeRound{method: "icmp", target: "10.0.0.1", sent: 3, rtts: ms(20)},
+			model.ProbeResult{Sent: 3, Received: 1, LossPercent: 66.67, RTTMs: 20, MinMs: 20, MaxMs: 20},
+		},
+		{
+			"全部丢包",
+			probeRound{method: "tcp", target: "10.0.0.1:22", sent: 5},
+			model.ProbeResult{Sent: 5, LossPercent: 100},
+		},
+		{
+			"无法执行",
+			probeRound{method: "icmp", target: "bad.invalid", err: errors.New("解析 bad.invalid 失败")},
+			model.ProbeResult{LossPercent: 100, Error: "解析 bad.invalid 失败"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newProbeResult(spec, tt.round, now)
+			tt.want.Name, tt.want.Target, tt.want.Method, tt.want.ProbedAt = "gw", tt.round.target, tt.round.method, now.Unix()
+			if *got != tt.want {
+				t.Errorf("newProbeResult() = %+v; want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+// TestProbeCollector_SetTasks 测试任务校验、默认值与结果保留
+func TestProbeCollector_SetTasks(t *testing.T) {
+	c := NewProbeCollector()
+	err := c.SetTasks([]*model.ProbeTask{
+		{Name: "gw", Type: "icmp", Host: "10.0.0.1"},
+		{Name: "ssh", Type: "tcp", Host: "10.0.0.2", Port: 22, Interval: 30, Count: 1000, TimeoutMs: 500},
+		{Name: "gw", Type: "tcp", Host: "10.0.0.3", Port: 80},
+		{Name: "web", Type: "tcp", Host: "10.0.0.4"},
+		{Name: "udp", Type: "udp", Host: "10.0.0.5", Port: 53},
+	})
+	for _, want := range []string{"名称重复", "端口无效", "不支持的探测类型"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("SetTasks() error = %v; want %s", err, want)
+		}
+	}
+	if len(c.probes) != 2 {
+		t.Fatalf("有效任务数 = %d; want 2", len(c.probes))
+	}
+	gw, ssh := c.probes[0].spec, c.probes[1].spec
+	if gw.interval != defaultProbeInterval || gw.count != defaultProbeCount || gw.timeout != defaultProbeTimeout {
+		t.Errorf("默认值 = %+v", gw)
+	}
+	if ssh.interval != 30*time.Second || ssh.count != maxProbeCount || ssh.timeout != 500*time.Millisecond {
+		t.Errorf("ssh = %+v", ssh)
+	}
+
+	// 目标不变的任务保留结果，目标变化的任务重新开始
+	c.probes[0].result = &model.ProbeResult{Name: "gw"}
+	c.probes[1].result = &model.ProbeResult{Name: "ssh"}
+	err = c.SetTasks([]*model.ProbeTask{
+		{Name: "gw", Type: "icmp", Host: "10.0.0.1", Count: 3},
+		{Name: "ssh", Type: "tcp", Host: "10.0.0.2", Port: 2222},
+	})
+	if err != nil {
+		t.Fatalf("SetTasks() error = %v", err)
+	}
+	if c.probes[0].result == nil || c.probes[0].spec.count != 3 || c.probes[1].result != nil {
+		t.Errorf("更新任务后 gw = %+v, ssh = %+v", c.probes[0], c.probes[1])
+	}
+}
+
+// TestProbeCollector 测试各任务按自己的周期在后台执行
+func TestProbeCollector(t *testing.T) {
+	var rounds atomic.Int32
+	now := time.Unix(1700000000, 0)
+	c := NewProbeCollector()
+	c.now = func() time.Time { return now }
+	c.roundFn = func(_ context.Context, spec probeSpec) probeRound {
+		rounds.Add(1)
+		return probeRound{method: spec.typ, target: spec.host, sent: spec.count, rtts: []time.Duration{time.Millisecond}}
+	}
+	collect := func() []*model.ProbeResult {
+		t.Helper()
+		return collectInto(t, c).Probes
+	}
+
+	// 未下发任务时不上报
+	if got := collect(); got != nil {
+		t.Errorf("无任务时 = %v", got)
+	}
+	if err := c.SetTasks([]*model.ProbeTask{{Name: "gw", Type: "icmp", Host: "10.0.0.1", Interval: 10, Count: 2}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 首次上报只启动探测，完成后的上报携带结果
+	if got := collect(); len(got) != 0 {
+		t.Errorf("探测完成前 = %v", got)
+	}
+	c.wg.Wait()
+	got := collect()
+	if len(got) != 1 || got[0].Name != "gw" || got[0].Sent != 2 || got[0].LossPercent != 50 || got[0].ProbedAt != now.Unix() {
+		t.Fatalf("探测完成后 = %+v", got)
+	}
+
+	// 周期内不重复探测，到期后再次探测
+	now = now.Add(5 * time.Second)
+	collect()
+	c.wg.Wait()
+	if rounds.Load() != 1 {
+		t.Errorf("周期内探测轮数 = %d; want 1", rounds.Load())
+	}
+	now = now.Add(5 * time.Second)
+	collect()
+	c.wg.Wait()
+	if got = collect(); rounds.Load() != 2 || got[0].ProbedAt != now.Unix() {
+		t.Errorf("到期后探测轮数 = %d, 结果 = %+v", rounds.Load(), got)
+	}
+
+	// 清空任务后不再上报
+	if err := c.SetTasks(nil); err != nil {
+		t.Fatal(err)
+	}
+	if got = collect(); got != nil {
+		t.Errorf("清空任务后 = %v", got)
+	}
+}
+
+// TestTCPRound 测试 TCP 连接探测
+func TestTCPRound(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+	spec := probeSpec{typ: model.ProbeTypeTCP, host: "127.0.0.1", port: port, count: 2, timeout: time.Second}
+
+	round := tcpRound(context.Background(), spec)
+	if round.err != nil || round.sent != 2 || len(round.rtts) != 2 || round.target != ln.Addr().String() {
+		t.Errorf("端口监听时 = %+v", round)
+	}
+	_ = ln.Close()
+	if round = tcpRound(context.Background(), spec); round.sent != 2 || len(round.rtts) != 0 {
+		t.Errorf("端口关闭后 = %+v", round)
+	}
+}
+
+// TestICMPRound 测试 ICMP 探测；没有发送 ICMP 的权限时跳过
+func TestICMPRound(t *testing.T) {
+	spec := probeSpec{typ: model.ProbeTypeICMP, host: "127.0.0.1", count: 2, timeout: time.Second}
+	round := icmpRound(context.Background(), spec)
+	if errors.Is(round.err, errICMPNotPermitted) {
+		t.Skip(round.err)
+	}
+	if round.err != nil || round.sent != 2 || len(round.rtts) != 2 {
+		t.Errorf("icmpRound() = %+v", round)
+	}
+}
@@ -0,0 +1,318 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ruanun/simple-server-status/internal/agent/config"
+	"github.com/ruanun/simple-server-status/pkg/model"
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// watchRescanInterval 按进程名监控时重新遍历全部进程的周期
+// 两次遍历之间只检查已匹配的进程；有实例退出或实例数不足时立即重新遍历
+const watchRescanInterval = 30 * time.Second
+
+// watchedProcStat 单个实例的一次采样
+type watchedProcStat struct {
+	createTime int64   // 启动时间，单位毫秒；与 pid 一起识别同一个进程
+	cpu        float64 // 累计 CPU 时间（user+system），单位秒
+	rss        uint64
+}
+
+// watchTarget 一个受监控进程的配置与状态
+type watchTarget struct {
+	name     string
+	process  string
+	pidfile  string
+	minCount int
+
+	instances   map[int32]watchedProcStat // 当前匹配的实例
+	mainPid     int32
+	restarts    uint64
+	restartedAt int64
+}
+
+// ProcessWatchCollector 受监控进程采集器
+// 每次上报检查 AgentConfig 中声明的进程是否运行、实例数、CPU/内存占用，并根据主进程 pid 的变化检测重启
+type ProcessWatchCollector struct {
+	baseCollector
+	namesFn func(ctx context.Context) (map[int32]string, error)
+	statFn  func(ctx context.Context, pid int32) (watchedProcStat, error)
+	now     func() time.Time
+
+	mu       sync.Mutex
+	targets  []*watchTarget
+	lastScan time.Time
+	lastTime time.Time
+}
+
+// NewProcessWatchCollector 创建受监控进程采集器；未配置受监控进程时不上报
+func NewProcessWatchCollector() *ProcessWatchCollector {
+	return &ProcessWatchCollector{
+		baseCollector: baseCollector{name: CollectorProcessWatch},
+		namesFn:       processNames,
+		statFn:        watchedStat,
+		now:           time.Now,
+	}
+}
+
+// newWatchTargets 根据配置生成受监控进程，配置无效时返回错误
+func newWatchTargets(watches []config.WatchProcess) ([]*watchTarget, error) {
+	targets := make([]*watchTarget, 0, len(watches))
+	names := make(map[string]bool, len(watches))
+	for i, w := range watches {
+		t := &watchTarget{
+			name:     w.Name,
+			process:  strings.TrimSpace(w.Process),
+			pidfile:  strings.TrimSpace(w.Pidfile),
+			minCount: w.MinCount,
+		}
+		if (t.process == "") == (t.pidfile == "") {
+			return nil, fmt.Errorf("第 %d 个受监控进程必须且只能配置 process 或 pidfile 之一", i+1)
+		}
+		if t.pidfile != "" && !filepath.IsAbs(t.pidfile) {
+			return nil, fmt.Errorf("pidfile 必须是绝对路径: %s", t.pidfile)
+		}
+		if t.minCount < 0 {
+			return nil, fmt.Errorf("受监控进程 %s 的最小实例数不能为负数", t.displayName())
+		}
+		if t.minCount == 0 {
+			t.minCount = 1
+		}
+		t.name = t.displayName()
+		if names[t.name] {
+			return nil, fmt.Errorf("受监控进程名称重复: %s", t.name)
+		}
+		names[t.name] = true
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// displayName 显示名称，未配置时使用进程名或 pidfile 的文件名
+func (t *watchTarget) displayName() string {
+	switch {
+	case t.name != "":
+		return t.name
+	case t.process != "":
+		return t.process
+	default:
+		return strings.TrimSuffix(filepath.Base(t.pidfile), ".pid")
+	}
+}
+
+// ApplyConfig 应用受监控进程配置；名称与匹配方式不变的进程保留重启计数
+func (c *ProcessWatchCollector) ApplyConfig(cfg *config.AgentConfig) error {
+	targets, err := newWatchTargets(cfg.WatchProcesses)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, t := range targets {
+		for _, old := range c.targets {
+			if old.name == t.name && old.process == t.process && old.pidfile == t.pidfile {
+				t.instances, t.mainPid, t.restarts, t.restartedAt = old.instances, old.mainPid, old.restarts, old.restartedAt
+			}
+		}
+	}
+	c.targets = targets
+	c.lastScan = time.Time{}
+	return nil
+}
+
+// Collect 检查各受监控进程
+func (c *ProcessWatchCollector) Collect(ctx context.Context) (PartialInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.targets) == 0 {
+		return func(s *model.ServerInfo) { s.WatchedProcesses = nil }, nil
+	}
+	now := c.now()
+	elapsed := now.Sub(c.lastTime).Seconds()
+	if c.lastTime.IsZero() {
+		elapsed = 0
+	}
+	c.lastTime = now
+
+	// 已匹配的实例退出、实例数不足或到达遍历周期时，重新按进程名遍历
+	current := make(map[*watchTarget]map[int32]watchedProcStat, len(c.targets))
+	rescan := now.Sub(c.lastScan) >= watchRescanInterval
+	for _, t := range c.targets {
+		if t.process == "" {
+			continue
+		}
+		alive := c.refresh(ctx, t.instances)
+		current[t] = alive
+		if len(alive) < len(t.instances) || len(alive) < t.minCount {
+			rescan = true
+		}
+	}
+	var scanErr error
+	if rescan {
+		if names, err := c.namesFn(ctx); err != nil {
+			scanErr = fmt.Errorf("获取进程列表失败: %w", err)
+		} else {
+			c.lastScan = now
+			for t := range current {
+				current[t] = c.match(ctx, t, names, current[t])
+			}
+		}
+	}
+
+	result := make([]*model.WatchedProcess, 0, len(c.targets))
+	for _, t := range c.targets {
+		wp := &model.WatchedProcess{Name: t.name}
+		instances := current[t]
+		var mainPid int32
+		if t.pidfile != "" {
+			instances, mainPid, wp.Error = c.checkPidfile(ctx, t.pidfile)
+		} else {
+			mainPid = oldestInstance(instances)
+		}
+
+		var cpu float64
+		for pid, st := range instances {
+			wp.RSS += st.rss
+			if last, ok := t.instances[pid]; ok && elapsed > 0 && last.createTime == st.createTime && st.cpu > last.cpu {
+				cpu += (st.cpu - last.cpu) / elapsed * 100
+			}
+		}
+		wp.Count = len(instances)
+		wp.Up = wp.Count >= t.minCount
+		wp.CPUPercent = math.Round(cpu*100) / 100
+		wp.Pid = mainPid
+
+		// 主进程 pid 变化视为重启；进程停止期间保留上一次的主进程，恢复后计为一次重启
+		if mainPid != 0 {
+			if t.mainPid != 0 && t.mainPid != mainPid {
+				t.restarts++
+				t.restartedAt = now.Unix()
+			}
+			t.mainPid = mainPid
+		}
+		wp.Restarts = t.restarts
+		wp.RestartedAt = t.restartedAt
+		t.instances = instances
+		result = append(result, wp)
+	}
+
+	return func(s *model.ServerInfo) { s.WatchedProcesses = result }, scanErr
+}
+
+// refresh 重新采样已匹配的实例，丢弃已退出或 pid 被复用的实例
+func (c *ProcessWatchCollector) refresh(ctx context.Context, instances map[int32]watchedProcStat) map[int32]watchedProcStat {
+	alive := make(map[int32]watchedProcStat, len(instances))
+	for pid, last := range instances {
+		st, err := c.statFn(ctx, pid)
+		if err != nil || st.createTime != last.createTime {
+			continue
+		}
+		alive[pid] = st
+	}
+	return alive
+}
+
+// match 按进程名匹配实例，已采样的实例不重复采样
+func (c *ProcessWatchCollector) match(ctx context.Context, t *watchTarget, names map[int32]string, alive map[int32]watchedProcStat) map[int32]watchedProcStat {
+	matched := make(map[int32]watchedProcStat)
+	for pid, name := range names {
+		if name != t.process {
+			continue
+		}
+		if st, ok := alive[pid]; ok {
+			matched[pid] = st
+			continue
+		}
+		if st, err := c.statFn(ctx, pid); err == nil {
+			matched[pid] = st
+		}
+	}
+	return matched
+}
+
+// checkPidfile 读取 pidfile 并采样其中记录的进程
+func (c *ProcessWatchCollector) checkPidfile(ctx context.Context, path string) (map[int32]watchedProcStat, int32, string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, 0, "pidfile 不存在"
+		}
+		return nil, 0, probeErrorMessage(err)
+	}
+	pid, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 32)
+	if err != nil || pid <= 0 {
+		return nil, 0, "pidfile 内容无效"
+	}
+	st, err := c.statFn(ctx, int32(pid))
+	if err != nil {
+		return nil, 0, fmt.Sprintf("进程 %d 未运行", pid)
+	}
+	return map[int32]watchedProcStat{int32(pid): st}, int32(pid), ""
+}
+
+// oldestInstance 返回最早启动的实例，如 nginx 的 master 进程；启动时间相同时取较小的 pid
+func oldestInstance(instances map[int32]watchedProcStat) int32 {
+	pids := make([]int32, 0, len(instances))
+	for pid := range instances {
+		pids = append(pids, pid)
+	}
+	slices.Sort(pids)
+	var oldest int32
+	for _, pid := range pids {
+		if oldest == 0 || instances[pid].createTime < instances[oldest].createTime {
+			oldest = pid
+		}
+	}
+	return oldest
+}
+
+// processNames 遍历全部进程的名称；遍历过程中退出的进程被跳过
+func processNames(ctx context.Context) (map[int32]string, error) {
+	pids, err := process.PidsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int32]string, len(pids))
+	for _, pid := range pids {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		p := &process.Process{Pid: pid}
+		if name, err := p.NameWithContext(ctx); err == nil {
+			names[pid] = name
+		}
+	}
+	return names, nil
+}
+
+// watchedStat 采样单个进程；进程不存在时返回错误
+func watchedStat(ctx context.Context, pid int32) (watchedProcStat, error) {
+	p, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return watchedProcStat{}, err
+	}
+	createTime, err := p.CreateTimeWithContext(ctx)
+	if err != nil {
+		return watchedProcStat{}, err
+	}
+	st := watchedProcStat{createTime: createTime}
+	if times, err := p.TimesWithContext(ctx); err == nil {
+		st.cpu = times.User + times.System
+	}
+	if m, err := p.MemoryInfoWithContext(ctx); err == nil {
+		st.rss = m.RSS
+	}
+	return st, nil
+}
@@ -0,0 +1,232 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ruanun/simple-server-status/internal/agent/config"
+	"github.com/ruanun/simple-server-status/pkg/model"
+)
+
+// fakeProcTable 模拟进程表：pid 到进程名与采样
+type fakeProcTable struct {
+	names map[int32]string
+	stats map[int32]watchedProcStat
+	scans int
+}
+
+// newFakeProcessWatchCollector 创建使用模拟进程表与固定时间的受监控进程采集器
+func newFakeProcessWatchCollector(t *testing.T, table *fakeProcTable, now *time.Time, watches ...config.WatchProcess) *ProcessWatchCollector {
+	t.Helper()
+	c := NewProcessWatchCollector()
+	c.namesFn = func(context.Context) (map[int32]string, error) {
+		table.scans++
+		return table.names, nil
+	}
+	c.statFn = func(_ context.Context, pid int32) (watchedProcStat, error) {
+		st, ok := table.stats[pid]
+		if !ok {
+			return st, errors.New("process does not exist")
+		}
+		return st, nil
+	}
+	c.now = func() time.Time { return *now }
+	if err := c.ApplyConfig(&config.AgentConfig{WatchProcesses: watches}); err != nil {
+		t.Fatalf("ApplyConfig() error = %v", err)
+	}
+	return c
+}
+
+// collectWatched 执行一次采集，返回按名称索引的受监控进程
+func collectWatched(t *testing.T, c *ProcessWatchCollector) map[string]*model.WatchedProcess {
+	t.Helper()
+	watched := collectInto(t, c).WatchedProcesses
+	result := make(map[string]*model.WatchedProcess, len(watched))
+	for _, p := range watched {
+		result[p.Name] = p
+	}
+	return result
+}
+
+// TestProcessWatchCollector 测试运行状态、实例数、CPU/内存与重启检测
+func TestProcessWatchCollector(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	table := &fakeProcTable{
+		names: map[int32]string{1: "systemd", 100: "nginx", 101: "nginx", 102: "nginx", 200: "app"},
+		stats: map[int32]watchedProcStat{
+			1:   {createTime: 1000, cpu: 5, rss: 10},
+			100: {createTime: 2000, cpu: 1, rss: 100},
+			101: {createTime: 2100, cpu: 10, rss: 200},
+			102: {createTime: 2100, cpu: 20, rss: 200},
+			200: {createTime: 3000, cpu: 3, rss: 50},
+		},
+	}
+	pidfile := filepath.Join(t.TempDir(), "app.pid")
+	if err := os.WriteFile(pidfile, []byte("200\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := newFakeProcessWatchCollector(t, table, &now,
+		config.WatchProcess{Process: "nginx", MinCount: 2},
+		config.WatchProcess{Process: "mysqld"},
+		config.WatchProcess{Pidfile: pidfile},
+	)
+
+	got := collectWatched(t, c)
+	nginx, mysqld, app := got["nginx"], got["mysqld"], got["app"]
+	if nginx == nil || mysqld == nil || app == nil {
+		t.Fatalf("结果 = %v", got)
+	}
+	if !nginx.Up || nginx.Count != 3 || nginx.Pid != 100 || nginx.RSS != 500 || nginx.CPUPercent != 0 {
+		t.Errorf("nginx = %+v", *nginx)
+	}
+	if mysqld.Up || mysqld.Count != 0 || mysqld.Pid != 0 {
+		t.Errorf("mysqld = %+v", *mysqld)
+	}
+	if !app.Up || app.Pid != 200 || app.Error != "" {
+		t.Errorf("app = %+v", *app)
+	}
+
+	// 10 秒后：两个 worker 各用了 2 秒 CPU；worker 102 被替换为 103（reload），master 未变不计重启
+	now = now.Add(10 * time.Second)
+	table.stats[101] = watchedProcStat{createTime: 2100, cpu: 12, rss: 200}
+	delete(table.stats, 102)
+	delete(table.names, 102)
+	table.names[103] = "nginx"
+	table.stats[103] = watchedProcStat{createTime: 9000, cpu: 0, rss: 300}
+	got = collectWatched(t, c)
+	if nginx = got["nginx"]; nginx.Count != 3 || nginx.CPUPercent != 20 || nginx.Restarts != 0 || nginx.RSS != 600 {
+		t.Errorf("reload 后 nginx = %+v", *nginx)
+	}
+
+	// nginx 全部退出：低于最小实例数，保留重启前的主进程
+	now = now.Add(10 * time.Second)
+	for _, pid := range []int32{100, 101, 103} {
+		delete(table.stats, pid)
+		delete(table.names, pid)
+	}
+	got = collectWatched(t, c)
+	if nginx = got["nginx"]; nginx.Up || nginx.Count != 0 || nginx.Restarts != 0 {
+		t.Errorf("退出后 nginx = %+v", *nginx)
+	}
+
+	// 以新的 pid 重新启动：计为一次重启；mysqld 启动后恢复运行
+	now = now.Add(10 * time.Second)
+	table.names[300], table.names[301], table.names[400] = "nginx", "nginx", "mysqld"
+	table.stats[300] = watchedProcStat{createTime: 20000}
+	table.stats[301] = watchedProcStat{createTime: 20001}
+	table.stats[400] = watchedProcStat{createTime: 20002, rss: 1000}
+	got = collectWatched(t, c)
+	if nginx = got["nginx"]; !nginx.Up || nginx.Pid != 300 || nginx.Restarts != 1 || nginx.RestartedAt != now.Unix() {
+		t.Errorf("重启后 nginx = %+v", *nginx)
+	}
+	if mysqld = got["mysqld"]; !mysqld.Up || mysqld.Restarts != 0 || mysqld.RSS != 1000 {
+		t.Errorf("启动后 mysqld = %+v", *mysqld)
+	}
+
+	// 重新加载相同配置时保留重启计数
+	if err := c.ApplyConfig(&config.AgentConfig{WatchProcesses: []config.WatchProcess{{Process: "nginx", MinCount: 2}}}); err != nil {
+		t.Fatalf("ApplyConfig() error = %v", err)
+	}
+	if got = collectWatched(t, c); got["nginx"].Restarts != 1 || len(got) != 1 {
+		t.Errorf("重新加载配置后 = %v", got)
+	}
+}
+
+// TestProcessWatchCollector_Rescan 测试两次遍历之间只检查已匹配的实例
+func TestProcessWatchCollector_Rescan(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	table := &fakeProcTable{
+		names: map[int32]string{10: "redis-server"},
+		stats: map[int32]watchedProcStat{10: {createTime: 1}},
+	}
+	c := newFakeProcessWatchCollector(t, table, &now, config.WatchProcess{Name: "redis", Process: "redis-server"})
+
+	steps := []struct {
+		name      string
+		advance   time.Duration
+		change    func()
+		wantScans int
+		wantCount int
+	}{
+		{"首次遍历", 0, nil, 1, 1},
+		{"周期内复用", 2 * time.Second, nil, 1, 1},
+		{"周期内不发现新实例", 2 * time.Second, func() {
+			table.names[11] = "redis-server"
+			table.stats[11] = watchedProcStat{createTime: 2}
+		}, 1, 1},
+		{"到达周期重新遍历", watchRescanInterval, nil, 2, 2},
+		{"pid 被复用时立即遍历", 2 * time.Second, func() {
+			table.names[10] = "bash"
+			table.stats[10] = watchedProcStat{createTime: 3}
+		}, 3, 1},
+	}
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		if step.change != nil {
+			step.change()
+		}
+		got := collectWatched(t, c)["redis"]
+		if table.scans != step.wantScans || got.Count != step.wantCount {
+			t.Errorf("%s: scans = %d, count = %d; want %d, %d", step.name, table.scans, got.Count, step.wantScans, step.wantCount)
+		}
+	}
+}
+
+// TestProcessWatchCollector_Pidfile 测试 pidfile 异常
+func TestProcessWatchCollector_Pidfile(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Unix(1700000000, 0)
+	table := &fakeProcTable{stats: map[int32]watchedProcStat{}}
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"不存在", "", "pidfile 不存在"},
+		{"内容无效", "abc", "pidfile 内容无效"},
+		{"进程未运行", "4242", "进程 4242 未运行"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name+".pid")
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			c := newFakeProcessWatchCollector(t, table, &now, config.WatchProcess{Name: "svc", Pidfile: path})
+			got := collectWatched(t, c)["svc"]
+			if got.Up || got.Error != tt.want {
+				t.Errorf("svc = %+v; want error %q", *got, tt.want)
+			}
+		})
+	}
+}
+
+// TestNewWatchTargets 测试受监控进程配置
+func TestNewWatchTargets(t *testing.T) {
+	targets, err := newWatchTargets([]config.WatchProcess{{Process: "nginx"}, {Pidfile: "/run/mysqld/mysqld.pid"}})
+	if err != nil {
+		t.Fatalf("newWatchTargets() error = %v", err)
+	}
+	if targets[0].name != "nginx" || targets[0].minCount != 1 || targets[1].name != "mysqld" {
+		t.Errorf("targets = %+v, %+v", *targets[0], *targets[1])
+	}
+
+	invalid := [][]config.WatchProcess{
+		{{}},
+		{{Process: "nginx", Pidfile: "/run/nginx.pid"}},
+		{{Pidfile: "run/nginx.pid"}},
+		{{Process: "nginx", MinCount: -1}},
+		{{Process: "nginx"}, {Pidfile: "/run/nginx.pid"}},
+	}
+	for _, watches := range invalid {
+		if _, err := newWatchTargets(watches); err == nil {
+			t.Errorf("newWatchTargets(%+v) 应返回错误", watches)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ruanun/simple-server-status/internal/agent/config"
+	"github.com/ruanun/simple-server-status/pkg/model"
+	"github.com/shirou/gopsutil/v4/mem"
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// 进程排行与命令行处理的默认值
+const (
+	defaultProcessTopN       = 5
+	maxProcessTopN           = 50
+	defaultCmdlineMaxLength  = 200
+	maxCmdlineMaxLength      = 4096
+	cmdlineRedactPlaceholder = "***"
+)
+
+// defaultCmdlineRedact 未配置时隐藏的命令行参数值，如 --password=xxx、-token xxx、SECRET=xxx
+var defaultCmdlineRedact = []string{
+	`(?i)(?:pass(?:word|wd)?|pwd|token|secret|api[_-]?key|access[_-]?key)(?:=|:|\s+)(\S+)`,
+}
+
+// processSample 单个进程的一次采样
+type processSample struct {
+	pid     int32
+	name    string
+	status  string
+	cpu     float64 // 累计 CPU 时间（user+system），单位秒
+	rss     uint64
+	threads int32
+}
+
+// processOptions 排行与命令行的处理规则
+type processOptions struct {
+	topN        int
+	hideCmdline bool
+	maxCmdline  int
+	redact      []*regexp.Regexp
+}
+
+// newProcessOptions 根据配置生成处理规则，配置无效时返回错误
+func newProcessOptions(cfg config.ProcessConfig) (processOptions, error) {
+	opts := processOptions{
+		topN:        cfg.TopN,
+		hideCmdline: cfg.HideCmdline,
+		maxCmdline:  cfg.CmdlineMaxLength,
+	}
+	if opts.topN == 0 {
+		opts.topN = defaultProcessTopN
+	}
+	if opts.topN < 0 || opts.topN > maxProcessTopN {
+		return opts, fmt.Errorf("排行进程数必须在 1-%d 之间", maxProcessTopN)
+	}
+	if opts.maxCmdline == 0 {
+		opts.maxCmdline = defaultCmdlineMaxLength
+	}
+	if opts.maxCmdline < 0 || opts.maxCmdline > maxCmdlineMaxLength {
+		return opts, fmt.Errorf("命令行最大长度必须在 1-%d 之间", maxCmdlineMaxLength)
+	}
+	patterns := cfg.Redact
+	if len(patterns) == 0 {
+		patterns = defaultCmdlineRedact
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return opts, fmt.Errorf("无效的命令行隐藏规则 %q: %w", pattern, err)
+		}
+		opts.redact = append(opts.redact, re)
+	}
+	return opts, nil
+}
+
+// formatCmdline 隐藏敏感参数并截断命令行
+func (o processOptions) formatCmdline(cmdline string) string {
+	if o.hideCmdline {
+		return ""
+	}
+	for _, re := range o.redact {
+		cmdline = redactMatches(re, cmdline)
+	}
+	if utf8.RuneCountInString(cmdline) > o.maxCmdline {
+		runes := []rune(cmdline)
+		cmdline = string(runes[:o.maxCmdline]) + "…"
+	}
+	return cmdline
+}
+
+// redactMatches 将匹配内容替换为占位符；规则含捕获组时只替换第一个捕获组
+func redactMatches(re *regexp.Regexp, s string) string {
+	matches := re.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+	var result []byte
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if len(m) >= 4 && m[2] >= 0 {
+			start, end = m[2], m[3]
+		}
+		result = append(result, s[last:start]...)
+		result = append(result, cmdlineRedactPlaceholder...)
+		last = end
+	}
+	return string(append(result, s[last:]...))
+}
+
+// ProcessCollector 进程采集器
+// 统计进程、线程数量，并按两次采集之间的 CPU 时间增量与常驻内存给出占用最高的进程；遍历全部进程开销较大，默认周期较长
+type ProcessCollector struct {
+	baseCollector
+	listFn     func(ctx context.Context) ([]processSample, error)
+	detailFn   func(ctx context.Context, pid int32) (user, cmdline string)
+	memTotalFn func(ctx context.Context) (uint64, error)
+	now        func() time.Time
+
+	mu       sync.Mutex
+	opts     processOptions
+	last     map[int32]float64 // pid 到累计 CPU 时间
+	lastTime time.Time
+}
+
+// NewProcessCollector 创建进程采集器
+func NewProcessCollector() *ProcessCollector {
+	opts, _ := newProcessOptions(config.ProcessConfig{})
+	return &ProcessCollector{
+		baseCollector: baseCollector{name: CollectorProcesses, interval: DefaultProcessInterval},
+		listFn:        listProcesses,
+		detailFn:      processDetail,
+		memTotalFn: func(ctx context.Context) (uint64, error) {
+			v, err := mem.VirtualMemoryWithContext(ctx)
+			if err != nil {
+				return 0, err
+			}
+			return v.Total, nil
+		},
+		now:  time.Now,
+		opts: opts,
+	}
+}
+
+// ApplyConfig 应用进程排行与命令行处理配置
+func (c *ProcessCollector) ApplyConfig(cfg *config.AgentConfig) error {
+	opts, err := newProcessOptions(cfg.Processes)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.opts = opts
+	c.mu.Unlock()
+	return nil
+}
+
+// Collect 采集进程统计；CPU 排行从第二次采集开始上报
+func (c *ProcessCollector) Collect(ctx context.Context) (PartialInfo, error) {
+	samples, err := c.listFn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取进程列表失败: %w", err)
+	}
+	memTotal, _ := c.memTotalFn(ctx)
+	now := c.now()
+
+	info := &model.ProcessInfo{Total: uint64(len(samples))}
+	cpuTimes := make(map[int32]float64, len(samples))
+	cpuPercent := make(map[int32]float64, len(samples))
+
+	c.mu.Lock()
+	opts := c.opts
+	elapsed := now.Sub(c.lastTime).Seconds()
+	for _, s := range samples {
+		switch s.status {
+		case process.Running:
+			info.Running++
+		case process.Zombie:
+			info.Zombie++
+		}
+		info.Threads += uint64(max(s.threads, 0))
+		cpuTimes[s.pid] = s.cpu
+		// 新进程或 pid 被复用（累计 CPU 时间减少）时本次不计算
+		if last, ok := c.last[s.pid]; ok && elapsed > 0 && s.cpu > last {
+			cpuPercent[s.pid] = (s.cpu - last) / elapsed * 100
+		}
+	}
+	c.last = cpuTimes
+	c.lastTime = now
+	c.mu.Unlock()
+
+	details := make(map[int32]*model.ProcessStat)
+	stat := func(s processSample) *model.ProcessStat {
+		if ps, ok := details[s.pid]; ok {
+			return ps
+		}
+		user, cmdline := c.detailFn(ctx, s.pid)
+		ps := &model.ProcessStat{
+			Pid:        s.pid,
+			Name:       s.name,
+			User:       user,
+			Cmdline:    opts.formatCmdline(cmdline),
+			CPUPercent: math.Round(cpuPercent[s.pid]*100) / 100,
+			RSS:        s.rss,
+		}
+		if memTotal > 0 {
+			ps.MemPercent = math.Round(float64(s.rss)/float64(memTotal)*10000) / 100
+		}
+		details[s.pid] = ps
+		return ps
+	}
+
+	byCPU := make([]processSample, 0, len(cpuPercent))
+	for _, s := range samples {
+		if cpuPercent[s.pid] > 0 {
+			byCPU = append(byCPU, s)
+		}
+	}
+	sort.SliceStable(byCPU, func(i, j int) bool { return cpuPercent[byCPU[i].pid] > cpuPercent[byCPU[j].pid] })
+	for _, s := range byCPU[:min(opts.topN, len(byCPU))] {
+		info.TopCPU = append(info.TopCPU, stat(s))
+	}
+
+	byRSS := make([]processSample, 0, len(samples))
+	for _, s := range samples {
+		if s.rss > 0 {
+			byRSS = append(byRSS, s)
+		}
+	}
+	sort.SliceStable(byRSS, func(i, j int) bool { return byRSS[i].rss > byRSS[j].rss })
+	for _, s := range byRSS[:min(opts.topN, len(byRSS))] {
+		info.TopRSS = append(info.TopRSS, stat(s))
+	}
+
+	return func(s *model.ServerInfo) { s.Processes = info }, nil
+}
+
+// listProcesses 遍历全部进程；遍历过程中退出或无权访问的进程被跳过
+func listProcesses(ctx context.Context) ([]processSample, error) {
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]processSample, 0, len(procs))
+	for _, p := range procs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		name, err := p.NameWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		s := processSample{pid: p.Pid, name: name}
+		if status, err := p.StatusWithContext(ctx); err == nil && len(status) > 0 {
+			s.status = status[0]
+		}
+		if threads, err := p.NumThreadsWithContext(ctx); err == nil {
+			s.threads = threads
+		}
+		if times, err := p.TimesWithContext(ctx); err == nil {
+			s.cpu = times.User + times.System
+		}
+		if m, err := p.MemoryInfoWithContext(ctx); err == nil {
+			s.rss = m.RSS
+		}
+		samples = append(samples, s)
+	}
+	return samples, nil
+}
+
+// processDetail 查询进程的用户与命令行，只对进入排行的进程调用；失败时返回空值
+func processDetail(ctx context.Context, pid int32) (user, cmdline string) {
+	p, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return "", ""
+	}
+	user, _ = p.UsernameWithContext(ctx)
+	cmdline, _ = p.CmdlineWithContext(ctx)
+	return user, cmdline
+}
@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ruanun/simple-server-status/internal/agent/config"
+	"github.com/ruanun/simple-server-status/pkg/model"
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// TestProcessOptions_FormatCmdline 测试命令行的敏感参数隐藏与截断
+func TestProcessOptions_FormatCmdline(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.ProcessConfig
+		cmdline string
+		want    string
+	}{
+		{"无敏感参数", config.ProcessConfig{}, "nginx: master process /usr/sbin/nginx", "nginx: master process /usr/sbin/nginx"},
+		{"等号形式", config.ProcessConfig{}, "app --password=hunter2 --port=80", "app --password=*** --port=80"},
+		{"空格分隔", config.ProcessConfig{}, "redis-cli -a x --token abc123 ping", "redis-cli -a x --token *** ping"},
+		{"环境变量形式", config.ProcessConfig{}, "env API_KEY=k1 SECRET=s1 run", "env API_KEY=*** SECRET=*** run"},
+		{"不误伤相似参数", config.ProcessConfig{}, "app --secret-file /etc/app.key", "app --secret-file /etc/app.key"},
+		{"自定义规则无捕获组", config.ProcessConfig{Redact: []string{`postgres://\S+`}}, "psql postgres://u:p@db/app", "psql ***"},
+		{"截断", config.ProcessConfig{CmdlineMaxLength: 8}, "java -jar 服务.jar", "java -ja…"},
+		{"多字节截断", config.ProcessConfig{CmdlineMaxLength: 11}, "java -jar 服务.jar", "java -jar 服…"},
+		{"不上报命令行", config.ProcessConfig{HideCmdline: true}, "app --port=80", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := newProcessOptions(tt.cfg)
+			if err != nil {
+				t.Fatalf("newProcessOptions() error = %v", err)
+			}
+			if got := opts.formatCmdline(tt.cmdline); got != tt.want {
+				t.Errorf("formatCmdline(%q) = %q; want %q", tt.cmdline, got, tt.want)
+			}
+		})
+	}
+
+	for _, cfg := range []config.ProcessConfig{{TopN: 51}, {CmdlineMaxLength: -1}, {Redact: []string{"("}}} {
+		if _, err := newProcessOptions(cfg); err == nil {
+			t.Errorf("newProcessOptions(%+v) 应返回错误", cfg)
+		}
+	}
+}
+
+// TestProcessCollector 测试进程数量统计与按 CPU 增量、常驻内存的排行
+func TestProcessCollector(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	var samples []processSample
+	c := NewProcessCollector()
+	c.listFn = func(context.Context) ([]processSample, error) { return samples, nil }
+	c.detailFn = func(_ context.Context, pid int32) (string, string) {
+		return "www", "worker --pid " + strings.Repeat("x", int(pid))
+	}
+	c.memTotalFn = func(context.Context) (uint64, error) { return 1000, nil }
+	c.now = func() time.Time { return now }
+	if err := c.ApplyConfig(&config.AgentConfig{Processes: config.ProcessConfig{TopN: 2}}); err != nil {
+		t.Fatalf("ApplyConfig() error = %v", err)
+	}
+
+	collect := func() *model.ProcessInfo {
+		t.Helper()
+		return collectInto(t, c).Processes
+	}
+
+	samples = []processSample{
+		{pid: 1, name: "init", status: process.Sleep, cpu: 10, rss: 100, threads: 1},
+		{pid: 2, name: "nginx", status: process.Running, cpu: 50, rss: 300, threads: 4},
+		{pid: 3, name: "mysqld", status: process.Sleep, cpu: 80, rss: 500, threads: 30},
+		{pid: 4, name: "defunct", status: process.Zombie},
+		{pid: 5, name: "cron", status: process.Sleep, cpu: 1, rss: 50, threads: 1},
+	}
+	info := collect()
+	if info.Total != 5 || info.Running != 1 || info.Zombie != 1 || info.Threads != 36 {
+		t.Errorf("Total/Running/Zombie/Threads = %d/%d/%d/%d; want 5/1/1/36", info.Total, info.Running, info.Zombie, info.Threads)
+	}
+	if len(info.TopCPU) != 0 {
+		t.Errorf("首次采集不应有 CPU 排行: %+v", info.TopCPU)
+	}
+	if len(info.TopRSS) != 2 || info.TopRSS[0].Pid != 3 || info.TopRSS[1].Pid != 2 {
+		t.Fatalf("TopRSS = %+v", info.TopRSS)
+	}
+	if info.TopRSS[0].MemPercent != 50 || info.TopRSS[0].User != "www" || info.TopRSS[0].Cmdline != "worker --pid xxx" {
+		t.Errorf("TopRSS[0] = %+v", *info.TopRSS[0])
+	}
+
+	// 10 秒后：nginx 用了 5 秒 CPU，cron 1 秒，mysqld 的 pid 被复用（CPU 时间减少），新进程 6 不计算
+	now = now.Add(10 * time.Second)
+	samples = []processSample{
+		{pid: 1, name: "init", status: process.Sleep, cpu: 10, rss: 100, threads: 1},
+		{pid: 2, name: "nginx", status: process.Running, cpu: 55, rss: 300, threads: 4},
+		{pid: 3, name: "mysqld", status: process.Sleep, cpu: 2, rss: 500, threads: 30},
+		{pid: 5, name: "cron", status: process.Sleep, cpu: 2, rss: 50, threads: 1},
+		{pid: 6, name: "stress", status: process.Running, cpu: 9, rss: 10, threads: 8},
+	}
+	info = collect()
+	if len(info.TopCPU) != 2 || info.TopCPU[0].Pid != 2 || info.TopCPU[1].Pid != 5 {
+		t.Fatalf("TopCPU = %+v", info.TopCPU)
+	}
+	if info.TopCPU[0].CPUPercent != 50 || info.TopCPU[1].CPUPercent != 10 {
+		t.Errorf("CPUPercent = %v, %v; want 50, 10", info.TopCPU[0].CPUPercent, info.TopCPU[1].CPUPercent)
+	}
+	// 同时出现在两个排行中的进程共用同一份明细
+	if info.TopRSS[1] != info.TopCPU[0] {
+		t.Error("同一进程应复用明细")
+	}
+}
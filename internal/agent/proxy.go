@@ -0,0 +1,205 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/net/http/httpproxy"
+	xproxy "golang.org/x/net/proxy"
+)
+
+// supportedProxySchemes 支持的代理协议
+var supportedProxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// ParseProxyURL 解析并校验代理地址
+// 支持 http://、https://、socks5:// 及可选的 user:password@ 认证信息
+func ParseProxyURL(proxyAddr string) (*url.URL, error) {
+	proxyURL, err := url.Parse(strings.TrimSpace(proxyAddr))
+	if err != nil {
+		return nil, fmt.Errorf("代理地址格式错误: %w", err)
+	}
+	scheme := strings.ToLower(proxyURL.Scheme)
+	supported := false
+	for _, s := range supportedProxySchemes {
+		if scheme == s {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return nil, fmt.Errorf("不支持的代理协议 %q，仅支持 http、https、socks5", proxyURL.Scheme)
+	}
+	if proxyURL.Hostname() == "" {
+		return nil, fmt.Errorf("代理地址缺少主机名")
+	}
+	proxyURL.Scheme = scheme
+	return proxyURL, nil
+}
+
+// NewProxyFunc 创建代理选择函数
+// proxyAddr 为空时使用 HTTP_PROXY/HTTPS_PROXY/NO_PROXY 环境变量；
+// 否则所有请求都走 proxyAddr，但仍然遵循 NO_PROXY 的排除规则
+func NewProxyFunc(proxyAddr string) (func(*http.Request) (*url.URL, error), error) {
+	proxyCfg := httpproxy.FromEnvironment()
+	if strings.TrimSpace(proxyAddr) != "" {
+		proxyURL, err := ParseProxyURL(proxyAddr)
+		if err != nil {
+			return nil, err
+		}
+		proxyCfg.HTTPProxy = proxyURL.String()
+		proxyCfg.HTTPSProxy = proxyURL.String()
+	}
+
+	proxyFunc := proxyCfg.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}, nil
+}
+
+// NewHTTPClient 创建遵循代理配置的 HTTP 客户端
+// Agent 发起的所有出站 HTTP 请求都应使用此函数创建客户端
+func NewHTTPClient(proxyFunc func(*http.Request) (*url.URL, error), timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxyFunc
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}
+}
+
+// NewWebSocketDialer 创建遵循代理配置的 WebSocket 拨号器
+// gorilla/websocket 自带的代理支持不包含 https 代理，这里统一由 proxyDialer 建立隧道
+func NewWebSocketDialer(proxyFunc func(*http.Request) (*url.URL, error), serverAddr string) *websocket.Dialer {
+	targetScheme := "http"
+	if strings.HasPrefix(strings.ToLower(serverAddr), "wss://") {
+		targetScheme = "https"
+	}
+
+	pd := &proxyDialer{
+		proxyFunc:    proxyFunc,
+		targetScheme: targetScheme,
+		forward: &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		},
+	}
+
+	return &websocket.Dialer{
+		NetDialContext:   pd.DialContext,
+		HandshakeTimeout: 45 * time.Second,
+	}
+}
+
+// proxyDialer 根据代理配置建立 TCP 连接
+type proxyDialer struct {
+	proxyFunc    func(*http.Request) (*url.URL, error)
+	targetScheme string
+	forward      *net.Dialer
+}
+
+// DialContext 建立到 addr 的连接，必要时经过代理
+func (d *proxyDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	var proxyURL *url.URL
+	if d.proxyFunc != nil {
+		var err error
+		proxyURL, err = d.proxyFunc(&http.Request{URL: &url.URL{Scheme: d.targetScheme, Host: addr}})
+		if err != nil {
+			return nil, fmt.Errorf("选择代理失败: %w", err)
+		}
+	}
+
+	if proxyURL == nil {
+		return d.forward.DialContext(ctx, network, addr)
+	}
+
+	switch strings.ToLower(proxyURL.Scheme) {
+	case "socks5", "socks5h":
+		dialer, err := xproxy.FromURL(proxyURL, d.forward)
+		if err != nil {
+			return nil, fmt.Errorf("创建 SOCKS5 代理拨号器失败: %w", err)
+		}
+		if cd, ok := dialer.(xproxy.ContextDialer); ok {
+			return cd.DialContext(ctx, network, addr)
+		}
+		return dialer.Dial(network, addr)
+	case "http", "https":
+		return d.dialConnect(ctx, proxyURL, addr)
+	default:
+		return nil, fmt.Errorf("不支持的代理协议 %q", proxyURL.Scheme)
+	}
+}
+
+// dialConnect 通过 HTTP CONNECT 建立隧道
+func (d *proxyDialer) dialConnect(ctx context.Context, proxyURL *url.URL, addr string) (net.Conn, error) {
+	proxyAddr := proxyURL.Host
+	if proxyURL.Port() == "" {
+		if proxyURL.Scheme == "https" {
+			proxyAddr = net.JoinHostPort(proxyURL.Hostname(), "443")
+		} else {
+			proxyAddr = net.JoinHostPort(proxyURL.Hostname(), "80")
+		}
+	}
+
+	conn, err := d.forward.DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, fmt.Errorf("连接代理服务器失败: %w", err)
+	}
+
+	if proxyURL.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{
+			ServerName: proxyURL.Hostname(),
+			MinVersion: tls.VersionTLS12,
+		})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close() // 忽略关闭错误，握手已失败
+			return nil, fmt.Errorf("代理服务器 TLS 握手失败: %w", err)
+		}
+		conn = tlsConn
+	}
+
+	// CONNECT 交互期间遵循上下文的截止时间
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer func() { _ = conn.SetDeadline(time.Time{}) }()
+	}
+
+	connectReq := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		credential := base64.StdEncoding.EncodeToString([]byte(proxyURL.User.Username() + ":" + password))
+		connectReq.Header.Set("Proxy-Authorization", "Basic "+credential)
+	}
+
+	if err := connectReq.Write(conn); err != nil {
+		_ = conn.Close() // 忽略关闭错误，隧道建立失败
+		return nil, fmt.Errorf("发送 CONNECT 请求失败: %w", err)
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), connectReq)
+	if err != nil {
+		_ = conn.Close() // 忽略关闭错误，隧道建立失败
+		return nil, fmt.Errorf("读取 CONNECT 响应失败: %w", err)
+	}
+	_ = resp.Body.Close() // CONNECT 响应没有有效的响应体
+
+	if resp.StatusCode != http.StatusOK {
+		_ = conn.Close() // 忽略关闭错误，隧道建立失败
+		return nil, fmt.Errorf("代理服务器拒绝 CONNECT 请求: %s", resp.Status)
+	}
+
+	return conn, nil
+}
@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/ruanun/simple-server-status/internal/agent/config"
+	"github.com/ruanun/simple-server-status/pkg/model"
+	"go.uber.org/zap"
+)
+
+const (
+	// TransportPull 反向拉取模式：Agent 不主动连接面板，由面板定时拉取
+	TransportPull = "pull"
+
+	// PullPath Agent 提供服务器信息快照的 HTTP 路径
+	PullPath = "/server-info"
+)
+
+// PullServer 为反向拉取模式提供服务器信息快照的 HTTP 服务
+// 适用于只允许入站连接的 DMZ 环境，面板使用与推送相同的 X-SERVER-ID/X-AUTH-SECRET 头进行认证
+type PullServer struct {
+	serverId   string
+	authSecret string
+	snapshot   func() *model.ServerInfo
+	httpServer *http.Server
+	listener   net.Listener
+	logger     *zap.SugaredLogger
+}
+
+// NewPullServer 创建拉取服务
+// snapshot 返回最近一次采集的服务器信息
+func NewPullServer(cfg *config.AgentConfig, snapshot func() *model.ServerInfo, logger *zap.SugaredLogger) *PullServer {
+	ps := &PullServer{
+		serverId:   cfg.ServerId,
+		authSecret: cfg.AuthSecret,
+		snapshot:   snapshot,
+		logger:     logger,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(PullPath, ps.handleServerInfo)
+
+	ps.httpServer = &http.Server{
+		Addr:              cfg.PullListen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second, // 防止 Slowloris 攻击
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      10 * time.Second,
+		IdleTimeout:       60 * time.Second,
+	}
+	return ps
+}
+
+// Start 开始监听
+func (ps *PullServer) Start() error {
+	listener, err := net.Listen("tcp", ps.httpServer.Addr)
+	if err != nil {
+		return err
+	}
+	ps.listener = listener
+
+	go func() {
+		if err := ps.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			ps.logger.Errorf("拉取服务异常退出: %v", err)
+		}
+	}()
+	ps.logger.Infof("拉取服务已启动，监听地址: %s%s", listener.Addr().String(), PullPath)
+	return nil
+}
+
+// Addr 返回实际监听地址
+func (ps *PullServer) Addr() string {
+	if ps.listener == nil {
+		return ps.httpServer.Addr
+	}
+	return ps.listener.Addr().String()
+}
+
+// handleServerInfo 返回服务器信息快照
+func (ps *PullServer) handleServerInfo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !ps.authenticate(r) {
+		ps.logger.Warnf("拉取请求认证失败 - RemoteAddr: %s", r.RemoteAddr)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	info := ps.snapshot()
+	if info == nil {
+		// 尚未完成首次采集
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(info); err != nil {
+		ps.logger.Warnf("写入拉取响应失败: %v", err)
+	}
+}
+
+// authenticate 使用常量时间比较校验认证头
+func (ps *PullServer) authenticate(r *http.Request) bool {
+	secret := r.Header.Get("X-AUTH-SECRET")
+	serverId := r.Header.Get("X-SERVER-ID")
+	if secret == "" || serverId == "" {
+		return false
+	}
+	idOK := subtle.ConstantTimeCompare([]byte(serverId), []byte(ps.serverId)) == 1
+	secretOK := subtle.ConstantTimeCompare([]byte(secret), []byte(ps.authSecret)) == 1
+	return idOK && secretOK
+}
+
+// Close 关闭拉取服务
+func (ps *PullServer) Close(ctx context.Context) error {
+	return ps.httpServer.Shutdown(ctx)
+}
@@ -0,0 +1,88 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ruanun/simple-server-status/internal/agent/config"
+	"github.com/ruanun/simple-server-status/pkg/model"
+	"go.uber.org/zap"
+)
+
+// TestPullServer 测试拉取服务的认证与快照返回
+func TestPullServer(t *testing.T) {
+	cfg := &config.AgentConfig{
+		ServerId:   "dmz-01",
+		AuthSecret: "secret-123456",
+		PullListen: "127.0.0.1:0",
+	}
+
+	var snapshot atomic.Pointer[model.ServerInfo]
+	ps := NewPullServer(cfg, snapshot.Load, zap.NewNop().Sugar())
+	if err := ps.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer func() { _ = ps.Close(context.Background()) }()
+
+	url := "http://" + ps.Addr() + PullPath
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	get := func(serverId, secret string) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		if serverId != "" {
+			req.Header.Set("X-SERVER-ID", serverId)
+		}
+		if secret != "" {
+			req.Header.Set("X-AUTH-SECRET", secret)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("请求失败: %v", err)
+		}
+		return resp
+	}
+
+	t.Run("缺少认证头", func(t *testing.T) {
+		resp := get("", "")
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("状态码 = %d; want 401", resp.StatusCode)
+		}
+	})
+
+	t.Run("密钥错误", func(t *testing.T) {
+		resp := get("dmz-01", "wrong-secret")
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("状态码 = %d; want 401", resp.StatusCode)
+		}
+	})
+
+	t.Run("尚未采集", func(t *testing.T) {
+		resp := get("dmz-01", "secret-123456")
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("状态码 = %d; want 503", resp.StatusCode)
+		}
+	})
+
+	t.Run("返回快照", func(t *testing.T) {
+		snapshot.Store(&model.ServerInfo{CpuInfo: &model.CpuInfo{Percent: 42}, Ip: "10.0.0.1"})
+		resp := get("dmz-01", "secret-123456")
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("状态码 = %d; want 200", resp.StatusCode)
+		}
+		var info model.ServerInfo
+		if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+			t.Fatalf("解析响应失败: %v", err)
+		}
+		if info.CpuInfo == nil || info.CpuInfo.Percent != 42 || info.Ip != "10.0.0.1" {
+			t.Errorf("快照内容不正确: %+v", info)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ruanun/simple-server-status/pkg/model"
+)
+
+// SampleWindow 累积一个上报周期内的高频采样，上报时计算统计
+// 采样间隔小于上报间隔时，短时间的 CPU 峰值等会体现在 max/p95 中，而上报频率不变
+type SampleWindow struct {
+	mu     sync.Mutex
+	start  time.Time
+	values map[string][]float64
+	count  int
+}
+
+// NewSampleWindow 创建采样窗口
+func NewSampleWindow() *SampleWindow {
+	return &SampleWindow{values: make(map[string][]float64)}
+}
+
+// Add 加入一次采样
+func (w *SampleWindow) Add(info *model.ServerInfo, now time.Time) {
+	if info == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.count == 0 {
+		w.start = now
+	}
+	w.count++
+
+	if info.CpuInfo != nil {
+		w.add(model.MetricCPU, info.CpuInfo.Percent)
+		if t := info.CpuInfo.Times; t != nil {
+			w.add(model.MetricIowait, t.Iowait)
+			w.add(model.MetricSteal, t.Steal)
+		}
+	}
+	if info.VirtualMemoryInfo != nil {
+		w.add(model.MetricRAM, info.VirtualMemoryInfo.UsedPercent)
+	}
+	if info.SwapMemoryInfo != nil {
+		w.add(model.MetricSwap, info.SwapMemoryInfo.UsedPercent)
+	}
+	if info.DiskInfo != nil {
+		w.add(model.MetricDisk, info.DiskInfo.UsedPercent)
+	}
+	if info.HostInfo != nil && info.HostInfo.AvgStat != nil {
+		w.add(model.MetricLoad1, info.HostInfo.AvgStat.Load1)
+	}
+	if info.NetworkInfo != nil {
+		w.add(model.MetricNetIn, float64(info.NetworkInfo.NetInSpeed))
+		w.add(model.MetricNetOut, float64(info.NetworkInfo.NetOutSpeed))
+	}
+}
+
+func (w *SampleWindow) add(name string, v float64) {
+	w.values[name] = append(w.values[name], v)
+}
+
+// Flush 计算当前窗口的统计并开始新窗口；窗口内没有采样时返回 nil
+func (w *SampleWindow) Flush(now time.Time) *model.SampleAggregates {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.count == 0 {
+		return nil
+	}
+
+	agg := &model.SampleAggregates{
+		Samples:  w.count,
+		WindowMs: now.Sub(w.start).Milliseconds(),
+		Metrics:  make(map[string]model.MetricAggregate, len(w.values)),
+	}
+	for name, values := range w.values {
+		if len(values) == 0 {
+			continue
+		}
+		agg.Metrics[name] = aggregate(values)
+		// 复用底层数组，减少高频采样的内存分配
+		w.values[name] = values[:0]
+	}
+	w.count = 0
+	return agg
+}
+
+// aggregate 计算 min/avg/max/p95，p95 使用最近秩法（nearest-rank）
+func aggregate(values []float64) model.MetricAggregate {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	rank := int(math.Ceil(0.95*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return model.MetricAggregate{
+		Min: sorted[0],
+		Avg: sum / float64(len(sorted)),
+		Max: sorted[len(sorted)-1],
+		P95: sorted[rank],
+	}
+}
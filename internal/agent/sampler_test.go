@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ruanun/simple-server-status/pkg/model"
+)
+
+// TestSampleWindow 测试采样窗口的统计与重置
+func TestSampleWindow(t *testing.T) {
+	w := NewSampleWindow()
+	start := time.Unix(1700000000, 0)
+
+	if agg := w.Flush(start); agg != nil {
+		t.Fatalf("空窗口应返回 nil: %+v", agg)
+	}
+
+	// 20 次采样，CPU 为 1..20，其中一次短时峰值 95
+	for i := 1; i <= 20; i++ {
+		percent := float64(i)
+		if i == 10 {
+			percent = 95
+		}
+		info := newFrameTestInfo("debian", percent)
+		info.NetworkInfo.NetOutSpeed = uint64(i * 100)
+		w.Add(info, start.Add(time.Duration(i-1)*250*time.Millisecond))
+	}
+
+	agg := w.Flush(start.Add(5 * time.Second))
+	if agg.Samples != 20 || agg.WindowMs != 5000 {
+		t.Fatalf("Samples = %d, WindowMs = %d", agg.Samples, agg.WindowMs)
+	}
+	cpu := agg.Metrics[model.MetricCPU]
+	if cpu.Min != 1 || cpu.Max != 95 || cpu.P95 != 20 {
+		t.Errorf("cpu = %+v", cpu)
+	}
+	if want := (210.0 - 10 + 95) / 20; cpu.Avg != want {
+		t.Errorf("cpu.Avg = %v; want %v", cpu.Avg, want)
+	}
+	if out := agg.Metrics[model.MetricNetOut]; out.Min != 100 || out.Max != 2000 {
+		t.Errorf("netOut = %+v", out)
+	}
+	if load := agg.Metrics[model.MetricLoad1]; load.Min != 0.5 || load.Max != 0.5 {
+		t.Errorf("load1 = %+v", load)
+	}
+
+	// Flush 后开始新窗口
+	w.Add(newFrameTestInfo("debian", 42), start.Add(6*time.Second))
+	agg = w.Flush(start.Add(7 * time.Second))
+	if agg.Samples != 1 || agg.WindowMs != 1000 {
+		t.Fatalf("新窗口 Samples = %d, WindowMs = %d", agg.Samples, agg.WindowMs)
+	}
+	if cpu := agg.Metrics[model.MetricCPU]; cpu.Min != 42 || cpu.Avg != 42 || cpu.Max != 42 || cpu.P95 != 42 {
+		t.Errorf("单次采样 cpu = %+v", cpu)
+	}
+
+	// 本窗口缺少的指标不应出现在统计中
+	w.Add(&model.ServerInfo{CpuInfo: &model.CpuInfo{Percent: 1}}, start.Add(8*time.Second))
+	agg = w.Flush(start.Add(9 * time.Second))
+	if _, ok := agg.Metrics[model.MetricRAM]; ok || len(agg.Metrics) != 1 {
+		t.Errorf("Metrics = %+v", agg.Metrics)
+	}
+}
@@ -0,0 +1,221 @@
+package internal
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ruanun/simple-server-status/internal/agent/config"
+	"github.com/ruanun/simple-server-status/pkg/model"
+	"github.com/shirou/gopsutil/v4/common"
+	"github.com/shirou/gopsutil/v4/sensors"
+)
+
+// defaultSysRoot 默认的 sysfs 挂载点
+const defaultSysRoot = "/sys"
+
+// SensorCollector 硬件传感器采集器，仅 Linux
+// 温度由 gopsutil 读取 class/hwmon（没有 hwmon 温度时读取 class/thermal），风扇转速与功耗直接读取 class/hwmon
+type SensorCollector struct {
+	baseCollector
+	temperaturesFn func(ctx context.Context) ([]sensors.TemperatureStat, error)
+
+	mu      sync.Mutex
+	sysRoot string
+}
+
+// NewSensorCollector 创建硬件传感器采集器
+func NewSensorCollector() *SensorCollector {
+	return &SensorCollector{
+		baseCollector:  baseCollector{name: CollectorSensors, interval: DefaultSensorInterval},
+		sysRoot:        cmp.Or(os.Getenv("HOST_SYS"), defaultSysRoot),
+		temperaturesFn: sensors.TemperaturesWithContext,
+	}
+}
+
+// Enabled 只有 Linux 提供 sysfs
+func (c *SensorCollector) Enabled() bool { return runtime.GOOS == "linux" }
+
+// ApplyConfig 应用 AgentConfig.Sensors 中的 sysfs 挂载点
+func (c *SensorCollector) ApplyConfig(cfg *config.AgentConfig) error {
+	c.mu.Lock()
+	c.sysRoot = cmp.Or(cfg.Sensors.SysfsRoot, os.Getenv("HOST_SYS"), defaultSysRoot)
+	c.mu.Unlock()
+	return nil
+}
+
+// Collect 采集温度、风扇转速与功耗；没有任何传感器时（如虚拟机）不上报
+func (c *SensorCollector) Collect(ctx context.Context) (PartialInfo, error) {
+	c.mu.Lock()
+	sysRoot := c.sysRoot
+	c.mu.Unlock()
+
+	ctx = context.WithValue(ctx, common.EnvKey, common.EnvMap{common.HostSysEnvKey: sysRoot})
+	temps, err := c.temperaturesFn(ctx)
+	// 个别传感器读取失败时 gopsutil 返回 Warnings，其余读数仍然有效
+	var warns *sensors.Warnings
+	if err != nil && !errors.As(err, &warns) {
+		return nil, fmt.Errorf("读取温度传感器失败: %w", err)
+	}
+
+	info := &model.SensorInfo{
+		Temperatures: convertTemperatures(sysRoot, temps),
+		Fans:         readFans(sysRoot),
+		Power:        readPower(sysRoot),
+	}
+	if len(info.Temperatures) == 0 && len(info.Fans) == 0 && len(info.Power) == 0 {
+		info = nil
+	}
+	return func(s *model.ServerInfo) { s.Sensors = info }, nil
+}
+
+// convertTemperatures 转换 gopsutil 的读数；同名传感器（如多块 NVMe 硬盘）依次加上序号
+// thermal_zone 没有阈值，使用其类型为 critical 的触发点作为临界温度
+func convertTemperatures(sysRoot string, temps []sensors.TemperatureStat) []*model.TemperatureSensor {
+	var trips map[string]float64
+	names := make(map[string]int, len(temps))
+	result := make([]*model.TemperatureSensor, 0, len(temps))
+	for _, t := range temps {
+		sensor := &model.TemperatureSensor{
+			Name:     uniqueSensorName(names, t.SensorKey),
+			Celsius:  math.Round(t.Temperature*100) / 100,
+			High:     math.Round(t.High*100) / 100,
+			Critical: math.Round(t.Critical*100) / 100,
+		}
+		if sensor.High == 0 && sensor.Critical == 0 {
+			if trips == nil {
+				trips = readThermalCriticals(sysRoot)
+			}
+			sensor.Critical = trips[t.SensorKey]
+		}
+		result = append(result, sensor)
+	}
+	return result
+}
+
+// readThermalCriticals 读取各 thermal_zone 类型为 critical 的触发点，返回 zone 类型到温度（摄氏度）的映射
+func readThermalCriticals(sysRoot string) map[string]float64 {
+	result := make(map[string]float64)
+	zones, _ := filepath.Glob(filepath.Join(sysRoot, "class", "thermal", "thermal_zone*"))
+	for _, zone := range zones {
+		name, err := readSysString(filepath.Join(zone, "type"))
+		if err != nil {
+			continue
+		}
+		types, _ := filepath.Glob(filepath.Join(zone, "trip_point_*_type"))
+		for _, typeFile := range types {
+			if kind, _ := readSysString(typeFile); kind != "critical" {
+				continue
+			}
+			if v, err := readUintFile(strings.TrimSuffix(typeFile, "_type") + "_temp"); err == nil {
+				result[name] = math.Round(float64(v)/10) / 100
+				break
+			}
+		}
+	}
+	return result
+}
+
+// readFans 读取 hwmon 中的风扇转速（fan*_input）
+// 未接风扇的接口读数为 0，只有设置了最低转速或处于告警状态时才上报
+func readFans(sysRoot string) []*model.FanSensor {
+	var fans []*model.FanSensor
+	names := make(map[string]int)
+	for _, input := range hwmonInputs(sysRoot, "fan*_input") {
+		rpm, err := readUintFile(input)
+		if err != nil {
+			continue
+		}
+		base := strings.TrimSuffix(input, "_input")
+		minRPM, _ := readUintFile(base + "_min")
+		alarm, _ := readUintFile(base + "_alarm")
+		if rpm == 0 && minRPM == 0 && alarm == 0 {
+			continue
+		}
+		fans = append(fans, &model.FanSensor{
+			Name: uniqueSensorName(names, hwmonSensorName(base)),
+			RPM:  rpm,
+			Min:  minRPM,
+		})
+	}
+	return fans
+}
+
+// readPower 读取 hwmon 中的功耗（power*_input，没有时为 power*_average），单位微瓦
+func readPower(sysRoot string) []*model.PowerSensor {
+	inputs := hwmonInputs(sysRoot, "power*_input")
+	for _, average := range hwmonInputs(sysRoot, "power*_average") {
+		if !slices.Contains(inputs, strings.TrimSuffix(average, "_average")+"_input") {
+			inputs = append(inputs, average)
+		}
+	}
+
+	var power []*model.PowerSensor
+	names := make(map[string]int)
+	for _, input := range inputs {
+		uw, err := readUintFile(input)
+		if err != nil {
+			continue
+		}
+		base := input[:strings.LastIndexByte(input, '_')]
+		powerCap, _ := readUintFile(base + "_cap")
+		power = append(power, &model.PowerSensor{
+			Name:  uniqueSensorName(names, hwmonSensorName(base)),
+			Watts: math.Round(float64(uw)/1e4) / 100,
+			Cap:   math.Round(float64(powerCap)/1e4) / 100,
+		})
+	}
+	return power
+}
+
+// hwmonInputs 返回各 hwmon 设备下匹配 pattern 的文件，兼容部分发行版在 device 子目录下提供的情况
+func hwmonInputs(sysRoot, pattern string) []string {
+	files, _ := filepath.Glob(filepath.Join(sysRoot, "class", "hwmon", "hwmon*", pattern))
+	if len(files) == 0 {
+		files, _ = filepath.Glob(filepath.Join(sysRoot, "class", "hwmon", "hwmon*", "device", pattern))
+	}
+	slices.Sort(files)
+	return files
+}
+
+// hwmonSensorName 由 hwmon 设备的驱动名与传感器标签生成名称，与 gopsutil 的温度传感器命名一致
+// base 为去掉后缀的传感器路径，如 /sys/class/hwmon/hwmon2/fan1
+func hwmonSensorName(base string) string {
+	dir := filepath.Dir(base)
+	name, err := readSysString(filepath.Join(dir, "name"))
+	if err != nil {
+		name = filepath.Base(dir)
+	}
+	label, _ := readSysString(base + "_label")
+	if label == "" {
+		label = filepath.Base(base)
+	}
+	return name + "_" + strings.Join(strings.Fields(strings.ToLower(label)), "_")
+}
+
+// uniqueSensorName 同名传感器从第二个起加上序号，如 nvme_composite、nvme_composite_2
+func uniqueSensorName(seen map[string]int, name string) string {
+	seen[name]++
+	if n := seen[name]; n > 1 {
+		return name + "_" + strconv.Itoa(n)
+	}
+	return name
+}
+
+// readSysString 读取 sysfs 文件内容并去掉首尾空白
+func readSysString(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ruanun/simple-server-status/internal/agent/config"
+	"github.com/ruanun/simple-server-status/pkg/model"
+	"github.com/shirou/gopsutil/v4/sensors"
+)
+
+// newFakeSensorCollector 创建读取 testdata 下模拟 sysfs 目录的硬件传感器采集器
+func newFakeSensorCollector(t *testing.T, dir string) *SensorCollector {
+	t.Helper()
+	root, err := filepath.Abs(filepath.Join("testdata", dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewSensorCollector()
+	if err := c.ApplyConfig(&config.AgentConfig{Sensors: config.SensorConfig{SysfsRoot: root}}); err != nil {
+		t.Fatalf("ApplyConfig() error = %v", err)
+	}
+	return c
+}
+
+// sensorJSON 以 JSON 格式输出读数，便于比较失败时查看
+func sensorJSON(v any) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// TestSensorCollector_Hwmon 测试从 hwmon 读取温度、风扇与功耗
+func TestSensorCollector_Hwmon(t *testing.T) {
+	got := collectInto(t, newFakeSensorCollector(t, "sys")).Sensors
+	if got == nil {
+		t.Fatal("Sensors = nil")
+	}
+
+	wantTemps := []*model.TemperatureSensor{
+		{Name: "coretemp_package_id_0", Celsius: 45, High: 80, Critical: 100},
+		{Name: "coretemp_core_0", Celsius: 101, High: 80, Critical: 100},
+		{Name: "nvme_composite", Celsius: 38.85, High: 81.85, Critical: 84.85},
+		{Name: "nvme_composite_2", Celsius: 41.85},
+	}
+	if !reflect.DeepEqual(got.Temperatures, wantTemps) {
+		t.Errorf("Temperatures = %s; want %s", sensorJSON(got.Temperatures), sensorJSON(wantTemps))
+	}
+
+	// fan2 未接风扇（读数为 0 且没有最低转速）不上报，fan3 设置了最低转速但已停转
+	wantFans := []*model.FanSensor{
+		{Name: "nct6775_cpu_fan", RPM: 1200, Min: 300},
+		{Name: "nct6775_fan3", RPM: 0, Min: 500},
+	}
+	if !reflect.DeepEqual(got.Fans, wantFans) {
+		t.Errorf("Fans = %s; want %s", sensorJSON(got.Fans), sensorJSON(wantFans))
+	}
+
+	wantPower := []*model.PowerSensor{{Name: "amdgpu_ppt", Watts: 35.25, Cap: 150}}
+	if !reflect.DeepEqual(got.Power, wantPower) {
+		t.Errorf("Power = %s; want %s", sensorJSON(got.Power), sensorJSON(wantPower))
+	}
+
+	resp := model.NewRespServerInfo(&model.ServerInfo{
+		HostInfo: &model.HostInfo{}, CpuInfo: &model.CpuInfo{}, VirtualMemoryInfo: &model.VirtualMemoryInfo{},
+		SwapMemoryInfo: &model.SwapMemoryInfo{}, DiskInfo: &model.DiskInfo{}, NetworkInfo: &model.NetworkInfo{},
+		Sensors: got,
+	})
+	if !reflect.DeepEqual(resp.Overheated, []string{"coretemp_core_0"}) {
+		t.Errorf("Overheated = %v; want [coretemp_core_0]", resp.Overheated)
+	}
+}
+
+// TestSensorCollector_Thermal 测试没有 hwmon 温度时读取 thermal_zone 及其临界触发点
+func TestSensorCollector_Thermal(t *testing.T) {
+	got := collectInto(t, newFakeSensorCollector(t, "sys-thermal")).Sensors
+	if got == nil {
+		t.Fatal("Sensors = nil")
+	}
+	want := []*model.TemperatureSensor{{Name: "cpu-thermal", Celsius: 52.12, Critical: 105}}
+	if !reflect.DeepEqual(got.Temperatures, want) {
+		t.Errorf("Temperatures = %s; want %s", sensorJSON(got.Temperatures), sensorJSON(want))
+	}
+	if len(got.Fans) != 0 || len(got.Power) != 0 {
+		t.Errorf("Fans = %v, Power = %v; want 空", got.Fans, got.Power)
+	}
+}
+
+// TestSensorCollector_NoSensors 测试没有传感器（如虚拟机）时不上报，读取失败时返回错误
+func TestSensorCollector_NoSensors(t *testing.T) {
+	c := NewSensorCollector()
+	if err := c.ApplyConfig(&config.AgentConfig{Sensors: config.SensorConfig{SysfsRoot: t.TempDir()}}); err != nil {
+		t.Fatalf("ApplyConfig() error = %v", err)
+	}
+	info := &model.ServerInfo{Sensors: &model.SensorInfo{}}
+	apply, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	apply(info)
+	if info.Sensors != nil {
+		t.Errorf("Sensors = %+v; want nil", info.Sensors)
+	}
+
+	// 个别传感器读取失败（Warnings）时仍上报其余读数
+	c.temperaturesFn = func(context.Context) ([]sensors.TemperatureStat, error) {
+		warns := &sensors.Warnings{}
+		warns.Add(errors.New("read temp2_input: no such device"))
+		return []sensors.TemperatureStat{{SensorKey: "k10temp_tctl", Temperature: 61.5}}, warns
+	}
+	if got := collectInto(t, c).Sensors; got == nil || len(got.Temperatures) != 1 || got.Temperatures[0].Name != "k10temp_tctl" {
+		t.Errorf("Warnings 时 Sensors = %+v; want 1 个温度传感器", got)
+	}
+
+	c.temperaturesFn = func(context.Context) ([]sensors.TemperatureStat, error) {
+		return nil, errors.New("syntax error in pattern")
+	}
+	if _, err := c.Collect(context.Background()); err == nil {
+		t.Error("读取失败时应返回错误")
+	}
+}
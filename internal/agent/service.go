@@ -6,9 +6,11 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ruanun/simple-server-status/internal/agent/config"
+	"github.com/ruanun/simple-server-status/pkg/model"
 	"go.uber.org/zap"
 )
 
@@ -19,15 +21,24 @@ type AgentService struct {
 	logger *zap.SugaredLogger
 
 	// 核心组件
-	wsClient     *WsClient
+	reportClient ReportClient // WebSocket 或 HTTP 推送客户端；拉取模式下为 nil
+	pullServer   *PullServer  // 拉取服务；未配置 pullListen 时为 nil
 	monitor      *PerformanceMonitor
 	memoryPool   *MemoryPoolManager
 	errorHandler *ErrorHandler
+	httpClient   *http.Client // 出站 HTTP 客户端（遵循代理配置）
+	netStats     *NetworkStatsCollector
+	probes       *ProbeCollector
+	collectors   *CollectorRegistry
 
 	// 服务器信息
 	hostIp       string // 服务器IP地址
 	hostLocation string // 服务器地理位置
 
+	// 最近一次采集的服务器信息（供拉取模式使用）
+	latestInfo *model.ServerInfo
+	latestMu   sync.RWMutex
+
 	// 生命周期管理
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -77,19 +88,88 @@ func (s *AgentService) initComponents() error {
 	s.errorHandler = NewErrorHandler(s.logger, s.monitor)
 	s.logger.Info("错误处理器已初始化")
 
-	// 4. 初始化 WebSocket 客户端（依赖所有组件）
-	s.wsClient = NewWsClient(s.config, s.logger, s.errorHandler, s.memoryPool, s.monitor)
-	s.logger.Info("WebSocket 客户端已初始化")
+	// 4. 初始化采集器（依赖 errorHandler）
+	s.netStats = NewNetworkStatsCollector(excludeNetInterfaces)
+	s.netStats.SetSmoothing(time.Duration(s.config.NetRateSmoothing) * time.Second)
+	s.probes = NewProbeCollector()
+	s.collectors = NewCollectorRegistry(s.errorHandler, s.monitor, s.logger)
+	if err := RegisterBuiltinCollectors(s.collectors, s.netStats, s.probes); err != nil {
+		return fmt.Errorf("注册采集器失败: %w", err)
+	}
+	if err := s.collectors.Configure(s.config.Collectors); err != nil {
+		return fmt.Errorf("采集器配置无效: %w", err)
+	}
+	if err := s.collectors.ApplyConfig(s.config); err != nil {
+		return fmt.Errorf("采集器配置无效: %w", err)
+	}
+	s.logger.Info("采集器已初始化")
+
+	// 4.1 初始化出站 HTTP 客户端（依赖代理配置）
+	proxyFunc, err := NewProxyFunc(s.config.Proxy)
+	if err != nil {
+		return fmt.Errorf("代理配置无效: %w", err)
+	}
+	s.httpClient = NewHTTPClient(proxyFunc, 30*time.Second)
+	if s.config.Proxy != "" {
+		s.logger.Info("出站代理已启用")
+	}
+
+	// 5. 初始化上报客户端（依赖所有组件），根据配置选择 WebSocket 或 HTTP 推送
+	transport, serverAddr := ResolveTransport(s.config)
+	switch transport {
+	case TransportPull:
+		s.logger.Info("拉取模式：不主动连接面板")
+	case TransportHTTP:
+		pushClient := NewHTTPPushClient(s.config, serverAddr, s.httpClient, s.logger, s.errorHandler, s.memoryPool)
+		pushClient.OnCommand = s.handleCommand
+		s.reportClient = pushClient
+		s.logger.Info("HTTP 推送客户端已初始化")
+	default:
+		wsClient := NewWsClient(s.config, proxyFunc, s.logger, s.errorHandler, s.memoryPool, s.monitor)
+		// 连接（重连）建立时立即刷新硬件与系统信息，面板重启后无需等待下一个采集周期
+		wsClient.OnConnected = func() { s.collectors.Refresh(InventoryCollectorNames...) }
+		wsClient.OnCommand = s.handleCommand
+		s.reportClient = wsClient
+		s.logger.Info("WebSocket 客户端已初始化")
+	}
+
+	// 6. 初始化拉取服务（可选）
+	if s.config.PullListen != "" {
+		s.pullServer = NewPullServer(s.config, s.getLatestInfo, s.logger)
+		s.logger.Info("拉取服务已初始化")
+	}
 
 	return nil
 }
 
+// handleCommand 执行面板下发的指令
+func (s *AgentService) handleCommand(cmd *model.AgentCommand) {
+	switch cmd.Type {
+	case model.CommandProbes:
+		if err := s.probes.SetTasks(cmd.Probes); err != nil {
+			s.logger.Warnf("部分网络探测任务无效: %v", err)
+		}
+		s.logger.Debugf("已更新网络探测任务，共 %d 个", len(cmd.Probes))
+	default:
+		s.logger.Debugf("忽略未知的面板指令: %s", cmd.Type)
+	}
+}
+
 // Start 启动服务
 func (s *AgentService) Start() error {
 	s.logger.Info("启动 Agent 服务...")
 
-	// 启动 WebSocket 客户端
-	s.wsClient.Start()
+	// 启动上报客户端
+	if s.reportClient != nil {
+		s.reportClient.Start()
+	}
+
+	// 启动拉取服务
+	if s.pullServer != nil {
+		if err := s.pullServer.Start(); err != nil {
+			return fmt.Errorf("启动拉取服务失败: %w", err)
+		}
+	}
 
 	// 启动业务任务（数据收集和上报）
 	go s.startTasks()
@@ -116,7 +196,7 @@ func (s *AgentService) startTasks() {
 func (s *AgentService) statNetInfo() {
 	defer func() {
 		if err := recover(); err != nil {
-			s.logger.Error("StatNetworkSpeed panic: ", err)
+			s.logger.Error("statNetInfo panic: ", err)
 		}
 	}()
 
@@ -129,7 +209,9 @@ func (s *AgentService) statNetInfo() {
 			s.logger.Info("网络统计 goroutine 正常退出")
 			return
 		case <-ticker.C:
-			StatNetworkSpeed()
+			if err := s.netStats.Update(); err != nil {
+				s.errorHandler.HandleError(NewAppError(ErrorTypeSystem, SeverityLow, "更新网络统计失败", err))
+			}
 		}
 	}
 }
@@ -155,13 +237,8 @@ func (s *AgentService) getServerLocAndIp() {
 	url := urls[RandomIntInRange(0, len(urls)-1)]
 	s.logger.Debugf("getServerLocAndIp url: %s", url)
 
-	// 创建带超时的 HTTP 客户端
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-
-	// 发送 GET 请求
-	resp, err := client.Get(url)
+	// 发送 GET 请求（使用遵循代理配置的 HTTP 客户端）
+	resp, err := s.httpClient.Get(url)
 	if err != nil {
 		s.logger.Warnf("Failed to fetch IP location from %s: %v", url, err)
 		return
@@ -222,6 +299,12 @@ func (s *AgentService) reportInfo() {
 
 	s.logger.Debug("reportInfo start")
 
+	// 开启高频采样时按采样间隔采集、按上报间隔发送
+	if s.config.SampleIntervalMs > 0 {
+		s.sampleAndReport()
+		return
+	}
+
 	// 创建自适应收集器
 	adaptiveCollector := NewAdaptiveCollector(s.config.ReportTimeInterval, s.logger)
 	s.logger.Info("Adaptive collection strategy enabled")
@@ -252,16 +335,11 @@ func (s *AgentService) reportInfo() {
 			s.logger.Info("数据上报 goroutine 正常退出")
 			return
 		case <-ticker.C:
-			serverInfo := GetServerInfo(s.hostIp, s.hostLocation)
-
-			// 记录数据收集事件
-			s.monitor.IncrementDataCollection()
+			serverInfo := s.collect()
 
-			// 通过 WebSocket 发送
-			s.wsClient.SendJsonMsg(serverInfo)
-
-			// 记录发送事件
-			s.monitor.IncrementWebSocketMessage()
+			// 保存快照供拉取模式使用
+			s.setLatestInfo(serverInfo)
+			s.send(serverInfo)
 
 			// 使用自适应间隔，重置 ticker
 			currentInterval := adaptiveCollector.GetCurrentInterval()
@@ -270,6 +348,86 @@ func (s *AgentService) reportInfo() {
 	}
 }
 
+// sampleAndReport 按采样间隔采集，每个上报周期发送最新一次采样及周期内的统计
+// 采样频率固定，不使用自适应间隔；上报频率仍由 reportTimeInterval 决定
+func (s *AgentService) sampleAndReport() {
+	sampleInterval := time.Duration(s.config.SampleIntervalMs) * time.Millisecond
+	reportInterval := time.Duration(s.config.ReportTimeInterval) * time.Second
+	s.logger.Infof("高频采样已启用: 采样间隔 %v, 上报间隔 %v", sampleInterval, reportInterval)
+
+	window := NewSampleWindow()
+	var aggregates *model.SampleAggregates
+	lastReport := time.Now()
+
+	ticker := time.NewTicker(sampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			s.logger.Info("数据上报 goroutine 正常退出")
+			return
+		case now := <-ticker.C:
+			serverInfo := s.collect()
+			window.Add(serverInfo, now)
+
+			// 留出半个采样间隔的余量，避免 ticker 抖动导致上报推迟一个采样间隔
+			due := now.Sub(lastReport) >= reportInterval-sampleInterval/2
+			if due {
+				lastReport = now
+				aggregates = window.Flush(now)
+			}
+
+			// 拉取模式返回最新一次采样及最近一个完整周期的统计
+			serverInfo.Aggregates = aggregates
+			s.setLatestInfo(serverInfo)
+			if due {
+				s.send(serverInfo)
+			}
+		}
+	}
+}
+
+// collect 执行一次采集
+func (s *AgentService) collect() *model.ServerInfo {
+	serverInfo := s.collectors.Collect(s.ctx)
+	serverInfo.Ip = s.hostIp
+	serverInfo.Loc = s.hostLocation
+
+	// 记录数据收集事件
+	s.monitor.IncrementDataCollection()
+	return serverInfo
+}
+
+// send 通过 WebSocket 或 HTTP 推送发送（拉取模式下不主动发送）
+func (s *AgentService) send(serverInfo *model.ServerInfo) {
+	if s.reportClient == nil {
+		return
+	}
+	// WebSocket 上报附带 Agent 测得的链路质量
+	if ws, ok := s.reportClient.(*WsClient); ok {
+		serverInfo.Link = ws.LinkQuality()
+	}
+	s.reportClient.SendJsonMsg(serverInfo)
+
+	// 记录发送事件
+	s.monitor.IncrementWebSocketMessage()
+}
+
+// setLatestInfo 保存最近一次采集的服务器信息
+func (s *AgentService) setLatestInfo(info *model.ServerInfo) {
+	s.latestMu.Lock()
+	s.latestInfo = info
+	s.latestMu.Unlock()
+}
+
+// getLatestInfo 获取最近一次采集的服务器信息
+func (s *AgentService) getLatestInfo() *model.ServerInfo {
+	s.latestMu.RLock()
+	defer s.latestMu.RUnlock()
+	return s.latestInfo
+}
+
 // Stop 停止服务
 func (s *AgentService) Stop(timeout time.Duration) error {
 	s.logger.Info("停止 Agent 服务...")
@@ -284,20 +442,27 @@ func (s *AgentService) Stop(timeout time.Duration) error {
 	// 2. 等待一小段时间让 goroutine 处理取消信号
 	time.Sleep(time.Millisecond * 200)
 
-	// 3. 关闭 WebSocket 客户端
+	// 3. 关闭上报客户端
 	done := make(chan struct{})
 	go func() {
-		if s.wsClient != nil {
-			s.wsClient.Close()
+		if s.reportClient != nil {
+			s.reportClient.Close()
 		}
 		close(done)
 	}()
 
 	select {
 	case <-done:
-		s.logger.Info("WebSocket 客户端已关闭")
+		s.logger.Info("上报客户端已关闭")
 	case <-ctx.Done():
-		s.logger.Warn("WebSocket 客户端关闭超时")
+		s.logger.Warn("上报客户端关闭超时")
+	}
+
+	// 关闭拉取服务
+	if s.pullServer != nil {
+		if err := s.pullServer.Close(ctx); err != nil {
+			s.logger.Warnf("拉取服务关闭失败: %v", err)
+		}
 	}
 
 	// 4. 关闭性能监控器
@@ -343,10 +508,10 @@ func (s *AgentService) GetMemoryPoolStats() PoolStats {
 	return PoolStats{}
 }
 
-// GetWSStats 获取 WebSocket 统计（用于外部监控）
+// GetWSStats 获取上报客户端统计（用于外部监控）
 func (s *AgentService) GetWSStats() map[string]int64 {
-	if s.wsClient != nil {
-		return s.wsClient.GetStats()
+	if s.reportClient != nil {
+		return s.reportClient.GetStats()
 	}
 	return nil
 }
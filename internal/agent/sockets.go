@@ -0,0 +1,207 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ruanun/simple-server-status/pkg/model"
+)
+
+// defaultProcRoot 默认的 procfs 挂载点
+const defaultProcRoot = "/proc"
+
+// socketCounters 需要按两次采集的差值上报的累计计数器
+type socketCounters struct {
+	outSegs         uint64
+	retransSegs     uint64
+	listenOverflows uint64
+	listenDrops     uint64
+	udpErrors       uint64
+}
+
+// SocketCollector TCP/UDP 连接与套接字统计采集器，仅 Linux
+// 各状态连接数读取 /proc/net/sockstat{,6} 与 /proc/net/snmp，重传率和 accept 队列溢出按两次采集的差值计算
+type SocketCollector struct {
+	baseCollector
+	procRoot string
+
+	mu   sync.Mutex
+	last *socketCounters
+}
+
+// NewSocketCollector 创建套接字统计采集器
+func NewSocketCollector() *SocketCollector {
+	return &SocketCollector{
+		baseCollector: baseCollector{name: CollectorSockets},
+		procRoot:      defaultProcRoot,
+	}
+}
+
+// Enabled 只有 Linux 提供 /proc/net 下的统计文件
+func (c *SocketCollector) Enabled() bool { return runtime.GOOS == "linux" }
+
+// Collect 采集套接字统计；首次采集只记录计数器，重传率等差值从第二次开始上报
+func (c *SocketCollector) Collect(_ context.Context) (PartialInfo, error) {
+	sockstat, err := readProcTable(filepath.Join(c.procRoot, "net", "sockstat"), parseSockstat)
+	if err != nil {
+		return nil, fmt.Errorf("读取 sockstat 失败: %w", err)
+	}
+	// 内核关闭 IPv6 时没有 sockstat6
+	sockstat6, _ := readProcTable(filepath.Join(c.procRoot, "net", "sockstat6"), parseSockstat)
+	snmp, err := readProcTable(filepath.Join(c.procRoot, "net", "snmp"), parseNetSNMP)
+	if err != nil {
+		return nil, fmt.Errorf("读取 snmp 失败: %w", err)
+	}
+	// 部分容器环境没有 netstat，此时不上报 accept 队列溢出
+	netstat, _ := readProcTable(filepath.Join(c.procRoot, "net", "netstat"), parseNetSNMP)
+
+	info := &model.SocketInfo{
+		TCPEstablished: snmp["Tcp"]["CurrEstab"],
+		TCPTimeWait:    sockstat["TCP"]["tw"],
+		TCPOrphan:      sockstat["TCP"]["orphan"],
+		TCPInUse:       sockstat["TCP"]["inuse"] + sockstat6["TCP6"]["inuse"],
+		UDPInUse:       sockstat["UDP"]["inuse"] + sockstat6["UDP6"]["inuse"],
+	}
+	cur := &socketCounters{
+		outSegs:         snmp["Tcp"]["OutSegs"],
+		retransSegs:     snmp["Tcp"]["RetransSegs"],
+		listenOverflows: netstat["TcpExt"]["ListenOverflows"],
+		listenDrops:     netstat["TcpExt"]["ListenDrops"],
+		udpErrors:       snmp["Udp"]["InErrors"],
+	}
+	if count, limit, ok := readConntrack(c.procRoot); ok {
+		info.ConntrackCount = count
+		info.ConntrackMax = limit
+		if limit > 0 {
+			info.ConntrackPercent = math.Round(float64(count)/float64(limit)*10000) / 100
+		}
+	}
+
+	c.mu.Lock()
+	if last := c.last; last != nil {
+		if cur.outSegs > last.outSegs && cur.retransSegs >= last.retransSegs {
+			retrans := float64(cur.retransSegs-last.retransSegs) / float64(cur.outSegs-last.outSegs) * 100
+			info.RetransPercent = math.Round(retrans*100) / 100
+		}
+		info.ListenOverflows = counterDelta(last.listenOverflows, cur.listenOverflows)
+		info.ListenDrops = counterDelta(last.listenDrops, cur.listenDrops)
+		info.UDPErrors = counterDelta(last.udpErrors, cur.udpErrors)
+	}
+	c.last = cur
+	c.mu.Unlock()
+
+	return func(s *model.ServerInfo) { s.Sockets = info }, nil
+}
+
+// counterDelta 计算累计计数器的增量；计数器回绕或重置时返回 0
+func counterDelta(last, cur uint64) uint64 {
+	if cur < last {
+		return 0
+	}
+	return cur - last
+}
+
+// readProcTable 打开 procfs 文件并用 parse 解析
+func readProcTable(path string, parse func(io.Reader) (map[string]map[string]uint64, error)) (map[string]map[string]uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return parse(f)
+}
+
+// parseSockstat 解析 /proc/net/sockstat 与 sockstat6，返回协议到各字段的映射
+// 每行格式：TCP: inuse 5 orphan 0 tw 2 alloc 7 mem 1
+func parseSockstat(r io.Reader) (map[string]map[string]uint64, error) {
+	result := make(map[string]map[string]uint64)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		proto, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields)%2 != 0 {
+			return nil, fmt.Errorf("%s 字段数量无效: %q", proto, rest)
+		}
+		values := make(map[string]uint64, len(fields)/2)
+		for i := 0; i < len(fields); i += 2 {
+			v, err := strconv.ParseUint(fields[i+1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s 的值无效: %w", proto, fields[i], err)
+			}
+			values[fields[i]] = v
+		}
+		result[proto] = values
+	}
+	return result, scanner.Err()
+}
+
+// parseNetSNMP 解析 /proc/net/snmp 与 /proc/net/netstat，返回协议到各计数器的映射
+// 每个协议占两行，第一行为字段名，第二行为对应的值：
+//
+//	Tcp: RtoAlgorithm RtoMin RtoMax MaxConn ActiveOpens ...
+//	Tcp: 1 200 120000 -1 551 ...
+//
+// 负值（如 MaxConn 的 -1）表示不适用，不写入结果
+func parseNetSNMP(r io.Reader) (map[string]map[string]uint64, error) {
+	result := make(map[string]map[string]uint64)
+	scanner := bufio.NewScanner(r)
+	// netstat 的 TcpExt 行较长，超过默认的 64KB 缓冲时扩容
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		header := strings.Fields(scanner.Text())
+		if len(header) == 0 {
+			continue
+		}
+		if !scanner.Scan() {
+			return nil, fmt.Errorf("%s 缺少数值行", strings.TrimSuffix(header[0], ":"))
+		}
+		values := strings.Fields(scanner.Text())
+		if len(values) != len(header) || values[0] != header[0] {
+			return nil, fmt.Errorf("字段名与数值不匹配: %q", strings.Join(header, " "))
+		}
+		proto := strings.TrimSuffix(header[0], ":")
+		counters := make(map[string]uint64, len(header)-1)
+		for i := 1; i < len(header); i++ {
+			if v, err := strconv.ParseUint(values[i], 10, 64); err == nil {
+				counters[header[i]] = v
+			}
+		}
+		result[proto] = counters
+	}
+	return result, scanner.Err()
+}
+
+// readConntrack 读取连接跟踪表的条目数与上限；未加载 nf_conntrack 时返回 false
+func readConntrack(procRoot string) (count, limit uint64, ok bool) {
+	dir := filepath.Join(procRoot, "sys", "net", "netfilter")
+	count, err := readUintFile(filepath.Join(dir, "nf_conntrack_count"))
+	if err != nil {
+		return 0, 0, false
+	}
+	limit, err = readUintFile(filepath.Join(dir, "nf_conntrack_max"))
+	if err != nil {
+		return 0, 0, false
+	}
+	return count, limit, true
+}
+
+// readUintFile 读取只包含一个无符号整数的文件
+func readUintFile(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
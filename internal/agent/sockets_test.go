@@ -0,0 +1,166 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ruanun/simple-server-status/pkg/model"
+)
+
+// TestParseSockstat 测试 sockstat 解析
+func TestParseSockstat(t *testing.T) {
+	f, err := os.Open("testdata/proc/net/sockstat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	got, err := parseSockstat(f)
+	if err != nil {
+		t.Fatalf("parseSockstat() error = %v", err)
+	}
+	tests := []struct {
+		proto, field string
+		want         uint64
+	}{
+		{"sockets", "used", 1843},
+		{"TCP", "inuse", 1204},
+		{"TCP", "orphan", 3},
+		{"TCP", "tw", 5821},
+		{"UDP", "inuse", 12},
+		{"FRAG", "memory", 0},
+	}
+	for _, tt := range tests {
+		if v := got[tt.proto][tt.field]; v != tt.want {
+			t.Errorf("%s %s = %d; want %d", tt.proto, tt.field, v, tt.want)
+		}
+	}
+
+	if _, err := parseSockstat(strings.NewReader("TCP: inuse 5 orphan\n")); err == nil {
+		t.Error("字段数量无效时应返回错误")
+	}
+	if _, err := parseSockstat(strings.NewReader("TCP: inuse x\n")); err == nil {
+		t.Error("数值无效时应返回错误")
+	}
+}
+
+// TestParseNetSNMP 测试 snmp 与 netstat 解析
+func TestParseNetSNMP(t *testing.T) {
+	tests := []struct {
+		file         string
+		proto, field string
+		want         uint64
+	}{
+		{"snmp", "Tcp", "CurrEstab", 1087},
+		{"snmp", "Tcp", "OutSegs", 893120000},
+		{"snmp", "Tcp", "RetransSegs", 1250000},
+		{"snmp", "Udp", "InErrors", 52},
+		{"snmp", "IcmpMsg", "InType8", 29976},
+		{"netstat", "TcpExt", "ListenOverflows", 732},
+		{"netstat", "TcpExt", "ListenDrops", 741},
+		{"netstat", "IpExt", "OutOctets", 977120331002},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file+"/"+tt.field, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata/proc/net", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = f.Close() }()
+
+			got, err := parseNetSNMP(f)
+			if err != nil {
+				t.Fatalf("parseNetSNMP() error = %v", err)
+			}
+			if v := got[tt.proto][tt.field]; v != tt.want {
+				t.Errorf("%s %s = %d; want %d", tt.proto, tt.field, v, tt.want)
+			}
+		})
+	}
+
+	got, _ := parseNetSNMP(strings.NewReader("Tcp: MaxConn CurrEstab\nTcp: -1 3\n"))
+	if _, ok := got["Tcp"]["MaxConn"]; ok {
+		t.Error("负值不应写入结果")
+	}
+	if _, err := parseNetSNMP(strings.NewReader("Tcp: MaxConn CurrEstab\n")); err == nil {
+		t.Error("缺少数值行时应返回错误")
+	}
+	if _, err := parseNetSNMP(strings.NewReader("Tcp: MaxConn CurrEstab\nUdp: 1 2\n")); err == nil {
+		t.Error("字段名与数值不匹配时应返回错误")
+	}
+}
+
+// TestSocketCollector 测试套接字统计与两次采集之间的差值
+func TestSocketCollector(t *testing.T) {
+	root := t.TempDir()
+	if err := os.CopyFS(root, os.DirFS("testdata/proc")); err != nil {
+		t.Fatal(err)
+	}
+	c := NewSocketCollector()
+	c.procRoot = root
+
+	info := collectInto(t, c).Sockets
+	want := model.SocketInfo{
+		TCPEstablished:   1087,
+		TCPTimeWait:      5821,
+		TCPOrphan:        3,
+		TCPInUse:         1300,
+		UDPInUse:         16,
+		ConntrackCount:   196214,
+		ConntrackMax:     262144,
+		ConntrackPercent: 74.85,
+	}
+	if *info != want {
+		t.Errorf("首次采集 = %+v; want %+v", *info, want)
+	}
+
+	// 第二次采集：发送 10000 个报文，其中 150 个为重传
+	replaceInFile(t, filepath.Join(root, "net", "snmp"), "893120000 1250000", "893130000 1250150")
+	replaceInFile(t, filepath.Join(root, "net", "snmp"), "7712340 10100 52", "7712340 10100 60")
+	replaceInFile(t, filepath.Join(root, "net", "netstat"), "732 741", "740 752")
+	info = collectInto(t, c).Sockets
+	if info.RetransPercent != 1.5 {
+		t.Errorf("RetransPercent = %v; want 1.5", info.RetransPercent)
+	}
+	if info.ListenOverflows != 8 || info.ListenDrops != 11 || info.UDPErrors != 8 {
+		t.Errorf("ListenOverflows/ListenDrops/UDPErrors = %d/%d/%d; want 8/11/8", info.ListenOverflows, info.ListenDrops, info.UDPErrors)
+	}
+
+	// 计数器重置（如网络命名空间重建）时不上报差值
+	replaceInFile(t, filepath.Join(root, "net", "netstat"), "740 752", "0 0")
+	if info = collectInto(t, c).Sockets; info.ListenOverflows != 0 || info.RetransPercent != 0 {
+		t.Errorf("计数器重置后 = %+v", *info)
+	}
+
+	// 没有 IPv6、netstat 与 nf_conntrack 时仍上报其余统计
+	_ = os.Remove(filepath.Join(root, "net", "sockstat6"))
+	_ = os.Remove(filepath.Join(root, "net", "netstat"))
+	_ = os.RemoveAll(filepath.Join(root, "sys"))
+	info = collectInto(t, c).Sockets
+	if info.TCPInUse != 1204 || info.UDPInUse != 12 || info.ConntrackMax != 0 {
+		t.Errorf("缺少可选文件时 = %+v", *info)
+	}
+
+	_ = os.Remove(filepath.Join(root, "net", "sockstat"))
+	if _, err := c.Collect(context.Background()); err == nil {
+		t.Error("缺少 sockstat 时应返回错误")
+	}
+}
+
+// replaceInFile 替换测试文件中的内容，模拟两次采集之间计数器的变化
+func replaceInFile(t *testing.T, path, old, new string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), old) {
+		t.Fatalf("%s 中没有 %q", path, old)
+	}
+	if err := os.WriteFile(path, []byte(strings.Replace(string(data), old, new, 1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
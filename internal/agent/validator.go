@@ -2,11 +2,16 @@ package internal
 
 import (
 	"fmt"
+	"net"
 	"net/url"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/ruanun/simple-server-status/internal/agent/config"
+	"github.com/ruanun/simple-server-status/pkg/model"
 )
 
 // ValidationError 验证错误
@@ -69,15 +74,46 @@ func (cv *ConfigValidator) ValidateConfig() *ValidationResult {
 	// 验证上报间隔
 	cv.validateReportTimeInterval(result)
 
+	// 验证高频采样间隔
+	cv.validateSampleInterval(result)
+
+	// 验证网速平滑
+	cv.validateNetRateSmoothing(result)
+
 	// 验证日志配置
 	cv.validateLogConfig(result)
 
+	// 验证代理配置
+	cv.validateProxy(result)
+
+	// 验证上报方式
+	cv.validateTransport(result)
+
+	// 验证采集器配置
+	cv.validateCollectors(result)
+
+	// 验证磁盘分区配置
+	cv.validateDisk(result)
+
+	// 验证进程排行配置
+	cv.validateProcesses(result)
+
+	// 验证硬件传感器配置
+	cv.validateSensors(result)
+
+	// 验证受监控进程配置
+	cv.validateWatchProcesses(result)
+
+	// 验证可用性检查配置
+	cv.validateChecks(result)
+
 	return result
 }
 
 // validateRequiredFields 验证必填字段
 func (cv *ConfigValidator) validateRequiredFields(result *ValidationResult) {
-	if strings.TrimSpace(cv.config.ServerAddr) == "" {
+	// 拉取模式下 Agent 不主动连接面板，无需服务器地址
+	if strings.TrimSpace(cv.config.ServerAddr) == "" && strings.ToLower(cv.config.Transport) != TransportPull {
 		result.AddError("ServerAddr", "server address is required")
 	}
 
@@ -96,9 +132,16 @@ func (cv *ConfigValidator) validateServerAddr(result *ValidationResult) {
 		return // 已在必填字段验证中处理
 	}
 
-	// 检查是否为有效的WebSocket URL
-	if !strings.HasPrefix(cv.config.ServerAddr, "ws://") && !strings.HasPrefix(cv.config.ServerAddr, "wss://") {
-		result.AddError("ServerAddr", "server address must start with ws:// or wss://")
+	// 检查是否为有效的 WebSocket 或 HTTP 推送 URL
+	validPrefix := false
+	for _, prefix := range []string{"ws://", "wss://", "http://", "https://"} {
+		if strings.HasPrefix(cv.config.ServerAddr, prefix) {
+			validPrefix = true
+			break
+		}
+	}
+	if !validPrefix {
+		result.AddError("ServerAddr", "server address must start with ws://, wss://, http:// or https://")
 		return
 	}
 
@@ -179,6 +222,31 @@ func (cv *ConfigValidator) validateReportTimeInterval(result *ValidationResult)
 	// - 大于 60 秒可能降低监控精度
 }
 
+// validateSampleInterval 验证高频采样间隔
+func (cv *ConfigValidator) validateSampleInterval(result *ValidationResult) {
+	interval := cv.config.SampleIntervalMs
+	if interval == 0 {
+		return
+	}
+	if interval < 100 {
+		result.AddError("SampleIntervalMs", "sample interval must be at least 100 milliseconds (or 0 to disable)")
+		return
+	}
+	if cv.config.ReportTimeInterval > 0 && interval >= cv.config.ReportTimeInterval*1000 {
+		result.AddError("SampleIntervalMs", "sample interval must be shorter than the report time interval")
+	}
+}
+
+// validateNetRateSmoothing 验证网速平滑的时间常数
+func (cv *ConfigValidator) validateNetRateSmoothing(result *ValidationResult) {
+	if cv.config.NetRateSmoothing < 0 {
+		result.AddError("NetRateSmoothing", "net rate smoothing must be non-negative (0 to disable)")
+	}
+	if cv.config.NetRateSmoothing > 300 {
+		result.AddError("NetRateSmoothing", "net rate smoothing should not exceed 300 seconds")
+	}
+}
+
 // validateLogConfig 验证日志配置
 func (cv *ConfigValidator) validateLogConfig(result *ValidationResult) {
 	// 验证日志级别
@@ -205,6 +273,129 @@ func (cv *ConfigValidator) validateLogConfig(result *ValidationResult) {
 	}
 }
 
+// validateProxy 验证代理配置
+func (cv *ConfigValidator) validateProxy(result *ValidationResult) {
+	if strings.TrimSpace(cv.config.Proxy) == "" {
+		return // 未配置时使用环境变量代理
+	}
+
+	if _, err := ParseProxyURL(cv.config.Proxy); err != nil {
+		result.AddError("Proxy", fmt.Sprintf("invalid proxy: %v", err))
+	}
+}
+
+// validateTransport 验证上报方式配置
+func (cv *ConfigValidator) validateTransport(result *ValidationResult) {
+	transport := strings.ToLower(cv.config.Transport)
+	if transport != "" && transport != TransportWebSocket && transport != TransportHTTP && transport != TransportPull {
+		result.AddError("Transport", "transport must be one of: ws, http, pull (or empty to detect from server address)")
+	}
+
+	if transport == TransportPull && strings.TrimSpace(cv.config.PullListen) == "" {
+		result.AddError("PullListen", "pull listen address is required when transport is pull")
+	}
+	if cv.config.PullListen != "" {
+		if _, port, err := net.SplitHostPort(cv.config.PullListen); err != nil || port == "" {
+			result.AddError("PullListen", "pull listen address must be in host:port format, e.g. :8901")
+		}
+	}
+
+	switch strings.ToLower(cv.config.Encoding) {
+	case "", model.EncodingJSON, model.EncodingMsgpack, model.EncodingCBOR:
+	default:
+		result.AddError("Encoding", "encoding must be one of: json, msgpack, cbor")
+	}
+
+	if cv.config.PushBatchSize < 0 {
+		result.AddError("PushBatchSize", "push batch size must not be negative")
+	}
+	if cv.config.PushBatchSize > 100 {
+		result.AddError("PushBatchSize", "push batch size should not exceed 100")
+	}
+	// HTTP 推送最迟每 maxPushFlushInterval 发送一次，上报间隔更长时面板会在两次上报之间判定离线
+	if t, _ := ResolveTransport(cv.config); t == TransportHTTP && time.Duration(cv.config.ReportTimeInterval)*time.Second > maxPushFlushInterval {
+		result.AddError("ReportTimeInterval", fmt.Sprintf("report time interval must not exceed %d seconds when transport is http", int(maxPushFlushInterval.Seconds())))
+	}
+}
+
+// validateCollectors 验证采集器配置
+func (cv *ConfigValidator) validateCollectors(result *ValidationResult) {
+	for name, cc := range cv.config.Collectors {
+		field := "Collectors." + name
+		known := false
+		for _, builtin := range BuiltinCollectorNames {
+			if name == builtin {
+				known = true
+				break
+			}
+		}
+		if !known {
+			result.AddError(field, fmt.Sprintf("unknown collector, must be one of: %s", strings.Join(BuiltinCollectorNames, ", ")))
+			continue
+		}
+
+		if cc.Interval < 0 {
+			result.AddError(field+".Interval", "collector interval must not be negative")
+		}
+		if cc.Interval > 3600 {
+			result.AddError(field+".Interval", "collector interval should not exceed 3600 seconds (1 hour)")
+		}
+		if cc.Timeout < 0 {
+			result.AddError(field+".Timeout", "collector timeout must not be negative")
+		}
+		if cc.Timeout > 60 {
+			result.AddError(field+".Timeout", "collector timeout should not exceed 60 seconds")
+		}
+		if len(cc.Include) > 0 || len(cc.Exclude) > 0 {
+			if !slices.Contains(FilterableCollectorNames, name) {
+				result.AddError(field, fmt.Sprintf("include/exclude is only supported by: %s", strings.Join(FilterableCollectorNames, ", ")))
+			} else if _, err := NewNameFilter(cc.Include, cc.Exclude); err != nil {
+				result.AddError(field, err.Error())
+			}
+		}
+	}
+}
+
+// validateDisk 验证磁盘分区的筛选规则与总容量统计方式
+func (cv *ConfigValidator) validateDisk(result *ValidationResult) {
+	if _, err := newDiskOptions(cv.config.Disk); err != nil {
+		result.AddError("Disk", err.Error())
+	}
+	for _, mountpoint := range cv.config.Disk.OnlyMountpoints {
+		if strings.TrimSpace(mountpoint) == "" {
+			result.AddError("Disk.OnlyMountpoints", "mountpoint must not be empty")
+		}
+	}
+}
+
+// validateProcesses 验证进程排行数量、命令行长度与隐藏规则
+func (cv *ConfigValidator) validateProcesses(result *ValidationResult) {
+	if _, err := newProcessOptions(cv.config.Processes); err != nil {
+		result.AddError("Processes", err.Error())
+	}
+}
+
+// validateSensors 验证 sysfs 挂载点
+func (cv *ConfigValidator) validateSensors(result *ValidationResult) {
+	if root := cv.config.Sensors.SysfsRoot; root != "" && !filepath.IsAbs(root) {
+		result.AddError("Sensors.SysfsRoot", "sysfs root must be an absolute path")
+	}
+}
+
+// validateWatchProcesses 验证受监控进程的匹配方式与名称
+func (cv *ConfigValidator) validateWatchProcesses(result *ValidationResult) {
+	if _, err := newWatchTargets(cv.config.WatchProcesses); err != nil {
+		result.AddError("WatchProcesses", err.Error())
+	}
+}
+
+// validateChecks 验证可用性检查的类型、目标、周期与名称
+func (cv *ConfigValidator) validateChecks(result *ValidationResult) {
+	if _, err := newCheckSpecs(cv.config.Checks); err != nil {
+		result.AddError("Checks", err.Error())
+	}
+}
+
 // ValidateAndSetDefaults 验证配置并设置默认值
 func ValidateAndSetDefaults(cfg *config.AgentConfig) error {
 	fmt.Println("[INFO] 开始配置验证和默认值设置...")
@@ -242,6 +433,31 @@ func setConfigDefaults(cfg *config.AgentConfig) {
 
 	// 标准化日志级别
 	cfg.LogLevel = strings.ToLower(cfg.LogLevel)
+
+	// 设置默认推送批量大小
+	if cfg.PushBatchSize == 0 {
+		cfg.PushBatchSize = 5
+	}
+
+	// 标准化上报方式
+	cfg.Transport = strings.ToLower(cfg.Transport)
+
+	// 标准化上报编码
+	cfg.Encoding = strings.ToLower(cfg.Encoding)
+	if cfg.Encoding == "" {
+		cfg.Encoding = model.EncodingJSON
+	}
+
+	// 默认按设备去重统计磁盘总容量
+	cfg.Disk.TotalMode = strings.ToLower(cfg.Disk.TotalMode)
+	if cfg.Disk.TotalMode == "" {
+		cfg.Disk.TotalMode = DiskTotalDevice
+	}
+
+	// 拉取模式默认监听地址
+	if cfg.Transport == TransportPull && cfg.PullListen == "" {
+		cfg.PullListen = ":8901"
+	}
 }
 
 // ValidateEnvironment 验证运行环境
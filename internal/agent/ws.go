@@ -2,20 +2,26 @@ package internal
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math"
 	"net/http"
+	"net/url"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/ruanun/simple-server-status/internal/agent/config"
+	"github.com/ruanun/simple-server-status/internal/shared/heartbeat"
+	"github.com/ruanun/simple-server-status/pkg/model"
 	"go.uber.org/zap"
 )
 
 const (
 	// retryCountMax WebSocket 客户端最大重试次数
 	retryCountMax = 999
+	// heartbeatWriteWait 发送 ping 的超时
+	heartbeatWriteWait = 10 * time.Second
 )
 
 type WsClient struct {
@@ -27,25 +33,36 @@ type WsClient struct {
 	RetryCountMax int
 	// 链接
 	conn *websocket.Conn
+	// 拨号器（遵循代理配置）
+	dialer *websocket.Dialer
 	// 连接状态管理
 	connected    bool
+	frames       bool        // 面板是否支持分帧上报（握手时协商）
+	codec        model.Codec // 当前连接使用的编码（握手时协商，面板不支持时为 JSON）
 	connMutex    sync.RWMutex
 	reconnecting bool
 	// 心跳管理
 	heartbeatInterval time.Duration
-	heartbeatTimeout  time.Duration
-	lastPong          time.Time
+	maxMissedPongs    int                // 连续未收到 pong 的次数达到该值时断开重连
+	link              *heartbeat.Tracker // 由 ping/pong 统计到面板的往返时间
 	// 上下文管理
 	ctx    context.Context
 	cancel context.CancelFunc
 	// 发送队列
-	sendChan chan []byte
+	sendChan chan wsMessage
 	closed   bool // channel 关闭标志,防止重复关闭
 	// 连接统计
 	connectionCount   int64
 	reconnectionCount int64
 	messagesSent      int64
 	messagesReceived  int64
+	bytesSent         int64
+	// 分帧编码
+	encoder *FrameEncoder
+	// 连接建立后的回调（例如刷新硬件信息），需在 Start 前设置
+	OnConnected func()
+	// 收到面板指令（如网络探测任务）时的回调，需在 Start 前设置
+	OnCommand func(cmd *model.AgentCommand)
 	// 依赖注入（移除全局变量）
 	logger       *zap.SugaredLogger
 	config       *config.AgentConfig
@@ -54,8 +71,16 @@ type WsClient struct {
 	monitor      *PerformanceMonitor
 }
 
+// wsMessage 发送队列中的消息，记录编码时使用的编码
+type wsMessage struct {
+	data     []byte
+	encoding string
+	binary   bool
+}
+
 func NewWsClient(
 	cfg *config.AgentConfig,
+	proxyFunc func(*http.Request) (*url.URL, error),
 	logger *zap.SugaredLogger,
 	errorHandler *ErrorHandler,
 	memoryPool *MemoryPoolManager,
@@ -64,20 +89,37 @@ func NewWsClient(
 	var AuthHeader = make(http.Header)
 	AuthHeader.Add("X-AUTH-SECRET", cfg.AuthSecret)
 	AuthHeader.Add("X-SERVER-ID", cfg.ServerId)
+	AuthHeader.Add(model.ProtocolHeader, model.ProtocolFrames)
+	if cfg.Encoding != "" && cfg.Encoding != model.EncodingJSON {
+		AuthHeader.Add(model.EncodingHeader, cfg.Encoding)
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
+	// 显式配置 transport: ws 时，http(s):// 地址会被映射为 ws(s)://
+	_, serverAddr := ResolveTransport(cfg)
+
+	dialer := NewWebSocketDialer(proxyFunc, serverAddr)
+	// 协商 permessage-deflate 压缩，面板不支持时不压缩
+	dialer.EnableCompression = !cfg.DisableCompression
+
+	jsonCodec, _ := model.NewCodec(model.EncodingJSON)
+
 	return &WsClient{
 		AuthHeader:        AuthHeader,
 		RetryCountMax:     retryCountMax,
-		ServerAddr:        cfg.ServerAddr,
+		ServerAddr:        serverAddr,
+		dialer:            dialer,
+		codec:             jsonCodec,
 		connected:         false,
 		reconnecting:      false,
-		heartbeatInterval: time.Second * 30, // 30秒心跳间隔
-		heartbeatTimeout:  time.Second * 45, // 45秒心跳超时
+		heartbeatInterval: time.Second * 15, // 15秒心跳间隔
+		maxMissedPongs:    3,                // 45秒未收到 pong 视为超时
+		link:              heartbeat.NewTracker(heartbeat.DefaultWindow),
 		ctx:               ctx,
 		cancel:            cancel,
-		sendChan:          make(chan []byte, 100), // 缓冲100条消息
+		sendChan:          make(chan wsMessage, 100), // 缓冲100条消息
+		encoder:           NewFrameEncoder(),
 		logger:            logger,
 		config:            cfg,
 		errorHandler:      errorHandler,
@@ -118,19 +160,36 @@ func (c *WsClient) SendJsonMsg(obj interface{}) {
 		c.connMutex.RUnlock()
 		return // 已关闭,直接返回,避免向已关闭的 channel 发送数据
 	}
+	frames, codec := c.frames, c.codec
 	c.connMutex.RUnlock()
 
-	data, err := c.memoryPool.OptimizedJSONMarshal(obj)
+	// 面板支持分帧时，硬件与系统信息只在变化时发送
+	var frame *model.ReportFrame
+	if info, ok := obj.(*model.ServerInfo); ok && frames {
+		frame = c.encoder.Encode(info)
+		obj = frame
+	}
+
+	var data []byte
+	var err error
+	if codec.Binary() {
+		data, err = codec.Marshal(obj)
+	} else {
+		data, err = c.memoryPool.OptimizedJSONMarshal(obj)
+	}
 	if err != nil {
 		// 使用统一错误处理
-		jsonErr := NewAppError(ErrorTypeData, SeverityMedium, "JSON序列化失败", err)
-		c.errorHandler.HandleError(jsonErr)
+		encodeErr := NewAppError(ErrorTypeData, SeverityMedium, codec.Name()+"序列化失败", err)
+		c.errorHandler.HandleError(encodeErr)
 		return
 	}
 
 	select {
-	case c.sendChan <- data:
+	case c.sendChan <- wsMessage{data: data, encoding: codec.Name(), binary: codec.Binary()}:
 		// 消息已加入发送队列
+		if frame != nil {
+			c.encoder.Sent(frame, len(data))
+		}
 	case <-time.After(time.Second * 5):
 		// 使用统一错误处理
 		timeoutErr := NewAppError(ErrorTypeNetwork, SeverityMedium, "发送队列已满，消息发送超时", nil)
@@ -188,9 +247,10 @@ func (c *WsClient) attemptConnection() {
 		}
 
 		// 尝试建立WebSocket连接
-		conn, _, err := websocket.DefaultDialer.Dial(c.ServerAddr, c.AuthHeader)
+		conn, resp, err := c.dialer.Dial(c.ServerAddr, c.AuthHeader)
 		if err == nil {
-			c.setConnection(conn)
+			frames := resp != nil && resp.Header.Get(model.ProtocolHeader) == model.ProtocolFrames
+			c.setConnection(conn, frames, c.negotiateCodec(resp))
 			c.logger.Info("连接成功")
 			c.connectionCount++
 			if c.connectionCount > 1 {
@@ -198,6 +258,9 @@ func (c *WsClient) attemptConnection() {
 			}
 			// 启动消息处理
 			go c.handleMessage()
+			if c.OnConnected != nil {
+				c.OnConnected()
+			}
 			return
 		}
 
@@ -225,16 +288,29 @@ func (c *WsClient) attemptConnection() {
 	}
 }
 
-// setConnection 设置连接
-func (c *WsClient) setConnection(conn *websocket.Conn) {
+// negotiateCodec 根据握手响应确定编码：面板确认了请求的编码时使用该编码，否则使用 JSON
+func (c *WsClient) negotiateCodec(resp *http.Response) model.Codec {
+	requested := c.AuthHeader.Get(model.EncodingHeader)
+	if requested != "" && resp != nil && resp.Header.Get(model.EncodingHeader) == requested {
+		if codec, err := model.NewCodec(requested); err == nil {
+			return codec
+		}
+	}
+	if requested != "" {
+		c.logger.Warnf("面板不支持 %s 编码，使用 JSON 上报", requested)
+	}
+	codec, _ := model.NewCodec(model.EncodingJSON)
+	return codec
+}
+
+// setConnection 设置连接，frames 表示面板是否支持分帧上报，codec 为协商后的编码
+func (c *WsClient) setConnection(conn *websocket.Conn, frames bool, codec model.Codec) {
 	c.connMutex.Lock()
 	defer c.connMutex.Unlock()
 
-	// 设置pong处理器
+	// 设置pong处理器：按 ping 携带的载荷匹配，统计往返时间
 	conn.SetPongHandler(func(appData string) error {
-		c.connMutex.Lock()
-		c.lastPong = time.Now()
-		c.connMutex.Unlock()
+		c.link.Pong(appData, time.Now())
 		return nil
 	})
 
@@ -244,15 +320,11 @@ func (c *WsClient) setConnection(conn *websocket.Conn) {
 
 	c.conn = conn
 	c.connected = true
-	c.lastPong = time.Now() // 初始化lastPong时间
-
-	// 设置pong处理器
-	c.conn.SetPongHandler(func(string) error {
-		c.connMutex.Lock()
-		c.lastPong = time.Now()
-		c.connMutex.Unlock()
-		return nil
-	})
+	c.frames = frames
+	c.codec = codec
+	// 新连接的第一帧发送完整数据
+	c.encoder.Reset()
+	c.link.Reset()
 }
 
 // IsConnected 检查连接状态
@@ -268,17 +340,18 @@ func (c *WsClient) sendLoop() {
 		select {
 		case <-c.ctx.Done():
 			return
-		case data := <-c.sendChan:
-			c.sendMessage(data)
+		case msg := <-c.sendChan:
+			c.sendMessage(msg)
 		}
 	}
 }
 
 // sendMessage 发送消息
-func (c *WsClient) sendMessage(data []byte) {
+func (c *WsClient) sendMessage(msg wsMessage) {
 	c.connMutex.RLock()
 	conn := c.conn
 	connected := c.connected
+	encoding := c.codec.Name()
 	c.connMutex.RUnlock()
 
 	if !connected || conn == nil {
@@ -288,7 +361,17 @@ func (c *WsClient) sendMessage(data []byte) {
 		return
 	}
 
-	err := conn.WriteMessage(websocket.TextMessage, data)
+	// 重连后编码可能变化，旧连接排队的消息面板无法解析，直接丢弃
+	if msg.encoding != encoding {
+		c.logger.Debugf("丢弃编码为 %s 的消息，当前连接编码为 %s", msg.encoding, encoding)
+		return
+	}
+
+	messageType := websocket.TextMessage
+	if msg.binary {
+		messageType = websocket.BinaryMessage
+	}
+	err := conn.WriteMessage(messageType, msg.data)
 	if err != nil {
 		// 使用统一错误处理
 		sendErr := NewAppError(ErrorTypeNetwork, SeverityMedium, "发送消息失败", err)
@@ -299,6 +382,7 @@ func (c *WsClient) sendMessage(data []byte) {
 	}
 
 	c.messagesSent++
+	c.bytesSent += int64(len(msg.data))
 	// 记录WebSocket消息发送事件
 	c.monitor.IncrementWebSocketMessage()
 }
@@ -341,7 +425,9 @@ func (c *WsClient) sendHeartbeat() {
 		return
 	}
 
-	err := conn.WriteMessage(websocket.PingMessage, []byte{})
+	// WriteControl 可与发送循环中的 WriteMessage 并发调用
+	payload := c.link.Ping(time.Now())
+	err := conn.WriteControl(websocket.PingMessage, []byte(payload), time.Now().Add(heartbeatWriteWait))
 	if err != nil {
 		// 使用统一错误处理
 		heartbeatErr := NewAppError(ErrorTypeNetwork, SeverityMedium, "发送心跳失败", err)
@@ -353,12 +439,11 @@ func (c *WsClient) sendHeartbeat() {
 // checkHeartbeat 检查心跳超时
 func (c *WsClient) checkHeartbeat() {
 	c.connMutex.RLock()
-	lastPong := c.lastPong
 	connected := c.connected
 	c.connMutex.RUnlock()
 
-	if connected && time.Since(lastPong) > c.heartbeatTimeout {
-		c.logger.Warn("心跳超时，断开连接")
+	if connected && c.link.Missed() >= c.maxMissedPongs {
+		c.logger.Warnf("连续 %d 次未收到心跳响应，断开连接", c.link.Missed())
 		c.markDisconnected()
 	}
 }
@@ -387,12 +472,32 @@ func (c *WsClient) handleMessage() {
 		}
 
 		c.messagesReceived++
+		c.dispatchCommand(message)
+	}
+}
+
+// dispatchCommand 解析面板下发的指令，不是指令的消息只记录日志
+// 重新同步指令由客户端自身处理，其余指令交给 OnCommand
+func (c *WsClient) dispatchCommand(message []byte) {
+	var cmd model.AgentCommand
+	if err := json.Unmarshal(message, &cmd); err != nil || cmd.Type == "" {
 		c.logger.Debug("收到消息:", string(message))
+		return
+	}
+	if cmd.Type == model.CommandResync {
+		c.logger.Info("面板要求重新同步，下一帧发送完整数据")
+		c.encoder.Reset()
+		return
+	}
+	if c.OnCommand != nil {
+		c.OnCommand(&cmd)
 	}
 }
 
 // GetStats 获取连接统计信息
 func (c *WsClient) GetStats() map[string]int64 {
+	inventoryFrames, metricFrames, bytesSaved := c.encoder.Stats()
+	link := c.link.Quality()
 	c.connMutex.RLock()
 	defer c.connMutex.RUnlock()
 	return map[string]int64{
@@ -400,7 +505,24 @@ func (c *WsClient) GetStats() map[string]int64 {
 		"reconnections":     c.reconnectionCount,
 		"messages_sent":     c.messagesSent,
 		"messages_received": c.messagesReceived,
+		"bytes_sent":        c.bytesSent,
+		"inventory_frames":  inventoryFrames,
+		"metric_frames":     metricFrames,
+		"bytes_saved":       bytesSaved,
+		"rtt_avg_us":        int64(link.AvgRTTMs * 1000),
+		"rtt_jitter_us":     int64(link.JitterMs * 1000),
+		"pings_sent":        link.PingsSent,
+		"pongs_received":    link.PongsReceived,
+		"missed_pongs":      link.MissedPongs,
+	}
+}
+
+// LinkQuality 返回到面板的链路质量；尚未收到任何 pong 时返回 nil
+func (c *WsClient) LinkQuality() *model.LinkQuality {
+	if q := c.link.Quality(); q.PongsReceived > 0 {
+		return q
 	}
+	return nil
 }
 
 // Close 关闭WebSocket客户端
@@ -0,0 +1,93 @@
+package internal
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/ruanun/simple-server-status/pkg/model"
+)
+
+// checkHistorySize 每项检查保留的历史记录条数；按默认 60 秒的检查周期约为 24 小时
+const checkHistorySize = 1440
+
+// checkSeries 单项检查的历史
+type checkSeries struct {
+	typ    string
+	target string
+	points []*model.CheckPoint
+}
+
+// CheckHistory 各服务器可用性检查结果的历史，只保存在内存中，面板重启后清空
+// Agent 每次上报都携带各项检查最近一次的结果，按检查时间去重后记录
+type CheckHistory struct {
+	mu      sync.RWMutex
+	size    int
+	servers map[string]map[string]*checkSeries // serverID -> 检查名称 -> 历史
+}
+
+// NewCheckHistory 创建检查历史，每项检查最多保留 size 条记录
+func NewCheckHistory(size int) *CheckHistory {
+	return &CheckHistory{
+		size:    size,
+		servers: make(map[string]map[string]*checkSeries),
+	}
+}
+
+// Record 记录一次上报中的检查结果
+// 同一结果随后续上报重复发送时只记录一次；检查的类型或目标变化时清空其历史；
+// 上报中不再包含的检查（已从 Agent 配置中删除）一并删除，未携带任何结果的上报不做处理
+func (h *CheckHistory) Record(serverID string, checks []*model.CheckResult) {
+	if len(checks) == 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	old := h.servers[serverID]
+	series := make(map[string]*checkSeries, len(checks))
+	for _, c := range checks {
+		s, ok := old[c.Name]
+		if !ok || s.typ != c.Type || s.target != c.Target {
+			s = &checkSeries{typ: c.Type, target: c.Target}
+		}
+		if n := len(s.points); n == 0 || c.CheckedAt > s.points[n-1].CheckedAt {
+			s.points = append(s.points, &model.CheckPoint{
+				CheckedAt:  c.CheckedAt,
+				Status:     c.Status,
+				LatencyMs:  c.LatencyMs,
+				StatusCode: c.StatusCode,
+				Error:      c.Error,
+			})
+			if len(s.points) > h.size {
+				s.points = s.points[len(s.points)-h.size:]
+			}
+		}
+		series[c.Name] = s
+	}
+	h.servers[serverID] = series
+}
+
+// Get 返回服务器各项检查的历史，按名称排序
+func (h *CheckHistory) Get(serverID string) []*model.CheckHistory {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	result := make([]*model.CheckHistory, 0, len(h.servers[serverID]))
+	for name, s := range h.servers[serverID] {
+		result = append(result, &model.CheckHistory{
+			Name:   name,
+			Type:   s.typ,
+			Target: s.target,
+			Points: append([]*model.CheckPoint(nil), s.points...),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Remove 删除服务器的检查历史
+func (h *CheckHistory) Remove(serverID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.servers, serverID)
+}
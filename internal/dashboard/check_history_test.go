@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/ruanun/simple-server-status/pkg/model"
+)
+
+// TestCheckHistory 测试检查结果去重、容量限制与配置变化
+func TestCheckHistory(t *testing.T) {
+	h := NewCheckHistory(3)
+	api := func(checkedAt int64, status string) *model.CheckResult {
+		return &model.CheckResult{Name: "api", Type: model.CheckTypeHTTP, Target: "https://example.com", Status: status, CheckedAt: checkedAt}
+	}
+	db := &model.CheckResult{Name: "db", Type: model.CheckTypeTCP, Target: "db:5432", Status: model.CheckStatusOK, CheckedAt: 100}
+
+	// 同一结果随多次上报重复发送时只记录一次
+	h.Record("s1", []*model.CheckResult{api(100, model.CheckStatusOK), db})
+	h.Record("s1", []*model.CheckResult{api(100, model.CheckStatusOK), db})
+	h.Record("s1", []*model.CheckResult{api(160, model.CheckStatusFail), db})
+	got := h.Get("s1")
+	if len(got) != 2 || got[0].Name != "api" || got[1].Name != "db" {
+		t.Fatalf("Get() = %+v", got)
+	}
+	if len(got[0].Points) != 2 || got[0].Points[1].Status != model.CheckStatusFail || len(got[1].Points) != 1 {
+		t.Errorf("去重后 api = %+v, db = %+v", got[0].Points, got[1].Points)
+	}
+
+	// 超出容量时丢弃最早的记录；未携带结果的上报不影响历史
+	h.Record("s1", []*model.CheckResult{api(220, model.CheckStatusOK), db})
+	h.Record("s1", []*model.CheckResult{api(280, model.CheckStatusOK), db})
+	h.Record("s1", nil)
+	if got = h.Get("s1"); len(got[0].Points) != 3 || got[0].Points[0].CheckedAt != 160 {
+		t.Errorf("容量限制后 api = %+v", got[0].Points)
+	}
+
+	// 目标变化时清空历史，已删除的检查不再返回
+	changed := api(340, model.CheckStatusOK)
+	changed.Target = "https://example.org"
+	h.Record("s1", []*model.CheckResult{changed})
+	if got = h.Get("s1"); len(got) != 1 || len(got[0].Points) != 1 || got[0].Target != "https://example.org" {
+		t.Errorf("配置变化后 = %+v", got)
+	}
+
+	h.Remove("s1")
+	if got = h.Get("s1"); len(got) != 0 {
+		t.Errorf("删除后 = %+v", got)
+	}
+}
@@ -6,13 +6,25 @@ type DashboardConfig struct {
 	Port                  int             `yaml:"port" json:"port"`                                   //监听的端口; 默认8900
 	WebSocketPath         string          `yaml:"webSocketPath" json:"webSocketPath"`                 //agent WebSocket路径 默认ws-report
 	ReportTimeIntervalMax int             `yaml:"reportTimeIntervalMax" json:"reportTimeIntervalMax"` //上报最大间隔；单位：秒 最小值5 默认值：30；离线判定，超过这个值既视为离线
+	RAMPercentMode        string          `yaml:"ramPercentMode" json:"ramPercentMode"`               //内存占用百分比计算方式：used（默认，Agent 上报的已用内存）或 exclude_cache（total - available，不计入可回收的缓存）
 	Servers               []*ServerConfig `yaml:"servers" validate:"required,dive,required" json:"servers"`
+	Probes                []*ProbeConfig  `yaml:"probes" json:"probes"`                 //网络探测任务，下发给 push 模式的 Agent 执行
+	Heartbeat             HeartbeatConfig `yaml:"heartbeat" json:"heartbeat"`           //Agent WebSocket 心跳与离线判定；修改后需重启面板
+	MaxMessageSize        int             `yaml:"maxMessageSize" json:"maxMessageSize"` //Agent 单条上报消息（WebSocket 消息、自定义上报请求体）的最大大小；单位：KB 默认256；修改后需重启面板
 
 	//日志配置,日志级别
 	LogPath  string `yaml:"logPath"`
 	LogLevel string `yaml:"logLevel"`
 }
 
+// HeartbeatConfig Agent WebSocket 心跳配置
+// 面板定时向 Agent 发送 ping，由对应的 pong 统计往返时间、抖动与丢失次数
+type HeartbeatConfig struct {
+	Interval       int    `yaml:"interval" json:"interval"`             //ping 间隔；单位：秒 默认15
+	MaxMissedPongs int    `yaml:"maxMissedPongs" json:"maxMissedPongs"` //连续未收到 pong 的次数达到该值时断开连接；默认3
+	OfflinePolicy  string `yaml:"offlinePolicy" json:"offlinePolicy"`   //离线判定：report（默认，超过 reportTimeIntervalMax 未上报）或 heartbeat（WebSocket 连接断开或上一次 ping 未收到 pong 时也立即视为离线）
+}
+
 // Validate 实现 ConfigLoader 接口 - 验证配置
 func (c *DashboardConfig) Validate() error {
 	// 基础验证会在配置加载时自动完成
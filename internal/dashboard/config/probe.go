@@ -0,0 +1,13 @@
+package config
+
+// ProbeConfig 网络探测任务；由面板下发给选定的 Agent，Agent 上报到目标的往返时间、抖动与丢包率
+type ProbeConfig struct {
+	Name      string   `yaml:"name" json:"name"`           //探测名称，唯一；展示使用
+	Type      string   `yaml:"type" json:"type"`           //icmp（默认）或 tcp
+	Host      string   `yaml:"host" json:"host"`           //目标主机名或 IP
+	Port      int      `yaml:"port" json:"port"`           //tcp 探测必填；icmp 探测配置后在 Agent 没有 ICMP 权限时回退为 TCP 连接
+	Interval  int      `yaml:"interval" json:"interval"`   //探测周期；单位：秒 默认60
+	Count     int      `yaml:"count" json:"count"`         //每轮探测次数；默认5 最大100
+	TimeoutMs int      `yaml:"timeoutMs" json:"timeoutMs"` //单次探测超时；单位：毫秒 默认1000
+	Servers   []string `yaml:"servers" json:"servers"`     //执行探测的服务器 ID；为空表示全部 push 模式的服务器
+}
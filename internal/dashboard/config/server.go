@@ -1,9 +1,13 @@
 package config
 
 type ServerConfig struct {
-	Name        string `yaml:"name" json:"name" validate:"required"`     //服务名字；展示使用
-	Id          string `yaml:"id" json:"id" validate:"required"`         //id唯一
-	Group       string `yaml:"group" json:"group"`                       //组
-	Secret      string `yaml:"secret" json:"secret" validate:"required"` //授权
-	CountryCode string `yaml:"countryCode" json:"countryCode"`           //国家代码 CN JP US SG
+	Name        string `yaml:"name" json:"name" validate:"required"`                               //服务名字；展示使用
+	Id          string `yaml:"id" json:"id" validate:"required"`                                   //id唯一
+	Group       string `yaml:"group" json:"group"`                                                 //组
+	Secret      string `yaml:"secret" json:"secret" validate:"required_unless=Mode node_exporter"` //授权；node_exporter 模式可不填
+	CountryCode string `yaml:"countryCode" json:"countryCode"`                                     //国家代码 CN JP US SG
+
+	Mode         string `yaml:"mode" json:"mode"`                 //数据来源：push（默认，Agent 主动上报）、pull（面板定时拉取）或 node_exporter（抓取 Prometheus node_exporter）
+	PullUrl      string `yaml:"pullUrl" json:"pullUrl"`           //pull 模式下 Agent 拉取服务地址，如 http://10.0.0.5:8901/server-info；node_exporter 模式下为 metrics 地址，如 http://10.0.0.6:9100/metrics
+	PullInterval int    `yaml:"pullInterval" json:"pullInterval"` //拉取间隔；单位：秒 默认5
 }
@@ -1,8 +1,10 @@
 package internal
 
 import (
+	"cmp"
 	"fmt"
 	"net"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -12,6 +14,7 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	"github.com/ruanun/simple-server-status/internal/dashboard/config"
+	"github.com/ruanun/simple-server-status/pkg/model"
 )
 
 // ConfigValidator 配置验证器
@@ -133,8 +136,12 @@ func (cv *ConfigValidator) ValidateConfig(cfg *config.DashboardConfig) error {
 	cv.validateAddress(cfg.Address)
 	cv.validateWebSocketPath(cfg.WebSocketPath)
 	cv.validateReportInterval(cfg.ReportTimeIntervalMax)
+	cv.validateHeartbeat(cfg.Heartbeat)
+	cv.validateMaxMessageSize(cfg.MaxMessageSize)
+	cv.validateRAMPercentMode(cfg.RAMPercentMode)
 	cv.validateLogConfig(cfg.LogPath, cfg.LogLevel)
 	cv.validateServers(cfg.Servers)
+	cv.validateProbes(cfg.Probes, cfg.Servers)
 
 	// 检查是否有错误
 	if cv.hasErrors() {
@@ -201,6 +208,43 @@ func (cv *ConfigValidator) validateReportInterval(interval int) {
 	}
 }
 
+// validateHeartbeat 验证心跳配置；未填写的间隔与次数使用默认值
+func (cv *ConfigValidator) validateHeartbeat(hb config.HeartbeatConfig) {
+	if hb.Interval != 0 && hb.Interval < 5 {
+		cv.addError("Heartbeat.Interval", strconv.Itoa(hb.Interval), "心跳间隔最小值为5秒", "error")
+	} else if hb.Interval > 120 {
+		cv.addError("Heartbeat.Interval", strconv.Itoa(hb.Interval), "心跳间隔过长(>120秒)可能导致断线发现延迟", "warning")
+	}
+	if hb.MaxMissedPongs < 0 {
+		cv.addError("Heartbeat.MaxMissedPongs", strconv.Itoa(hb.MaxMissedPongs), "允许丢失的心跳次数不能为负数", "error")
+	}
+	switch strings.ToLower(hb.OfflinePolicy) {
+	case "", OfflinePolicyReport, OfflinePolicyHeartbeat:
+	default:
+		cv.addError("Heartbeat.OfflinePolicy", hb.OfflinePolicy, fmt.Sprintf("无效的离线判定策略，有效值: %s, %s", OfflinePolicyReport, OfflinePolicyHeartbeat), "error")
+	}
+}
+
+// validateMaxMessageSize 验证上报消息大小上限；未填写时使用默认值
+func (cv *ConfigValidator) validateMaxMessageSize(size int) {
+	if size < 0 {
+		cv.addError("MaxMessageSize", strconv.Itoa(size), "上报消息大小上限不能为负数", "error")
+	} else if size != 0 && size < 16 {
+		cv.addError("MaxMessageSize", strconv.Itoa(size), "上报消息大小上限最小值为16KB，过小会导致完整上报被拒绝", "error")
+	} else if size > 10240 {
+		cv.addError("MaxMessageSize", strconv.Itoa(size), "上报消息大小上限过大(>10MB)会增加面板内存占用", "warning")
+	}
+}
+
+// validateRAMPercentMode 验证内存占用计算方式
+func (cv *ConfigValidator) validateRAMPercentMode(mode string) {
+	switch strings.ToLower(mode) {
+	case "", model.RAMPercentUsed, model.RAMPercentExcludeCache:
+	default:
+		cv.addError("RAMPercentMode", mode, fmt.Sprintf("无效的内存占用计算方式，有效值: %s, %s", model.RAMPercentUsed, model.RAMPercentExcludeCache), "error")
+	}
+}
+
 // validateLogConfig 验证日志配置
 func (cv *ConfigValidator) validateLogConfig(logPath, logLevel string) {
 	// 验证日志路径
@@ -279,9 +323,11 @@ func (cv *ConfigValidator) validateServers(servers []*config.ServerConfig) {
 			}
 		}
 
-		// 验证密钥
+		// 验证密钥（node_exporter 模式不经过认证，可不填）
 		if server.Secret == "" {
-			cv.addError(prefix+".Secret", server.Secret, "服务器密钥不能为空", "error")
+			if strings.ToLower(server.Mode) != ServerModeNodeExporter {
+				cv.addError(prefix+".Secret", server.Secret, "服务器密钥不能为空", "error")
+			}
 		} else {
 			if len(server.Secret) < 8 {
 				cv.addError(prefix+".Secret", "***", "密钥长度应至少8位以确保安全性", "warning")
@@ -302,6 +348,99 @@ func (cv *ConfigValidator) validateServers(servers []*config.ServerConfig) {
 		if server.Group != "" && len(server.Group) > 50 {
 			cv.addError(prefix+".Group", server.Group, "分组名称过长(>50字符)", "warning")
 		}
+
+		// 验证数据来源
+		cv.validateServerMode(prefix, server)
+	}
+}
+
+// validateServerMode 验证服务器数据来源配置
+func (cv *ConfigValidator) validateServerMode(prefix string, server *config.ServerConfig) {
+	mode := strings.ToLower(server.Mode)
+	if mode != "" && mode != ServerModePush && mode != ServerModePull && mode != ServerModeNodeExporter {
+		cv.addError(prefix+".Mode", server.Mode, "数据来源只能为 push、pull 或 node_exporter", "error")
+		return
+	}
+
+	if mode != ServerModePull && mode != ServerModeNodeExporter {
+		if server.PullUrl != "" {
+			cv.addError(prefix+".PullUrl", server.PullUrl, "push 模式下 pullUrl 不会生效", "warning")
+		}
+		return
+	}
+
+	if server.PullUrl == "" {
+		cv.addError(prefix+".PullUrl", server.PullUrl, mode+" 模式必须配置 pullUrl", "error")
+	} else if u, err := url.Parse(server.PullUrl); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		cv.addError(prefix+".PullUrl", server.PullUrl, "pullUrl 必须是有效的 http:// 或 https:// 地址", "error")
+	}
+
+	if server.PullInterval < 0 {
+		cv.addError(prefix+".PullInterval", strconv.Itoa(server.PullInterval), "拉取间隔不能为负数", "error")
+	} else if server.PullInterval > 300 {
+		cv.addError(prefix+".PullInterval", strconv.Itoa(server.PullInterval), "拉取间隔过长(>300秒)可能导致服务器被判定为离线", "warning")
+	}
+}
+
+// validateProbes 验证网络探测任务；未填写的周期、次数与超时使用默认值
+func (cv *ConfigValidator) validateProbes(probes []*config.ProbeConfig, servers []*config.ServerConfig) {
+	modes := make(map[string]string, len(servers))
+	for _, server := range servers {
+		modes[server.Id] = strings.ToLower(server.Mode)
+	}
+	names := make(map[string]bool, len(probes))
+
+	for i, probe := range probes {
+		prefix := fmt.Sprintf("Probes[%d]", i)
+
+		if probe.Name == "" {
+			cv.addError(prefix+".Name", probe.Name, "探测名称不能为空", "error")
+		} else if names[probe.Name] {
+			cv.addError(prefix+".Name", probe.Name, "探测名称重复", "error")
+		}
+		names[probe.Name] = true
+
+		typ := strings.ToLower(probe.Type)
+		if typ != "" && typ != model.ProbeTypeICMP && typ != model.ProbeTypeTCP {
+			cv.addError(prefix+".Type", probe.Type, "探测类型只能为 icmp 或 tcp", "error")
+		}
+		if probe.Host == "" {
+			cv.addError(prefix+".Host", probe.Host, "探测目标主机不能为空", "error")
+		}
+		if probe.Port < 0 || probe.Port > 65535 {
+			cv.addError(prefix+".Port", strconv.Itoa(probe.Port), "端口号应在1-65535之间", "error")
+		} else if typ == model.ProbeTypeTCP && probe.Port == 0 {
+			cv.addError(prefix+".Port", strconv.Itoa(probe.Port), "tcp 探测必须配置端口", "error")
+		}
+
+		if probe.Interval < 0 {
+			cv.addError(prefix+".Interval", strconv.Itoa(probe.Interval), "探测周期不能为负数", "error")
+		} else if probe.Interval > 0 && probe.Interval < 10 {
+			cv.addError(prefix+".Interval", strconv.Itoa(probe.Interval), "探测周期过短(<10秒)会增加目标的负担", "warning")
+		}
+		if probe.Count < 0 || probe.Count > maxProbeCount {
+			cv.addError(prefix+".Count", strconv.Itoa(probe.Count), fmt.Sprintf("每轮探测次数应在1-%d之间", maxProbeCount), "error")
+		}
+		if probe.TimeoutMs < 0 {
+			cv.addError(prefix+".TimeoutMs", strconv.Itoa(probe.TimeoutMs), "探测超时不能为负数", "error")
+		}
+		if probe.Interval >= 0 && probe.Count >= 0 && probe.Count <= maxProbeCount && probe.TimeoutMs >= 0 {
+			interval := cmp.Or(probe.Interval, defaultProbeInterval)
+			count := cmp.Or(probe.Count, defaultProbeCount)
+			timeoutMs := cmp.Or(probe.TimeoutMs, defaultProbeTimeoutMs)
+			if count*(timeoutMs+probeSpacingMs) > interval*1000 {
+				cv.addError(prefix+".Interval", strconv.Itoa(interval), "每轮探测的最长耗时超过探测周期，建议增大周期或减少次数", "warning")
+			}
+		}
+
+		for _, id := range probe.Servers {
+			mode, ok := modes[id]
+			if !ok {
+				cv.addError(prefix+".Servers", id, "服务器ID不存在", "error")
+			} else if mode == ServerModePull || mode == ServerModeNodeExporter {
+				cv.addError(prefix+".Servers", id, "pull 与 node_exporter 模式的服务器无法执行探测", "warning")
+			}
+		}
 	}
 }
 
@@ -458,11 +597,52 @@ func applyDefaultValues(cfg *config.DashboardConfig) {
 	if cfg.LogLevel == "" {
 		cfg.LogLevel = "info"
 	}
+	if cfg.Heartbeat.Interval == 0 {
+		cfg.Heartbeat.Interval = defaultHeartbeatInterval
+	}
+	if cfg.Heartbeat.MaxMissedPongs == 0 {
+		cfg.Heartbeat.MaxMissedPongs = defaultMaxMissedPongs
+	}
+	if cfg.MaxMessageSize == 0 {
+		cfg.MaxMessageSize = defaultMaxMessageSize
+	}
+	cfg.Heartbeat.OfflinePolicy = strings.ToLower(cfg.Heartbeat.OfflinePolicy)
+	if cfg.Heartbeat.OfflinePolicy == "" {
+		cfg.Heartbeat.OfflinePolicy = OfflinePolicyReport
+	}
+	cfg.RAMPercentMode = strings.ToLower(cfg.RAMPercentMode)
+	if cfg.RAMPercentMode == "" {
+		cfg.RAMPercentMode = model.RAMPercentUsed
+	}
 
 	// 为服务器配置应用默认值
 	for _, server := range cfg.Servers {
 		if server.Group == "" {
 			server.Group = "DEFAULT"
 		}
+		server.Mode = strings.ToLower(server.Mode)
+		if server.Mode == "" {
+			server.Mode = ServerModePush
+		}
+		if (server.Mode == ServerModePull || server.Mode == ServerModeNodeExporter) && server.PullInterval == 0 {
+			server.PullInterval = 5
+		}
+	}
+
+	// 为网络探测任务应用默认值
+	for _, probe := range cfg.Probes {
+		probe.Type = strings.ToLower(probe.Type)
+		if probe.Type == "" {
+			probe.Type = model.ProbeTypeICMP
+		}
+		if probe.Interval == 0 {
+			probe.Interval = defaultProbeInterval
+		}
+		if probe.Count == 0 {
+			probe.Count = defaultProbeCount
+		}
+		if probe.TimeoutMs == 0 {
+			probe.TimeoutMs = defaultProbeTimeoutMs
+		}
 	}
 }
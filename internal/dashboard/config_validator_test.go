@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/ruanun/simple-server-status/internal/dashboard/config"
+	"github.com/ruanun/simple-server-status/pkg/model"
 )
 
 // TestNewConfigValidator 测试创建配置验证器
@@ -133,6 +134,68 @@ func TestValidateReportInterval(t *testing.T) {
 	}
 }
 
+// TestValidateHeartbeat 测试心跳配置验证
+func TestValidateHeartbeat(t *testing.T) {
+	tests := []struct {
+		name         string
+		heartbeat    config.HeartbeatConfig
+		wantErrorNum int
+	}{
+		{"未配置", config.HeartbeatConfig{}, 0},
+		{"正常配置", config.HeartbeatConfig{Interval: 10, MaxMissedPongs: 2, OfflinePolicy: "Heartbeat"}, 0},
+		{"间隔过小", config.HeartbeatConfig{Interval: 2}, 1},
+		{"间隔过长", config.HeartbeatConfig{Interval: 300}, 1}, // 警告
+		{"次数为负", config.HeartbeatConfig{MaxMissedPongs: -1}, 1},
+		{"无效策略", config.HeartbeatConfig{OfflinePolicy: "socket"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cv := NewConfigValidator()
+			cv.validateHeartbeat(tt.heartbeat)
+			if len(cv.errors) != tt.wantErrorNum {
+				t.Errorf("期望 %d 个错误，实际 %d 个: %+v", tt.wantErrorNum, len(cv.errors), cv.errors)
+			}
+		})
+	}
+}
+
+// TestValidateMaxMessageSize 测试上报消息大小上限验证
+func TestValidateMaxMessageSize(t *testing.T) {
+	for size, wantErrorNum := range map[int]int{0: 0, 256: 0, 16: 0, 8: 1, -1: 1, 20480: 1} {
+		cv := NewConfigValidator()
+		cv.validateMaxMessageSize(size)
+		if len(cv.errors) != wantErrorNum {
+			t.Errorf("大小 %d: 期望 %d 个错误，实际 %d 个: %+v", size, wantErrorNum, len(cv.errors), cv.errors)
+		}
+	}
+}
+
+// TestRAMPercentMode 测试内存占用计算方式的验证与计算
+func TestRAMPercentMode(t *testing.T) {
+	for mode, wantErrorNum := range map[string]int{"": 0, "used": 0, "EXCLUDE_CACHE": 0, "free": 1} {
+		cv := NewConfigValidator()
+		cv.validateRAMPercentMode(mode)
+		if len(cv.errors) != wantErrorNum {
+			t.Errorf("方式 '%s': 期望 %d 个错误，实际 %d 个", mode, wantErrorNum, len(cv.errors))
+		}
+	}
+
+	// 页缓存占用较多：已用 90%，其中 60% 可回收
+	mem := &model.VirtualMemoryInfo{Total: 1000, Used: 900, UsedPercent: 90, Available: 700, Cached: 600}
+	if got := mem.Percent(model.RAMPercentUsed); got != 90 {
+		t.Errorf("used = %v; want 90", got)
+	}
+	if got := mem.Percent(model.RAMPercentExcludeCache); got != 30 {
+		t.Errorf("exclude_cache = %v; want 30", got)
+	}
+	// 旧版本 Agent 未上报 available 时使用 usedPercent
+	legacy := &model.VirtualMemoryInfo{Total: 1000, Used: 900, UsedPercent: 90}
+	if got := legacy.Percent(model.RAMPercentExcludeCache); got != 90 {
+		t.Errorf("缺少 available 时 = %v; want 90", got)
+	}
+}
+
 // TestValidateLogConfig 测试日志配置验证
 func TestValidateLogConfig(t *testing.T) {
 	// 创建临时目录用于测试
@@ -262,6 +325,90 @@ func TestValidateServers(t *testing.T) {
 	}
 }
 
+// TestValidateServerMode 测试服务器数据来源验证
+func TestValidateServerMode(t *testing.T) {
+	tests := []struct {
+		name         string
+		server       *config.ServerConfig
+		wantErrorNum int
+		wantLevel    string
+	}{
+		{"默认推送", &config.ServerConfig{}, 0, ""},
+		{"显式推送", &config.ServerConfig{Mode: "push"}, 0, ""},
+		{"有效拉取", &config.ServerConfig{Mode: "pull", PullUrl: "http://10.0.0.5:8901/server-info"}, 0, ""},
+		{"大写拉取", &config.ServerConfig{Mode: "PULL", PullUrl: "https://dmz.example.com/server-info"}, 0, ""},
+		{"未知模式", &config.ServerConfig{Mode: "poll"}, 1, "error"},
+		{"拉取缺少地址", &config.ServerConfig{Mode: "pull"}, 1, "error"},
+		{"拉取地址协议错误", &config.ServerConfig{Mode: "pull", PullUrl: "ws://10.0.0.5:8901"}, 1, "error"},
+		{"拉取间隔为负", &config.ServerConfig{Mode: "pull", PullUrl: "http://10.0.0.5:8901", PullInterval: -1}, 1, "error"},
+		{"拉取间隔过长", &config.ServerConfig{Mode: "pull", PullUrl: "http://10.0.0.5:8901", PullInterval: 600}, 1, "warning"},
+		{"推送模式配置了地址", &config.ServerConfig{PullUrl: "http://10.0.0.5:8901"}, 1, "warning"},
+		{"有效 node_exporter", &config.ServerConfig{Mode: "node_exporter", PullUrl: "http://10.0.0.6:9100/metrics"}, 0, ""},
+		{"node_exporter 缺少地址", &config.ServerConfig{Mode: "node_exporter"}, 1, "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cv := NewConfigValidator()
+			cv.validateServerMode("Servers[0]", tt.server)
+			if len(cv.errors) != tt.wantErrorNum {
+				t.Fatalf("期望 %d 个错误，实际 %d 个: %+v", tt.wantErrorNum, len(cv.errors), cv.errors)
+			}
+			if tt.wantErrorNum > 0 && cv.errors[0].Level != tt.wantLevel {
+				t.Errorf("期望错误级别 %s，实际 %s", tt.wantLevel, cv.errors[0].Level)
+			}
+		})
+	}
+}
+
+// TestValidateProbes 测试网络探测任务验证
+func TestValidateProbes(t *testing.T) {
+	servers := []*config.ServerConfig{
+		{Id: "web-01", Mode: "push"},
+		{Id: "dmz-01", Mode: "pull"},
+	}
+	tests := []struct {
+		name         string
+		probe        *config.ProbeConfig
+		wantErrorNum int
+		wantLevel    string
+	}{
+		{"默认值", &config.ProbeConfig{Name: "gw", Host: "10.0.0.1"}, 0, ""},
+		{"tcp 探测", &config.ProbeConfig{Name: "db", Type: "TCP", Host: "db.internal", Port: 5432, Servers: []string{"web-01"}}, 0, ""},
+		{"缺少名称", &config.ProbeConfig{Host: "10.0.0.1"}, 1, "error"},
+		{"缺少目标", &config.ProbeConfig{Name: "gw"}, 1, "error"},
+		{"未知类型", &config.ProbeConfig{Name: "gw", Type: "udp", Host: "10.0.0.1"}, 1, "error"},
+		{"tcp 缺少端口", &config.ProbeConfig{Name: "db", Type: "tcp", Host: "10.0.0.2"}, 1, "error"},
+		{"端口超出范围", &config.ProbeConfig{Name: "gw", Host: "10.0.0.1", Port: 70000}, 1, "error"},
+		{"次数过多", &config.ProbeConfig{Name: "gw", Host: "10.0.0.1", Count: 500}, 1, "error"},
+		{"周期过短", &config.ProbeConfig{Name: "gw", Host: "10.0.0.1", Interval: 5, Count: 3}, 1, "warning"},
+		{"耗时超过周期", &config.ProbeConfig{Name: "gw", Host: "10.0.0.1", Interval: 10, Count: 20}, 1, "warning"},
+		{"未知服务器", &config.ProbeConfig{Name: "gw", Host: "10.0.0.1", Servers: []string{"web-02"}}, 1, "error"},
+		{"拉取模式的服务器", &config.ProbeConfig{Name: "gw", Host: "10.0.0.1", Servers: []string{"dmz-01"}}, 1, "warning"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cv := NewConfigValidator()
+			cv.validateProbes([]*config.ProbeConfig{tt.probe}, servers)
+			if len(cv.errors) != tt.wantErrorNum {
+				t.Fatalf("期望 %d 个错误，实际 %d 个: %+v", tt.wantErrorNum, len(cv.errors), cv.errors)
+			}
+			if tt.wantErrorNum > 0 && cv.errors[0].Level != tt.wantLevel {
+				t.Errorf("期望错误级别 %s，实际 %s", tt.wantLevel, cv.errors[0].Level)
+			}
+		})
+	}
+
+	t.Run("名称重复", func(t *testing.T) {
+		cv := NewConfigValidator()
+		cv.validateProbes([]*config.ProbeConfig{{Name: "gw", Host: "10.0.0.1"}, {Name: "gw", Host: "10.0.0.2"}}, servers)
+		if len(cv.errors) != 1 || cv.errors[0].Field != "Probes[1].Name" {
+			t.Errorf("errors = %+v", cv.errors)
+		}
+	})
+}
+
 // TestValidateConfig 测试完整配置验证
 func TestValidateConfig(t *testing.T) {
 	tests := []struct {
@@ -355,8 +502,33 @@ func TestApplyDefaultValues(t *testing.T) {
 		{"默认上报间隔", cfg.ReportTimeIntervalMax, 30},
 		{"默认日志路径", cfg.LogPath, "./.logs/sss-dashboard.log"},
 		{"默认日志级别", cfg.LogLevel, "info"},
+		{"默认内存占用计算方式", cfg.RAMPercentMode, "used"},
+		{"默认心跳间隔", cfg.Heartbeat.Interval, 15},
+		{"默认允许丢失心跳次数", cfg.Heartbeat.MaxMissedPongs, 3},
+		{"默认离线判定策略", cfg.Heartbeat.OfflinePolicy, "report"},
+		{"默认上报消息大小上限", cfg.MaxMessageSize, 256},
 	}
 
+	t.Run("服务器数据来源默认值", func(t *testing.T) {
+		pushServer := &config.ServerConfig{Id: "a"}
+		pullServer := &config.ServerConfig{Id: "b", Mode: "Pull", PullUrl: "http://10.0.0.5:8901"}
+		applyDefaultValues(&config.DashboardConfig{Servers: []*config.ServerConfig{pushServer, pullServer}})
+		if pushServer.Mode != ServerModePush || pushServer.PullInterval != 0 {
+			t.Errorf("推送服务器默认值错误: %+v", pushServer)
+		}
+		if pullServer.Mode != ServerModePull || pullServer.PullInterval != 5 {
+			t.Errorf("拉取服务器默认值错误: %+v", pullServer)
+		}
+	})
+
+	t.Run("网络探测默认值", func(t *testing.T) {
+		probe := &config.ProbeConfig{Name: "gw", Type: "ICMP", Host: "10.0.0.1"}
+		applyDefaultValues(&config.DashboardConfig{Probes: []*config.ProbeConfig{probe}})
+		if probe.Type != "icmp" || probe.Interval != 60 || probe.Count != 5 || probe.TimeoutMs != 1000 {
+			t.Errorf("探测默认值错误: %+v", probe)
+		}
+	})
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if tt.got != tt.expected {
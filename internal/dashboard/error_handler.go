@@ -288,6 +288,17 @@ func NewWebSocketError(message, details string) *AppError {
 	}
 }
 
+// NewNetworkError 创建网络错误
+func NewNetworkError(message, details string) *AppError {
+	return &AppError{
+		Type:      ErrorTypeNetwork,
+		Code:      "NETWORK_ERROR",
+		Message:   message,
+		Details:   details,
+		Timestamp: time.Now(),
+	}
+}
+
 // NewConfigError 创建配置错误
 func NewConfigError(message, details string) *AppError {
 	return &AppError{
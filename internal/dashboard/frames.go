@@ -0,0 +1,93 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/olahol/melody"
+	"github.com/ruanun/simple-server-status/pkg/model"
+)
+
+// frameTypeLegacy 未分帧的完整 ServerInfo（旧版本 Agent）
+const frameTypeLegacy = "legacy"
+
+// negotiateCodec 根据握手请求头选择编码，未请求或不支持时使用 JSON
+func negotiateCodec(header http.Header) model.Codec {
+	if codec, err := model.NewCodec(header.Get(model.EncodingHeader)); err == nil {
+		return codec
+	}
+	codec, _ := model.NewCodec(model.EncodingJSON)
+	return codec
+}
+
+// decodeReport 按连接协商的编码解析 Agent 上报的消息
+// 支持分帧上报（完整帧/指标帧）和旧版本的完整 ServerInfo；指标帧使用当前状态中的硬件与系统信息补全
+func (wsm *WebSocketManager) decodeReport(serverID string, msg []byte, codec model.Codec) (*model.ServerInfo, *model.ReportFrame, error) {
+	var frame model.ReportFrame
+	if err := codec.Unmarshal(msg, &frame); err != nil {
+		return nil, nil, err
+	}
+
+	switch frame.Type {
+	case "":
+		var info model.ServerInfo
+		if err := codec.Unmarshal(msg, &info); err != nil {
+			return nil, nil, err
+		}
+		frame.Type = frameTypeLegacy
+		return &info, &frame, nil
+	case model.FrameTypeInventory:
+		if frame.Data == nil {
+			return nil, nil, fmt.Errorf("完整帧缺少 data")
+		}
+		return frame.Data, &frame, nil
+	case model.FrameTypeMetrics:
+		if frame.Data == nil {
+			return nil, nil, fmt.Errorf("指标帧缺少 data")
+		}
+		if current, ok := wsm.serverStatus.Get(serverID); ok {
+			frame.Data.MergeInventory(current)
+		}
+		frame.Data.FillEmpty()
+		return frame.Data, &frame, nil
+	default:
+		return nil, nil, fmt.Errorf("未知的帧类型: %s", frame.Type)
+	}
+}
+
+// recordFrame 记录分帧统计，并检查指标帧与已接收的完整帧是否对应
+// 不对应时（如完整帧未送达）返回 true，调用方应要求 Agent 重新同步
+func (wsm *WebSocketManager) recordFrame(serverID string, frame *model.ReportFrame) bool {
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
+
+	connInfo := wsm.connections[serverID]
+	switch frame.Type {
+	case model.FrameTypeInventory:
+		wsm.inventoryFrames++
+		if connInfo != nil {
+			connInfo.InventoryHash = frame.Hash
+			connInfo.resyncPending = false
+		}
+	case model.FrameTypeMetrics:
+		wsm.metricFrames++
+		if connInfo != nil && connInfo.InventoryHash != frame.Hash && !connInfo.resyncPending {
+			wsm.logger.Warnf("服务器 %s 的指标帧与完整帧不对应 (hash: %s, 已接收: %s)，要求重新同步",
+				serverID, frame.Hash, connInfo.InventoryHash)
+			connInfo.resyncPending = true
+			return true
+		}
+	default:
+		wsm.legacyFrames++
+	}
+	return false
+}
+
+// requestResync 要求 Agent 下一帧发送完整帧；调用方不能持有 wsm.mu
+func (wsm *WebSocketManager) requestResync(s *melody.Session, serverID string) {
+	msg, _ := json.Marshal(&model.AgentCommand{Type: model.CommandResync})
+	if err := s.Write(msg); err != nil {
+		wsm.logger.Warnf("发送重新同步指令失败 - ServerID: %s, Error: %v", serverID, err)
+	}
+}
@@ -0,0 +1,421 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/ruanun/simple-server-status/internal/dashboard/global/constant"
+	"github.com/ruanun/simple-server-status/pkg/model"
+)
+
+// dialReport 以 Agent 身份连接上报 WebSocket
+func dialReport(t *testing.T, srv *httptest.Server, frames bool) (*websocket.Conn, *http.Response) {
+	t.Helper()
+	return dialReportWith(t, srv, websocket.DefaultDialer, frames, "")
+}
+
+// dialReportWith 使用指定拨号器和编码连接上报 WebSocket
+func dialReportWith(t *testing.T, srv *httptest.Server, dialer *websocket.Dialer, frames bool, encoding string) (*websocket.Conn, *http.Response) {
+	t.Helper()
+	header := http.Header{}
+	if encoding != "" {
+		header.Set(model.EncodingHeader, encoding)
+	}
+	header.Set(constant.HeaderSecret, "secret-123456")
+	header.Set(constant.HeaderId, "web-01")
+	if frames {
+		header.Set(model.ProtocolHeader, model.ProtocolFrames)
+	}
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws-report"
+	conn, resp, err := dialer.Dial(url, header)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn, resp
+}
+
+// waitStats 等待面板处理完指定数量的消息
+func waitStats(t *testing.T, wsm *WebSocketManager, key string, want int64) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if wsm.GetStats()[key].(int64) >= want {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("%s 未达到 %d: %v", key, want, wsm.GetStats())
+}
+
+// TestReportFrames 测试分帧上报的协商与合并
+func TestReportFrames(t *testing.T) {
+	wsm, statusMap, r := newTestWebSocketManager(t)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	conn, resp := dialReport(t, srv, true)
+	if resp.Header.Get(model.ProtocolHeader) != model.ProtocolFrames {
+		t.Fatal("握手响应未确认分帧上报")
+	}
+
+	inventory := `{"type":"inventory","hash":"h1","data":{"hostInfo":{"platform":"debian","platformVersion":"12","uptime":10},` +
+		`"cpuInfo":{"percent":5,"info":["Xeon x 2 "]},"virtualMemoryInfo":{},"swapMemoryInfo":{},` +
+		`"diskInfo":{"total":100,"used":10,"usedPercent":10,"partitions":[{"mountPoint":"/","total":100}]},"networkInfo":{}}}`
+	metrics := `{"type":"metrics","hash":"h1","data":{"hostInfo":{"uptime":20},"cpuInfo":{"percent":42},` +
+		`"diskInfo":{"total":100,"used":20,"usedPercent":20},"diskIO":[{"name":"sda","busyPercent":35},{"name":"sdb","busyPercent":88}],` +
+		`"aggregates":{"samples":5,"windowMs":5000,"metrics":{"cpu":{"min":3,"avg":20,"max":97,"p95":97}}}}}`
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(inventory)); err != nil {
+		t.Fatal(err)
+	}
+	waitStats(t, wsm, "inventory_frames", 1)
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(metrics)); err != nil {
+		t.Fatal(err)
+	}
+	waitStats(t, wsm, "metric_frames", 1)
+
+	info, ok := statusMap.Get("web-01")
+	if !ok {
+		t.Fatal("上报未写入状态")
+	}
+	// 指标来自指标帧，硬件与系统信息来自完整帧
+	if info.CpuInfo.Percent != 42 || info.HostInfo.Uptime != 20 || info.DiskInfo.UsedPercent != 20 {
+		t.Errorf("指标未更新: cpu=%v uptime=%d disk=%v", info.CpuInfo.Percent, info.HostInfo.Uptime, info.DiskInfo.UsedPercent)
+	}
+	if info.HostInfo.Platform != "debian" || len(info.CpuInfo.Info) != 1 || len(info.DiskInfo.Partitions) != 1 {
+		t.Errorf("硬件与系统信息未合并: %+v %+v", info.HostInfo, info.CpuInfo)
+	}
+	resp2 := model.NewRespServerInfo(info)
+	if resp2.Platform != "debian 12" || resp2.CpuPercent != 42 {
+		t.Errorf("展示数据错误: %+v", resp2)
+	}
+	if resp2.DiskIOBusy != 88 || len(resp2.HostInfo.DiskIO) != 2 {
+		t.Errorf("磁盘 I/O 错误: busy=%v io=%+v", resp2.DiskIOBusy, resp2.HostInfo.DiskIO)
+	}
+	// 实时展示使用最新采样，采样统计通过 API 返回
+	if resp2.Aggregates == nil || resp2.Aggregates.Samples != 5 || resp2.Aggregates.Metrics[model.MetricCPU].Max != 97 {
+		t.Errorf("采样统计错误: %+v", resp2.Aggregates)
+	}
+	if conn, ok := wsm.GetConnectionInfo("web-01"); !ok || conn.InventoryHash != "h1" {
+		t.Errorf("InventoryHash 未记录: %+v", conn)
+	}
+	if wsm.GetStats()["total_bytes"].(int64) != int64(len(inventory)+len(metrics)) {
+		t.Errorf("total_bytes 错误: %v", wsm.GetStats())
+	}
+}
+
+// TestReportFrames_Resync 测试指标帧与已接收的完整帧不对应时要求 Agent 重新同步
+func TestReportFrames_Resync(t *testing.T) {
+	wsm, _, r := newTestWebSocketManager(t)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	conn, _ := dialReport(t, srv, true)
+	inventory := `{"type":"inventory","hash":"h1","data":{"hostInfo":{"platform":"debian"},"cpuInfo":{},"diskInfo":{}}}`
+	metrics := `{"type":"metrics","hash":"h2","data":{"cpuInfo":{"percent":42}}}`
+	for _, msg := range []string{inventory, metrics, metrics} {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitStats(t, wsm, "metric_frames", 2)
+
+	// 连接建立时还会下发探测任务，只统计重新同步指令
+	resyncs := 0
+	_ = conn.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		var cmd model.AgentCommand
+		if json.Unmarshal(data, &cmd) == nil && cmd.Type == model.CommandResync {
+			resyncs++
+		}
+	}
+	// 收到完整帧前只要求一次
+	if resyncs != 1 {
+		t.Errorf("重新同步指令 = %d; want 1", resyncs)
+	}
+}
+
+// TestWebSocketPong 测试 pong 按载荷与 ping 对应，发送新的 ping 之后才到达的 pong 不计入往返时间
+func TestWebSocketPong(t *testing.T) {
+	wsm, _, r := newTestWebSocketManager(t)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	conn, _ := dialReport(t, srv, true)
+	pings := make(chan string, 4)
+	conn.SetPingHandler(func(payload string) error {
+		pings <- payload
+		return nil
+	})
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	// 读取协程按顺序处理消息与 pong：每次 pong 之后发送一条上报，处理完上报即处理完 pong
+	report := func(msg string) {
+		t.Helper()
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	nextPing := func() string {
+		t.Helper()
+		wsm.checkHeartbeats()
+		select {
+		case payload := <-pings:
+			return payload
+		case <-time.After(2 * time.Second):
+			t.Fatal("未收到 ping")
+			return ""
+		}
+	}
+	pong := func(payload string) {
+		t.Helper()
+		if err := conn.WriteControl(websocket.PongMessage, []byte(payload), time.Now().Add(time.Second)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pongsReceived := func() int64 {
+		return wsm.GetConnectionLinks()["web-01"].Dashboard.PongsReceived
+	}
+
+	// 第一条上报之后面板接管 pong 处理
+	report(`{"type":"inventory","hash":"h1","data":{"hostInfo":{},"cpuInfo":{},"diskInfo":{}}}`)
+	waitStats(t, wsm, "inventory_frames", 1)
+	metrics := `{"type":"metrics","hash":"h1","data":{"cpuInfo":{"percent":1}}}`
+
+	first := nextPing()
+	second := nextPing()
+	if first == second {
+		t.Fatalf("ping 载荷应不同: %s", first)
+	}
+
+	pong(first)
+	report(metrics)
+	waitStats(t, wsm, "metric_frames", 1)
+	if got := pongsReceived(); got != 0 {
+		t.Errorf("过期的 pong 不应计入: PongsReceived = %d", got)
+	}
+
+	pong(second)
+	report(metrics)
+	waitStats(t, wsm, "metric_frames", 2)
+	if got := pongsReceived(); got != 1 {
+		t.Errorf("PongsReceived = %d; want 1", got)
+	}
+}
+
+// newFullReportInfo 开启全部采集器的多核服务器的完整上报
+func newFullReportInfo() *model.ServerInfo {
+	info := &model.ServerInfo{
+		HostInfo: &model.HostInfo{Platform: "ubuntu", PlatformVersion: "22.04", KernelVersion: "5.15.0-105-generic", KernelArch: "x86_64", Uptime: 864000},
+		CpuInfo: &model.CpuInfo{
+			Percent: 37.5, Mhz: 2450, LogicalCores: 64, PhysicalCores: 32,
+			Info:  []string{"AMD EPYC 7543 32-Core Processor x 64 "},
+			Times: &model.CpuTimes{User: 30, System: 5, Iowait: 1.5, Idle: 62.5, Steal: 1},
+		},
+		VirtualMemoryInfo: &model.VirtualMemoryInfo{Total: 256 << 30, Used: 180 << 30, UsedPercent: 70.31},
+		SwapMemoryInfo:    &model.SwapMemoryInfo{Total: 8 << 30, Used: 1 << 30, UsedPercent: 12.5},
+		DiskInfo:          &model.DiskInfo{Total: 40 << 40, Used: 22 << 40, UsedPercent: 55},
+		NetworkInfo:       &model.NetworkInfo{NetInSpeed: 125 << 20, NetOutSpeed: 80 << 20},
+		Processes:         &model.ProcessInfo{Total: 1843, Running: 12, Threads: 9120},
+		Sensors:           &model.SensorInfo{},
+	}
+	for i := 0; i < 64; i++ {
+		info.CpuInfo.PerCore = append(info.CpuInfo.PerCore, 37.5+float64(i%7)*3.21)
+	}
+	for i := 0; i < 24; i++ {
+		info.DiskInfo.Partitions = append(info.DiskInfo.Partitions, &model.Partition{
+			MountPoint: fmt.Sprintf("/var/lib/kubelet/pods/%08x/volumes/csi/pvc-%04d/mount", i*2654435761, i), Fstype: "ext4",
+			Total: 500 << 30, Used: 275 << 30, Free: 225 << 30, UsedPercent: 55, InodesTotal: 32768000, InodesUsed: 1048576, InodesUsedPercent: 3.2,
+		})
+	}
+	for i := 0; i < 8; i++ {
+		info.DiskIO = append(info.DiskIO, &model.DiskIO{Name: fmt.Sprintf("nvme%dn1", i), ReadSpeed: 52 << 20, WriteSpeed: 31 << 20, ReadIOPS: 1520.5, WriteIOPS: 980.25, AwaitMs: 0.42, BusyPercent: 38.7})
+	}
+	for i := 0; i < 16; i++ {
+		info.NetworkInfo.Interfaces = append(info.NetworkInfo.Interfaces, &model.NetInterface{Name: fmt.Sprintf("veth%07x", i*97531), InSpeed: 1 << 20, OutSpeed: 2 << 20, InTransfer: 1 << 40, OutTransfer: 2 << 40, InPackets: 12000, OutPackets: 9000, OperState: "up"})
+	}
+	for i := 0; i < 10; i++ {
+		stat := &model.ProcessStat{Pid: int32(1000 + i), Name: "java", User: "app", CPUPercent: 250.5, RSS: 8 << 30, MemPercent: 3.1,
+			Cmdline: "/usr/lib/jvm/java-17-openjdk/bin/java -Xms8g -Xmx8g -XX:+UseG1GC -Dspring.profiles.active=prod -jar /opt/app/service.jar"}
+		info.Processes.TopCPU = append(info.Processes.TopCPU, stat)
+		info.Processes.TopRSS = append(info.Processes.TopRSS, stat)
+	}
+	for i := 0; i < 32; i++ {
+		info.Sensors.Temperatures = append(info.Sensors.Temperatures, &model.TemperatureSensor{Name: fmt.Sprintf("k10temp_tccd%d", i), Celsius: 61.25, High: 90, Critical: 95})
+	}
+	for i := 0; i < 10; i++ {
+		info.Checks = append(info.Checks, &model.CheckResult{Name: fmt.Sprintf("api-%d", i), Type: model.CheckTypeHTTP, Target: fmt.Sprintf("https://api-%d.example.com/healthz", i),
+			Status: model.CheckStatusOK, LatencyMs: 12.5, StatusCode: 200, CheckedAt: 1700000000})
+	}
+	return info
+}
+
+// TestReportFrames_FullFrame 测试开启全部采集器时的完整帧不超过默认消息大小上限
+func TestReportFrames_FullFrame(t *testing.T) {
+	wsm, statusMap, r := newTestWebSocketManager(t)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	conn, _ := dialReport(t, srv, true)
+	data, err := json.Marshal(&model.ReportFrame{Type: model.FrameTypeInventory, Hash: "full", Data: newFullReportInfo()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 此类完整帧约 20KB，超过早期 10KB 的固定上限
+	if len(data) <= 10*1024 || int64(len(data)) >= wsm.maxMessageSize {
+		t.Fatalf("完整帧大小 = %d; want 10KB 到 %d 之间", len(data), wsm.maxMessageSize)
+	}
+	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		t.Fatal(err)
+	}
+	waitStats(t, wsm, "inventory_frames", 1)
+
+	stored, ok := statusMap.Get("web-01")
+	if !ok || len(stored.DiskInfo.Partitions) != 24 || len(stored.CpuInfo.PerCore) != 64 || len(stored.Checks) != 10 {
+		t.Errorf("完整帧未写入状态: %+v", stored)
+	}
+}
+
+// TestReportFrames_Legacy 测试旧版本 Agent 的完整 ServerInfo 上报
+func TestReportFrames_Legacy(t *testing.T) {
+	wsm, statusMap, r := newTestWebSocketManager(t)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	conn, resp := dialReport(t, srv, false)
+	if resp.Header.Get(model.ProtocolHeader) != "" {
+		t.Error("未请求分帧时不应确认")
+	}
+
+	info := newLegacyInfo()
+	data, _ := json.Marshal(info)
+	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		t.Fatal(err)
+	}
+	waitStats(t, wsm, "legacy_frames", 1)
+
+	stored, ok := statusMap.Get("web-01")
+	if !ok || stored.CpuInfo.Percent != 33 || stored.HostInfo.Platform != "alpine" {
+		t.Errorf("旧格式上报未生效: %+v", stored)
+	}
+}
+
+func newLegacyInfo() *model.ServerInfo {
+	info := &model.ServerInfo{CpuInfo: &model.CpuInfo{Percent: 33}, HostInfo: &model.HostInfo{Platform: "alpine"}}
+	info.FillEmpty()
+	return info
+}
+
+// TestDecodeReport 测试上报消息解析
+func TestDecodeReport(t *testing.T) {
+	wsm, _, _ := newTestWebSocketManager(t)
+	codec, _ := model.NewCodec(model.EncodingJSON)
+
+	tests := []struct {
+		name     string
+		msg      string
+		wantType string
+		wantErr  bool
+	}{
+		{"完整帧", `{"type":"inventory","hash":"h","data":{"cpuInfo":{"percent":1}}}`, model.FrameTypeInventory, false},
+		{"无完整帧时的指标帧", `{"type":"metrics","hash":"h","data":{"cpuInfo":{"percent":1}}}`, model.FrameTypeMetrics, false},
+		{"旧格式", `{"cpuInfo":{"percent":1}}`, frameTypeLegacy, false},
+		{"未知类型", `{"type":"delta","data":{}}`, "", true},
+		{"缺少 data", `{"type":"metrics","hash":"h"}`, "", true},
+		{"非 JSON", `not json`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, frame, err := wsm.decodeReport("web-01", []byte(tt.msg), codec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeReport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if frame.Type != tt.wantType || info.CpuInfo.Percent != 1 {
+				t.Errorf("结果错误: type=%s info=%+v", frame.Type, info)
+			}
+			if tt.wantType == model.FrameTypeMetrics && (info.HostInfo == nil || info.DiskInfo == nil) {
+				t.Error("指标帧未补全空对象")
+			}
+		})
+	}
+}
+
+// TestReportEncoding 测试二进制编码与压缩的协商
+func TestReportEncoding(t *testing.T) {
+	for _, encoding := range []string{model.EncodingMsgpack, model.EncodingCBOR} {
+		t.Run(encoding, func(t *testing.T) {
+			wsm, statusMap, r := newTestWebSocketManager(t)
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			dialer := *websocket.DefaultDialer
+			dialer.EnableCompression = true
+			conn, resp := dialReportWith(t, srv, &dialer, true, encoding)
+			if resp.Header.Get(model.EncodingHeader) != encoding {
+				t.Fatalf("握手响应未确认编码: %v", resp.Header)
+			}
+			if !strings.Contains(resp.Header.Get("Sec-Websocket-Extensions"), "permessage-deflate") {
+				t.Errorf("未协商压缩: %v", resp.Header)
+			}
+
+			codec, _ := model.NewCodec(encoding)
+			data, err := codec.Marshal(&model.ReportFrame{Type: model.FrameTypeInventory, Hash: "h1", Data: newLegacyInfo()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+				t.Fatal(err)
+			}
+			waitStats(t, wsm, "inventory_frames", 1)
+
+			stored, ok := statusMap.Get("web-01")
+			if !ok || stored.CpuInfo.Percent != 33 || stored.HostInfo.Platform != "alpine" {
+				t.Errorf("%s 上报未生效: %+v", encoding, stored)
+			}
+			if conn, _ := wsm.GetConnectionInfo("web-01"); conn.Encoding != encoding {
+				t.Errorf("Encoding = %s; want %s", conn.Encoding, encoding)
+			}
+		})
+	}
+
+	t.Run("不支持的编码回退到 JSON", func(t *testing.T) {
+		wsm, _, r := newTestWebSocketManager(t)
+		srv := httptest.NewServer(r)
+		defer srv.Close()
+
+		_, resp := dialReportWith(t, srv, websocket.DefaultDialer, false, "protobuf")
+		if resp.Header.Get(model.EncodingHeader) != "" {
+			t.Error("不支持的编码不应确认")
+		}
+		deadline := time.Now().Add(time.Second)
+		for time.Now().Before(deadline) {
+			if conn, ok := wsm.GetConnectionInfo("web-01"); ok {
+				if conn.Encoding != model.EncodingJSON {
+					t.Errorf("Encoding = %s; want json", conn.Encoding)
+				}
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Error("连接未注册")
+	})
+}
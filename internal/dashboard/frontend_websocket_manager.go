@@ -18,6 +18,11 @@ type ServerStatusIterator interface {
 	IterBuffered() <-chan cmap.Tuple[string, *model.ServerInfo]
 }
 
+// OnlineChecker 在线状态判定接口
+type OnlineChecker interface {
+	IsOnline(info *model.ServerInfo) bool
+}
+
 // FrontendWebSocketManager 前端 WebSocket 管理器
 // 用于管理前端用户（浏览器）的连接，向前端推送服务器状态数据
 type FrontendWebSocketManager struct {
@@ -37,6 +42,7 @@ type FrontendWebSocketManager struct {
 	// 数据访问
 	serverStatus ServerStatusIterator
 	configAccess ConfigAccessor
+	online       OnlineChecker
 
 	// 统计信息
 	totalConnections    int64
@@ -54,6 +60,7 @@ func NewFrontendWebSocketManager(
 	errorHandler *ErrorHandler,
 	serverStatus ServerStatusIterator,
 	configAccess ConfigAccessor,
+	online OnlineChecker,
 ) *FrontendWebSocketManager {
 	ctx, cancel := context.WithCancel(context.Background())
 	m := melody.New()
@@ -69,6 +76,7 @@ func NewFrontendWebSocketManager(
 		errorHandler: errorHandler,
 		serverStatus: serverStatus,
 		configAccess: configAccess,
+		online:       online,
 	}
 
 	// 设置melody事件处理器
@@ -212,10 +220,11 @@ func (fwsm *FrontendWebSocketManager) getAllServerData() []*model.RespServerInfo
 	// 获取所有服务器状态信息并转换为RespServerInfo
 	var respServerInfos []*model.RespServerInfo
 	for item := range fwsm.serverStatus.IterBuffered() {
+		cfg := fwsm.configAccess.GetConfig()
 		info := model.NewRespServerInfo(item.Val)
+		info.RAMPercent = item.Val.VirtualMemoryInfo.Percent(cfg.RAMPercentMode)
 		// 检查是否在线
-		isOnline := time.Now().Unix()-info.LastReportTime <= int64(fwsm.configAccess.GetConfig().ReportTimeIntervalMax)
-		info.IsOnline = isOnline
+		info.IsOnline = fwsm.online.IsOnline(item.Val)
 		respServerInfos = append(respServerInfos, info)
 	}
 
@@ -2,7 +2,6 @@ package handler
 
 import (
 	"sort"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ruanun/simple-server-status/internal/dashboard/response"
@@ -15,6 +14,19 @@ import (
 type WebSocketStatsProvider interface {
 	GetAllServerId() []string
 	SessionLength() int
+	IsOnline(info *model.ServerInfo) bool
+	GetConnectionLinks() map[string]*model.ConnectionLink
+}
+
+// CheckHistoryProvider 可用性检查历史提供者接口
+type CheckHistoryProvider interface {
+	GetCheckHistory(serverID string) []*model.CheckHistory
+}
+
+// ProbeProvider 网络探测结果提供者接口
+type ProbeProvider interface {
+	GetProbeMatrix() *model.ProbeMatrix
+	GetProbeHistory(serverID, name string) []*model.ProbePoint
 }
 
 // ServerStatusMapProvider 服务器状态 Map 提供者接口
@@ -30,6 +42,8 @@ type ServerConfigMapProvider interface {
 
 // InitApi 初始化 API 路由
 // wsManager: Agent WebSocket 管理器，用于获取连接统计信息
+// checkHistory: 可用性检查历史提供者
+// probes: 网络探测结果提供者
 // configProvider: 配置提供者
 // logger: 日志记录器
 // serverStatusMap: 服务器状态 Map 提供者
@@ -38,6 +52,8 @@ type ServerConfigMapProvider interface {
 func InitApi(
 	r *gin.Engine,
 	wsManager WebSocketStatsProvider,
+	checkHistory CheckHistoryProvider,
+	probes ProbeProvider,
 	configProvider ConfigProvider,
 	logger LoggerProvider,
 	serverStatusMap ServerStatusMapProvider,
@@ -47,7 +63,22 @@ func InitApi(
 	group := r.Group("/api")
 
 	{
-		group.GET("/server/statusInfo", StatusInfo(serverStatusMap, configProvider))
+		group.GET("/server/statusInfo", StatusInfo(serverStatusMap, configProvider, wsManager))
+		//各服务器与面板之间的心跳链路质量
+		group.GET("/server/links", func(c *gin.Context) {
+			response.Success(c, wsManager.GetConnectionLinks())
+		})
+		//可用性检查历史
+		group.GET("/server/checks/:id", func(c *gin.Context) {
+			response.Success(c, checkHistory.GetCheckHistory(c.Param("id")))
+		})
+		//网络探测：各服务器到各目标的最近结果，以及一台服务器到一个目标的历史
+		group.GET("/probes/matrix", func(c *gin.Context) {
+			response.Success(c, probes.GetProbeMatrix())
+		})
+		group.GET("/probes/:id/:name", func(c *gin.Context) {
+			response.Success(c, probes.GetProbeHistory(c.Param("id"), c.Param("name")))
+		})
 		//统计信息
 		group.GET("/statistics", func(c *gin.Context) {
 			response.Success(c, gin.H{
@@ -65,15 +96,16 @@ func InitApi(
 }
 
 // StatusInfo 获取服务器状态信息（工厂函数）
-func StatusInfo(serverStatusMap ServerStatusMapProvider, configProvider ConfigProvider) gin.HandlerFunc {
+func StatusInfo(serverStatusMap ServerStatusMapProvider, configProvider ConfigProvider, wsManager WebSocketStatsProvider) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 处理数据结构并返回
 		values := lo.Values(serverStatusMap.Items())
 		//转换
 		baseServerInfos := lo.Map(values, func(item *model.ServerInfo, index int) *model.RespServerInfo {
+			cfg := configProvider.GetConfig()
 			info := model.NewRespServerInfo(item)
-			isOnline := time.Now().Unix()-info.LastReportTime <= int64(configProvider.GetConfig().ReportTimeIntervalMax)
-			info.IsOnline = isOnline
+			info.RAMPercent = item.VirtualMemoryInfo.Percent(cfg.RAMPercentMode)
+			info.IsOnline = wsManager.IsOnline(item)
 			return info
 		})
 		sort.Slice(baseServerInfos, func(i, j int) bool {
@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruanun/simple-server-status/internal/dashboard/global/constant"
+	"github.com/ruanun/simple-server-status/internal/dashboard/response"
+	"github.com/ruanun/simple-server-status/pkg/model"
+)
+
+// maxPushBatchSize 单次 HTTP 推送允许的最大上报条数（与 Agent 端 pushBatchSize 上限一致）
+const maxPushBatchSize = 100
+
+// handlePush 处理 Agent 的 HTTP 推送上报
+// 请求体为单个 ServerInfo 对象或 ServerInfo 数组，认证方式与 WebSocket 握手相同
+func (wsm *WebSocketManager) handlePush(c *gin.Context) {
+	secret := c.GetHeader(constant.HeaderSecret)
+	serverID := c.GetHeader(constant.HeaderId)
+
+	if !wsm.authenticate(secret, serverID) {
+		authErr := NewAuthenticationError("HTTP推送认证失败", fmt.Sprintf("ServerID: %s", serverID))
+		authErr.IP = c.ClientIP()
+		if wsm.errorHandler != nil {
+			wsm.errorHandler.RecordError(authErr)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权连接"})
+		return
+	}
+
+	// 每条上报与 WebSocket 单条消息的大小上限一致
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, wsm.maxMessageSize*maxPushBatchSize))
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "请求体过大"})
+		return
+	}
+
+	reports, err := decodePushBody(body)
+	if err != nil || len(reports) == 0 || len(reports) > maxPushBatchSize {
+		msgErr := NewValidationError("HTTP推送消息格式错误", fmt.Sprintf("ServerID: %s, Error: %v", serverID, err))
+		msgErr.IP = c.ClientIP()
+		if wsm.errorHandler != nil {
+			wsm.errorHandler.RecordError(msgErr)
+		}
+		wsm.incrementErrorCount(serverID)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "消息格式错误"})
+		return
+	}
+
+	wsm.touchConnection(serverID, TransportHTTP, c.ClientIP(), len(reports))
+
+	// 按顺序处理，最后一条即为最新状态
+	accepted := 0
+	for _, report := range reports {
+		if wsm.applyServerInfo(serverID, report) {
+			accepted++
+		}
+	}
+
+	// 在响应中携带探测任务，HTTP 推送模式的 Agent 由此获取任务
+	response.Success(c, gin.H{"accepted": accepted, "command": wsm.probes.Command(serverID)})
+}
+
+// decodePushBody 解析推送请求体，兼容单个对象和数组
+func decodePushBody(body []byte) ([]*model.ServerInfo, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("请求体为空")
+	}
+
+	if trimmed[0] == '[' {
+		var reports []*model.ServerInfo
+		if err := json.Unmarshal(trimmed, &reports); err != nil {
+			return nil, err
+		}
+		for i, report := range reports {
+			if report == nil {
+				return nil, fmt.Errorf("第 %d 条上报为空", i)
+			}
+		}
+		return reports, nil
+	}
+
+	var report model.ServerInfo
+	if err := json.Unmarshal(trimmed, &report); err != nil {
+		return nil, err
+	}
+	return []*model.ServerInfo{&report}, nil
+}
+
+// touchConnection 记录无会话（HTTP 推送、面板拉取）的连接信息，使其与 WebSocket 连接一样参与在线统计
+// 已有其他上报方式的连接（如 WebSocket）时单独记录到 OtherTransports，不覆盖该连接的心跳与消息计数
+func (wsm *WebSocketManager) touchConnection(serverID, transport, ip string, count int) {
+	now := time.Now()
+
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
+
+	connInfo, exists := wsm.connections[serverID]
+	if !exists {
+		connInfo = &ConnectionInfo{
+			ServerID:    serverID,
+			Status:      Connected,
+			Transport:   transport,
+			ConnectedAt: now,
+			IP:          ip,
+		}
+		wsm.connections[serverID] = connInfo
+		wsm.totalConnections++
+		wsm.logger.Infof("服务器开始上报 - ServerID: %s, Transport: %s, IP: %s", serverID, transport, ip)
+	}
+
+	wsm.totalMessages += int64(count)
+	if connInfo.Transport != transport {
+		if connInfo.OtherTransports == nil {
+			connInfo.OtherTransports = make(map[string]TransportActivity)
+		}
+		activity := connInfo.OtherTransports[transport]
+		activity.LastMessage = now
+		activity.MessageCount += int64(count)
+		connInfo.OtherTransports[transport] = activity
+		return
+	}
+
+	connInfo.LastHeartbeat = now
+	connInfo.LastMessage = now
+	connInfo.MessageCount += int64(count)
+}
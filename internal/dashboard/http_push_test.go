@@ -0,0 +1,310 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	cmap "github.com/orcaman/concurrent-map/v2"
+	"github.com/ruanun/simple-server-status/internal/dashboard/config"
+	"github.com/ruanun/simple-server-status/internal/dashboard/global/constant"
+	"github.com/ruanun/simple-server-status/internal/shared/heartbeat"
+	"github.com/ruanun/simple-server-status/pkg/model"
+	"go.uber.org/zap"
+)
+
+// testConfigAccessor 测试用配置访问器
+type testConfigAccessor struct {
+	cfg *config.DashboardConfig
+}
+
+func (a *testConfigAccessor) GetConfig() *config.DashboardConfig {
+	return a.cfg
+}
+
+// newTestWebSocketManager 创建测试用 WebSocket 管理器及其依赖
+func newTestWebSocketManager(t *testing.T) (*WebSocketManager, cmap.ConcurrentMap[string, *model.ServerInfo], *gin.Engine) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.DashboardConfig{
+		WebSocketPath:         "/ws-report",
+		ReportTimeIntervalMax: 30,
+		Servers: []*config.ServerConfig{
+			{Id: "web-01", Name: "Web 01", Group: "prod", Secret: "secret-123456", CountryCode: "JP"},
+		},
+	}
+	servers := cmap.New[*config.ServerConfig]()
+	for _, s := range cfg.Servers {
+		servers.Set(s.Id, s)
+	}
+	statusMap := cmap.New[*model.ServerInfo]()
+
+	logger := zap.NewNop().Sugar()
+	wsm := NewWebSocketManager(logger, NewErrorHandler(logger),
+		&serverConfigAdapter{servers: servers}, &serverStatusAdapter{statusMap: statusMap},
+		&testConfigAccessor{cfg: cfg})
+	t.Cleanup(wsm.Close)
+
+	r := gin.New()
+	wsm.SetupRoutes(r)
+	return wsm, statusMap, r
+}
+
+// TestHandlePush 测试 HTTP 推送上报
+func TestHandlePush(t *testing.T) {
+	tests := []struct {
+		name       string
+		serverID   string
+		secret     string
+		body       string
+		wantStatus int
+		wantStored bool
+	}{
+		{"单条上报", "web-01", "secret-123456", `{"cpuInfo":{"percent":12.5},"loc":"US"}`, http.StatusOK, true},
+		{"批量上报", "web-01", "secret-123456", `[{"cpuInfo":{"percent":1}},{"cpuInfo":{"percent":12.5}}]`, http.StatusOK, true},
+		{"密钥错误", "web-01", "wrong-secret", `{"cpuInfo":{"percent":12.5}}`, http.StatusUnauthorized, false},
+		{"未知服务器", "unknown", "secret-123456", `{"cpuInfo":{"percent":12.5}}`, http.StatusUnauthorized, false},
+		{"格式错误", "web-01", "secret-123456", `{"cpuInfo":`, http.StatusBadRequest, false},
+		{"空数组", "web-01", "secret-123456", `[]`, http.StatusBadRequest, false},
+		{"数组包含空元素", "web-01", "secret-123456", `[null]`, http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wsm, statusMap, r := newTestWebSocketManager(t)
+
+			req := httptest.NewRequest(http.MethodPost, "/ws-report", strings.NewReader(tt.body))
+			req.Header.Set(constant.HeaderId, tt.serverID)
+			req.Header.Set(constant.HeaderSecret, tt.secret)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("状态码 = %d; want %d, body: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			info, stored := statusMap.Get(tt.serverID)
+			if stored != tt.wantStored {
+				t.Fatalf("stored = %v; want %v", stored, tt.wantStored)
+			}
+			if !tt.wantStored {
+				return
+			}
+
+			// 与 WebSocket 上报相同的处理：补充配置信息、国家代码覆盖并转小写
+			if info.Name != "Web 01" || info.Group != "prod" || info.Id != "web-01" {
+				t.Errorf("服务器信息未按配置补充: %+v", info)
+			}
+			if info.Loc != "jp" {
+				t.Errorf("Loc = %s; want jp", info.Loc)
+			}
+			if info.CpuInfo == nil || info.CpuInfo.Percent != 12.5 {
+				t.Errorf("最新上报未生效: %+v", info.CpuInfo)
+			}
+			if info.LastReportTime == 0 {
+				t.Error("LastReportTime 未设置")
+			}
+
+			// HTTP 推送应与 WebSocket 一样计入在线服务器统计
+			conn, ok := wsm.GetConnectionInfo(tt.serverID)
+			if !ok {
+				t.Fatal("HTTP 推送未计入连接信息")
+			}
+			if conn.Transport != TransportHTTP {
+				t.Errorf("Transport = %s; want %s", conn.Transport, TransportHTTP)
+			}
+			if ids := wsm.GetAllServerId(); len(ids) != 1 || ids[0] != tt.serverID {
+				t.Errorf("GetAllServerId() = %v", ids)
+			}
+		})
+	}
+}
+
+// TestHandlePush_Empty 测试推送空对象时补全嵌套对象，展示数据不会空指针
+func TestHandlePush_Empty(t *testing.T) {
+	for _, body := range []string{`{}`, `[{}]`} {
+		_, statusMap, r := newTestWebSocketManager(t)
+
+		req := httptest.NewRequest(http.MethodPost, "/ws-report", strings.NewReader(body))
+		req.Header.Set(constant.HeaderId, "web-01")
+		req.Header.Set(constant.HeaderSecret, "secret-123456")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: 状态码 = %d, body: %s", body, w.Code, w.Body.String())
+		}
+
+		info, ok := statusMap.Get("web-01")
+		if !ok || info.HostInfo == nil || info.CpuInfo == nil || info.VirtualMemoryInfo == nil || info.DiskInfo == nil || info.NetworkInfo == nil {
+			t.Fatalf("%s: 嵌套对象未补全: %+v", body, info)
+		}
+		resp := model.NewRespServerInfo(info)
+		_ = info.VirtualMemoryInfo.Percent(model.RAMPercentExcludeCache)
+		if resp.Name != "Web 01" {
+			t.Errorf("%s: Name = %s", body, resp.Name)
+		}
+	}
+}
+
+// TestTouchConnection_KeepsWebSocket 测试 WebSocket 连接期间的其他上报不覆盖连接的心跳与消息计数
+func TestTouchConnection_KeepsWebSocket(t *testing.T) {
+	wsm, _, _ := newTestWebSocketManager(t)
+
+	heartbeatAt := time.Now().Add(-time.Minute)
+	wsm.mu.Lock()
+	wsm.connections["web-01"] = &ConnectionInfo{ServerID: "web-01", Transport: TransportWebSocket, LastHeartbeat: heartbeatAt, MessageCount: 3}
+	wsm.mu.Unlock()
+
+	wsm.touchConnection("web-01", TransportIngest, "127.0.0.1", 1)
+	wsm.touchConnection("web-01", TransportIngest, "127.0.0.1", 2)
+
+	info, _ := wsm.GetConnectionInfo("web-01")
+	if info.Transport != TransportWebSocket || !info.LastHeartbeat.Equal(heartbeatAt) || info.MessageCount != 3 {
+		t.Errorf("WebSocket 连接被覆盖: transport=%s heartbeat=%v count=%d", info.Transport, info.LastHeartbeat, info.MessageCount)
+	}
+	if got := info.OtherTransports[TransportIngest]; got.MessageCount != 3 || got.LastMessage.IsZero() {
+		t.Errorf("OtherTransports[ingest] = %+v; want 3 条消息", got)
+	}
+}
+
+// TestCheckHeartbeats_RemovesStalePush 测试 HTTP 推送超时后被移出在线列表
+func TestCheckHeartbeats_RemovesStalePush(t *testing.T) {
+	wsm, _, _ := newTestWebSocketManager(t)
+
+	wsm.touchConnection("web-01", TransportHTTP, "127.0.0.1", 1)
+	if wsm.SessionLength() != 1 {
+		t.Fatalf("SessionLength() = %d; want 1", wsm.SessionLength())
+	}
+
+	wsm.mu.Lock()
+	wsm.connections["web-01"].LastHeartbeat = wsm.connections["web-01"].LastHeartbeat.Add(-2 * wsm.heartbeatTimeout)
+	wsm.mu.Unlock()
+
+	wsm.checkHeartbeats()
+
+	if wsm.SessionLength() != 0 {
+		t.Errorf("SessionLength() = %d; want 0", wsm.SessionLength())
+	}
+}
+
+// TestHandlePush_Probes 测试推送响应下发探测任务，上报的探测结果进入延迟矩阵
+func TestHandlePush_Probes(t *testing.T) {
+	wsm, _, r := newTestWebSocketManager(t)
+	wsm.SetProbes([]*config.ProbeConfig{
+		{Name: "gw", Type: "icmp", Host: "10.0.0.1", Interval: 60, Count: 5, TimeoutMs: 1000},
+		{Name: "db", Type: "tcp", Host: "10.0.0.2", Port: 5432, Interval: 60, Count: 5, TimeoutMs: 1000, Servers: []string{"db-01"}},
+	})
+
+	body := `{"probes":[{"name":"gw","target":"10.0.0.1","method":"icmp","sent":5,"received":5,"rttMs":1.5,"probedAt":1700000000}]}`
+	req := httptest.NewRequest(http.MethodPost, "/ws-report", strings.NewReader(body))
+	req.Header.Set(constant.HeaderId, "web-01")
+	req.Header.Set(constant.HeaderSecret, "secret-123456")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp struct {
+		Data struct {
+			Command *model.AgentCommand `json:"command"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("响应格式错误: %v, body: %s", err, w.Body.String())
+	}
+	// 只下发分配给该服务器的任务
+	if cmd := resp.Data.Command; cmd == nil || cmd.Type != model.CommandProbes || len(cmd.Probes) != 1 || cmd.Probes[0].Name != "gw" {
+		t.Errorf("下发的指令 = %+v", resp.Data.Command)
+	}
+
+	matrix := wsm.GetProbeMatrix()
+	if len(matrix.Probes) != 2 || len(matrix.Servers) != 1 || matrix.Servers[0].Name != "Web 01" || matrix.Servers[0].Results["gw"].RTTMs != 1.5 {
+		t.Errorf("GetProbeMatrix() = %+v", matrix)
+	}
+	if points := wsm.GetProbeHistory("web-01", "gw"); len(points) != 1 || points[0].ProbedAt != 1700000000 {
+		t.Errorf("GetProbeHistory() = %+v", points)
+	}
+}
+
+// TestSendProbeTasks_BufferFull 测试发送缓冲区已满时下发探测任务不会与 handleError 死锁
+func TestSendProbeTasks_BufferFull(t *testing.T) {
+	wsm, _, r := newTestWebSocketManager(t)
+	// handleConnect 在 melody 启动写协程之前执行，无缓冲时下发任务必然触发 ErrMessageBufferFull
+	wsm.melody.Config.MessageBufferSize = 0
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	dialReport(t, srv, false)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for wsm.GetStats()["total_errors"].(int64) == 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		wsm.SetProbes([]*config.ProbeConfig{{Name: "gw", Type: "icmp", Host: "10.0.0.1"}})
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("下发探测任务死锁")
+	}
+	if wsm.SessionLength() != 1 {
+		t.Errorf("SessionLength() = %d; want 1", wsm.SessionLength())
+	}
+}
+
+// TestIsOnline 测试两种离线判定策略
+func TestIsOnline(t *testing.T) {
+	wsm, _, _ := newTestWebSocketManager(t)
+	now := time.Now()
+
+	// ws-01 上一个周期的 ping 未响应，ws-02 已响应
+	pending := heartbeat.NewTracker(heartbeat.DefaultWindow)
+	pending.Ping(now.Add(-15 * time.Second))
+	pending.Ping(now)
+	answered := heartbeat.NewTracker(heartbeat.DefaultWindow)
+	answered.Ping(now)
+	answered.Pong("", now.Add(10*time.Millisecond))
+	wsm.mu.Lock()
+	wsm.connections["ws-01"] = &ConnectionInfo{ServerID: "ws-01", Transport: TransportWebSocket, link: pending}
+	wsm.connections["ws-02"] = &ConnectionInfo{ServerID: "ws-02", Transport: TransportWebSocket, link: answered}
+	wsm.mu.Unlock()
+	wsm.touchConnection("web-01", TransportHTTP, "127.0.0.1", 1)
+
+	fresh := now.Unix()
+	tests := []struct {
+		name   string
+		policy string
+		info   *model.ServerInfo
+		want   bool
+	}{
+		{"上报超时", OfflinePolicyReport, &model.ServerInfo{Id: "ws-02", LastReportTime: fresh - 60}, false},
+		{"按上报判定忽略心跳", OfflinePolicyReport, &model.ServerInfo{Id: "ws-01", LastReportTime: fresh}, true},
+		{"按上报判定忽略连接", OfflinePolicyReport, &model.ServerInfo{Id: "gone", LastReportTime: fresh}, true},
+		{"按心跳判定丢失pong", OfflinePolicyHeartbeat, &model.ServerInfo{Id: "ws-01", LastReportTime: fresh}, false},
+		{"按心跳判定已响应", OfflinePolicyHeartbeat, &model.ServerInfo{Id: "ws-02", LastReportTime: fresh}, true},
+		{"按心跳判定连接已断开", OfflinePolicyHeartbeat, &model.ServerInfo{Id: "gone", LastReportTime: fresh}, false},
+		{"按心跳判定HTTP推送", OfflinePolicyHeartbeat, &model.ServerInfo{Id: "web-01", LastReportTime: fresh}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wsm.offlinePolicy = tt.policy
+			if got := wsm.IsOnline(tt.info); got != tt.want {
+				t.Errorf("IsOnline(%s) = %v; want %v", tt.info.Id, got, tt.want)
+			}
+		})
+	}
+
+	links := wsm.GetConnectionLinks()
+	if links["ws-02"].Dashboard == nil || links["ws-02"].Dashboard.PongsReceived != 1 {
+		t.Errorf("ws-02 链路质量 = %+v; want 1 个 pong", links["ws-02"].Dashboard)
+	}
+	if links["web-01"].Dashboard != nil {
+		t.Errorf("HTTP 推送不应有面板测得的链路质量: %+v", links["web-01"].Dashboard)
+	}
+}
@@ -0,0 +1,285 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruanun/simple-server-status/internal/dashboard/global/constant"
+	"github.com/ruanun/simple-server-status/internal/dashboard/response"
+	"github.com/ruanun/simple-server-status/pkg/model"
+)
+
+// IngestPath 自定义上报路径
+const IngestPath = "/api/ingest/:serverId"
+
+// ingestKV 键值形式的单个指标
+type ingestKV struct {
+	Key   string   `json:"key"`
+	Value *float64 `json:"value"`
+}
+
+// ingestMetric 键值指标到 ServerInfo 字段的映射
+type ingestMetric struct {
+	percent bool // 取值范围 0-100
+	apply   func(info *model.ServerInfo, v float64)
+}
+
+// ingestMetrics 支持的键值指标
+var ingestMetrics = map[string]ingestMetric{
+	"cpu.percent":      {true, func(i *model.ServerInfo, v float64) { i.CpuInfo.Percent = v }},
+	"memory.total":     {false, func(i *model.ServerInfo, v float64) { i.VirtualMemoryInfo.Total = uint64(v) }},
+	"memory.used":      {false, func(i *model.ServerInfo, v float64) { i.VirtualMemoryInfo.Used = uint64(v) }},
+	"memory.percent":   {true, func(i *model.ServerInfo, v float64) { i.VirtualMemoryInfo.UsedPercent = v }},
+	"swap.total":       {false, func(i *model.ServerInfo, v float64) { i.SwapMemoryInfo.Total = uint64(v) }},
+	"swap.used":        {false, func(i *model.ServerInfo, v float64) { i.SwapMemoryInfo.Used = uint64(v) }},
+	"swap.percent":     {true, func(i *model.ServerInfo, v float64) { i.SwapMemoryInfo.UsedPercent = v }},
+	"disk.total":       {false, func(i *model.ServerInfo, v float64) { i.DiskInfo.Total = uint64(v) }},
+	"disk.used":        {false, func(i *model.ServerInfo, v float64) { i.DiskInfo.Used = uint64(v) }},
+	"disk.percent":     {true, func(i *model.ServerInfo, v float64) { i.DiskInfo.UsedPercent = v }},
+	"net.in_speed":     {false, func(i *model.ServerInfo, v float64) { i.NetworkInfo.NetInSpeed = uint64(v) }},
+	"net.out_speed":    {false, func(i *model.ServerInfo, v float64) { i.NetworkInfo.NetOutSpeed = uint64(v) }},
+	"net.in_transfer":  {false, func(i *model.ServerInfo, v float64) { i.NetworkInfo.NetInTransfer = uint64(v) }},
+	"net.out_transfer": {false, func(i *model.ServerInfo, v float64) { i.NetworkInfo.NetOutTransfer = uint64(v) }},
+	"load.1":           {false, func(i *model.ServerInfo, v float64) { i.HostInfo.AvgStat.Load1 = v }},
+	"load.5":           {false, func(i *model.ServerInfo, v float64) { i.HostInfo.AvgStat.Load5 = v }},
+	"load.15":          {false, func(i *model.ServerInfo, v float64) { i.HostInfo.AvgStat.Load15 = v }},
+	"host.uptime":      {false, func(i *model.ServerInfo, v float64) { i.HostInfo.Uptime = uint64(v) }},
+	"host.boot_time":   {false, func(i *model.ServerInfo, v float64) { i.HostInfo.BootTime = uint64(v) }},
+}
+
+// handleIngest 处理自定义上报
+// 请求体为部分 ServerInfo 对象，或键值指标列表（数组或 {"metrics": [...]}）
+// 上报内容合并到该服务器的当前状态，认证使用与 Agent 握手相同的密钥
+func (wsm *WebSocketManager) handleIngest(c *gin.Context) {
+	serverID := c.Param("serverId")
+	secret := c.GetHeader(constant.HeaderSecret)
+
+	if !wsm.authenticate(secret, serverID) {
+		authErr := NewAuthenticationError("自定义上报认证失败", fmt.Sprintf("ServerID: %s", serverID))
+		authErr.IP = c.ClientIP()
+		if wsm.errorHandler != nil {
+			wsm.errorHandler.RecordError(authErr)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权连接"})
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, wsm.maxMessageSize))
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "请求体过大"})
+		return
+	}
+
+	wsm.ingestMu.Lock()
+	defer wsm.ingestMu.Unlock()
+
+	info, err := wsm.mergeIngest(serverID, body)
+	if err != nil {
+		msgErr := NewValidationError("自定义上报内容无效", fmt.Sprintf("ServerID: %s, Error: %v", serverID, err))
+		msgErr.IP = c.ClientIP()
+		if wsm.errorHandler != nil {
+			wsm.errorHandler.RecordError(msgErr)
+		}
+		wsm.incrementErrorCount(serverID)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if info.Ip == "" {
+		info.Ip = c.ClientIP()
+	}
+
+	wsm.touchConnection(serverID, TransportIngest, c.ClientIP(), 1)
+	wsm.applyServerInfo(serverID, info)
+
+	response.Success(c, gin.H{"accepted": 1})
+}
+
+// mergeIngest 将上报内容合并到当前状态的副本上
+// 不直接修改状态表中的对象，避免与读取方产生数据竞争
+func (wsm *WebSocketManager) mergeIngest(serverID string, body []byte) (*model.ServerInfo, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("请求体为空")
+	}
+
+	info := &model.ServerInfo{}
+	if current, ok := wsm.serverStatus.Get(serverID); ok && current != nil {
+		cloned, err := cloneServerInfo(current)
+		if err != nil {
+			return nil, err
+		}
+		info = cloned
+	}
+	info.FillEmpty()
+
+	metrics, isKV, err := decodeIngestKV(trimmed)
+	if err != nil {
+		return nil, err
+	}
+	var provided map[string]bool
+	if isKV {
+		if err := applyIngestKV(info, metrics); err != nil {
+			return nil, err
+		}
+		provided = make(map[string]bool, len(metrics))
+		for _, m := range metrics {
+			provided[m.Key] = true
+		}
+	} else {
+		// 按部分 ServerInfo 解析：未出现的字段保留当前值，嵌套对象按字段合并
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(info); err != nil {
+			return nil, fmt.Errorf("解析 ServerInfo 失败: %w", err)
+		}
+		info.FillEmpty()
+		provided = providedUsageKeys(trimmed)
+	}
+
+	fillPercentages(info, provided)
+	if err := validateIngested(info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// decodeIngestKV 尝试按键值列表解析，返回是否为键值格式
+func decodeIngestKV(body []byte) ([]ingestKV, bool, error) {
+	if body[0] == '[' {
+		var metrics []ingestKV
+		if err := json.Unmarshal(body, &metrics); err != nil {
+			return nil, true, fmt.Errorf("解析指标列表失败: %w", err)
+		}
+		return metrics, true, nil
+	}
+
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(body, &probe); err != nil {
+		return nil, false, fmt.Errorf("请求体不是有效的 JSON 对象: %w", err)
+	}
+	raw, ok := probe["metrics"]
+	if !ok {
+		return nil, false, nil
+	}
+	if len(probe) != 1 {
+		return nil, true, fmt.Errorf("键值格式只允许包含 metrics 字段")
+	}
+	var metrics []ingestKV
+	if err := json.Unmarshal(raw, &metrics); err != nil {
+		return nil, true, fmt.Errorf("解析指标列表失败: %w", err)
+	}
+	return metrics, true, nil
+}
+
+// applyIngestKV 校验并应用键值指标
+func applyIngestKV(info *model.ServerInfo, metrics []ingestKV) error {
+	if len(metrics) == 0 {
+		return fmt.Errorf("指标列表为空")
+	}
+	for i, m := range metrics {
+		metric, ok := ingestMetrics[m.Key]
+		if !ok {
+			return fmt.Errorf("第 %d 个指标 %q 不受支持，可用指标: %v", i, m.Key, ingestMetricKeys())
+		}
+		if m.Value == nil {
+			return fmt.Errorf("指标 %s 缺少 value", m.Key)
+		}
+		v := *m.Value
+		if v < 0 {
+			return fmt.Errorf("指标 %s 不能为负数", m.Key)
+		}
+		if metric.percent && v > 100 {
+			return fmt.Errorf("指标 %s 超出 0-100 范围", m.Key)
+		}
+		metric.apply(info, v)
+	}
+	return nil
+}
+
+// ingestMetricKeys 返回支持的指标名（排序后）
+func ingestMetricKeys() []string {
+	keys := make([]string, 0, len(ingestMetrics))
+	for k := range ingestMetrics {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validateIngested 校验合并后的数据
+func validateIngested(info *model.ServerInfo) error {
+	percents := map[string]float64{
+		"cpuInfo.percent":               info.CpuInfo.Percent,
+		"virtualMemoryInfo.usedPercent": info.VirtualMemoryInfo.UsedPercent,
+		"swapMemoryInfo.usedPercent":    info.SwapMemoryInfo.UsedPercent,
+		"diskInfo.usedPercent":          info.DiskInfo.UsedPercent,
+	}
+	for field, v := range percents {
+		if v < 0 || v > 100 {
+			return fmt.Errorf("%s 超出 0-100 范围", field)
+		}
+	}
+	if info.HostInfo.AvgStat.Load1 < 0 || info.HostInfo.AvgStat.Load5 < 0 || info.HostInfo.AvgStat.Load15 < 0 {
+		return fmt.Errorf("负载不能为负数")
+	}
+	return nil
+}
+
+// providedUsageKeys 返回部分 ServerInfo 中出现的内存、交换、磁盘占用字段，键名与键值指标一致（如 memory.used）
+func providedUsageKeys(body []byte) map[string]bool {
+	var usage struct {
+		Memory map[string]json.RawMessage `json:"virtualMemoryInfo"`
+		Swap   map[string]json.RawMessage `json:"swapMemoryInfo"`
+		Disk   map[string]json.RawMessage `json:"diskInfo"`
+	}
+	provided := make(map[string]bool)
+	if err := json.Unmarshal(body, &usage); err != nil {
+		return provided
+	}
+	for prefix, fields := range map[string]map[string]json.RawMessage{"memory": usage.Memory, "swap": usage.Swap, "disk": usage.Disk} {
+		for field, key := range map[string]string{"total": "total", "used": "used", "usedPercent": "percent"} {
+			if _, ok := fields[field]; ok {
+				provided[prefix+"."+key] = true
+			}
+		}
+	}
+	return provided
+}
+
+// fillPercentages 根据 used/total 计算占用百分比
+// 本次上报包含 used 或 total 但未显式提供百分比时重新计算，避免沿用合并前的旧值；从未有过百分比时同样计算
+func fillPercentages(info *model.ServerInfo, provided map[string]bool) {
+	usages := []struct {
+		prefix      string
+		used, total uint64
+		percent     *float64
+	}{
+		{"memory", info.VirtualMemoryInfo.Used, info.VirtualMemoryInfo.Total, &info.VirtualMemoryInfo.UsedPercent},
+		{"swap", info.SwapMemoryInfo.Used, info.SwapMemoryInfo.Total, &info.SwapMemoryInfo.UsedPercent},
+		{"disk", info.DiskInfo.Used, info.DiskInfo.Total, &info.DiskInfo.UsedPercent},
+	}
+	for _, u := range usages {
+		sized := provided[u.prefix+".used"] || provided[u.prefix+".total"]
+		if (sized && !provided[u.prefix+".percent"]) || *u.percent == 0 {
+			*u.percent = percent(u.used, u.total)
+		}
+	}
+}
+
+// cloneServerInfo 深拷贝 ServerInfo
+func cloneServerInfo(info *model.ServerInfo) (*model.ServerInfo, error) {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+	var cloned model.ServerInfo
+	if err := json.Unmarshal(data, &cloned); err != nil {
+		return nil, err
+	}
+	return &cloned, nil
+}
@@ -0,0 +1,181 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ruanun/simple-server-status/internal/dashboard/global/constant"
+	"github.com/ruanun/simple-server-status/pkg/model"
+)
+
+// TestHandleIngest 测试自定义上报
+func TestHandleIngest(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		secret     string
+		body       string
+		wantStatus int
+		check      func(t *testing.T, info *model.ServerInfo)
+	}{
+		{
+			name: "部分 ServerInfo", path: "/api/ingest/web-01", secret: "secret-123456",
+			body:       `{"cpuInfo":{"percent":12.5},"virtualMemoryInfo":{"total":1000,"used":250}}`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, info *model.ServerInfo) {
+				if info.CpuInfo.Percent != 12.5 {
+					t.Errorf("CPU = %v; want 12.5", info.CpuInfo.Percent)
+				}
+				// 未提供百分比时根据 used/total 计算
+				if info.VirtualMemoryInfo.UsedPercent != 25 {
+					t.Errorf("内存占用 = %v; want 25", info.VirtualMemoryInfo.UsedPercent)
+				}
+				if info.HostInfo == nil || info.DiskInfo == nil || info.NetworkInfo == nil {
+					t.Error("缺失的嵌套对象未补全")
+				}
+				if info.Ip == "" {
+					t.Error("Ip 未使用客户端地址补全")
+				}
+			},
+		},
+		{
+			name: "键值数组", path: "/api/ingest/web-01", secret: "secret-123456",
+			body:       `[{"key":"cpu.percent","value":40},{"key":"load.1","value":0.75},{"key":"net.in_speed","value":2048}]`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, info *model.ServerInfo) {
+				if info.CpuInfo.Percent != 40 || info.HostInfo.AvgStat.Load1 != 0.75 || info.NetworkInfo.NetInSpeed != 2048 {
+					t.Errorf("键值指标未生效: cpu=%v load=%v in=%v",
+						info.CpuInfo.Percent, info.HostInfo.AvgStat.Load1, info.NetworkInfo.NetInSpeed)
+				}
+			},
+		},
+		{
+			name: "键值对象", path: "/api/ingest/web-01", secret: "secret-123456",
+			body:       `{"metrics":[{"key":"disk.total","value":200},{"key":"disk.used","value":50}]}`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, info *model.ServerInfo) {
+				if info.DiskInfo.UsedPercent != 25 {
+					t.Errorf("磁盘占用 = %v; want 25", info.DiskInfo.UsedPercent)
+				}
+			},
+		},
+		{name: "密钥错误", path: "/api/ingest/web-01", secret: "wrong", body: `{"cpuInfo":{"percent":1}}`, wantStatus: http.StatusUnauthorized},
+		{name: "未知服务器", path: "/api/ingest/unknown", secret: "secret-123456", body: `{"cpuInfo":{"percent":1}}`, wantStatus: http.StatusUnauthorized},
+		{name: "未知字段", path: "/api/ingest/web-01", secret: "secret-123456", body: `{"cpu":{"percent":1}}`, wantStatus: http.StatusBadRequest},
+		{name: "百分比越界", path: "/api/ingest/web-01", secret: "secret-123456", body: `{"cpuInfo":{"percent":150}}`, wantStatus: http.StatusBadRequest},
+		{name: "负数", path: "/api/ingest/web-01", secret: "secret-123456", body: `{"virtualMemoryInfo":{"total":-1}}`, wantStatus: http.StatusBadRequest},
+		{name: "未知指标", path: "/api/ingest/web-01", secret: "secret-123456", body: `[{"key":"gpu.percent","value":1}]`, wantStatus: http.StatusBadRequest},
+		{name: "指标缺少值", path: "/api/ingest/web-01", secret: "secret-123456", body: `[{"key":"cpu.percent"}]`, wantStatus: http.StatusBadRequest},
+		{name: "键值百分比越界", path: "/api/ingest/web-01", secret: "secret-123456", body: `[{"key":"memory.percent","value":101}]`, wantStatus: http.StatusBadRequest},
+		{name: "空指标列表", path: "/api/ingest/web-01", secret: "secret-123456", body: `{"metrics":[]}`, wantStatus: http.StatusBadRequest},
+		{name: "空请求体", path: "/api/ingest/web-01", secret: "secret-123456", body: ``, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wsm, statusMap, r := newTestWebSocketManager(t)
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set(constant.HeaderSecret, tt.secret)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("状态码 = %d; want %d, body: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			info, stored := statusMap.Get("web-01")
+			if tt.wantStatus != http.StatusOK {
+				if stored {
+					t.Error("失败的上报不应写入状态")
+				}
+				return
+			}
+			if !stored {
+				t.Fatal("上报未写入状态")
+			}
+
+			// 与 Agent 上报相同的处理：补充配置信息并计入在线统计
+			if info.Name != "Web 01" || info.Loc != "jp" || info.LastReportTime == 0 {
+				t.Errorf("服务器信息未按上报流程处理: %+v", info)
+			}
+			if conn, ok := wsm.GetConnectionInfo("web-01"); !ok || conn.Transport != TransportIngest {
+				t.Errorf("连接信息错误: %+v", conn)
+			}
+			// 展示层可直接转换，不会因空指针崩溃
+			_ = model.NewRespServerInfo(info)
+			tt.check(t, info)
+		})
+	}
+}
+
+// TestHandleIngest_Merge 测试多次上报按字段合并且不修改已存储对象
+func TestHandleIngest_Merge(t *testing.T) {
+	_, statusMap, r := newTestWebSocketManager(t)
+
+	post := func(body string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/ingest/web-01", strings.NewReader(body))
+		req.Header.Set(constant.HeaderSecret, "secret-123456")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("状态码 = %d, body: %s", w.Code, w.Body.String())
+		}
+	}
+
+	post(`{"cpuInfo":{"percent":10,"info":["ARM x 4 "]},"hostInfo":{"platform":"openwrt"}}`)
+	first, _ := statusMap.Get("web-01")
+
+	post(`[{"key":"cpu.percent","value":55}]`)
+	second, _ := statusMap.Get("web-01")
+
+	if second.CpuInfo.Percent != 55 {
+		t.Errorf("CPU = %v; want 55", second.CpuInfo.Percent)
+	}
+	if len(second.CpuInfo.Info) != 1 || second.HostInfo.Platform != "openwrt" {
+		t.Errorf("未上报的字段应保留: %+v %+v", second.CpuInfo, second.HostInfo)
+	}
+	if first.CpuInfo.Percent != 10 {
+		t.Errorf("已存储的对象被修改: %v", first.CpuInfo.Percent)
+	}
+}
+
+// TestHandleIngest_RecomputePercent 测试后续上报更新 used/total 时重新计算占用百分比
+func TestHandleIngest_RecomputePercent(t *testing.T) {
+	_, statusMap, r := newTestWebSocketManager(t)
+
+	post := func(body string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/ingest/web-01", strings.NewReader(body))
+		req.Header.Set(constant.HeaderSecret, "secret-123456")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("状态码 = %d, body: %s", w.Code, w.Body.String())
+		}
+	}
+
+	steps := []struct {
+		name       string
+		body       string
+		wantMemory float64
+		wantDisk   float64
+	}{
+		{"首次上报", `{"virtualMemoryInfo":{"total":1000,"used":250},"diskInfo":{"total":200,"used":50}}`, 25, 25},
+		{"只更新 used", `{"virtualMemoryInfo":{"used":500}}`, 50, 25},
+		{"键值更新 total", `[{"key":"memory.total","value":2000},{"key":"disk.used","value":100}]`, 25, 50},
+		{"显式百分比优先", `{"virtualMemoryInfo":{"used":1000,"usedPercent":42}}`, 42, 50},
+		{"未上报占用时保留", `[{"key":"cpu.percent","value":5}]`, 42, 50},
+	}
+	for _, step := range steps {
+		post(step.body)
+		info, _ := statusMap.Get("web-01")
+		if info.VirtualMemoryInfo.UsedPercent != step.wantMemory || info.DiskInfo.UsedPercent != step.wantDisk {
+			t.Errorf("%s: 内存 = %v, 磁盘 = %v; want %v, %v", step.name,
+				info.VirtualMemoryInfo.UsedPercent, info.DiskInfo.UsedPercent, step.wantMemory, step.wantDisk)
+		}
+	}
+}
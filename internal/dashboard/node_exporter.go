@@ -0,0 +1,439 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ruanun/simple-server-status/internal/dashboard/config"
+	"github.com/ruanun/simple-server-status/pkg/model"
+	"github.com/shirou/gopsutil/v4/load"
+)
+
+// maxExpositionSize node_exporter 响应体大小上限
+const maxExpositionSize = 10 << 20
+
+// nodeExporterExcludeDevices 不计入流量统计的网卡，按设备名前缀匹配
+var nodeExporterExcludeDevices = []string{
+	"lo", "tun", "docker", "veth", "br-", "vmbr", "vnet", "kube",
+}
+
+// nodeExporterFsTypes 计入磁盘统计的文件系统类型，按类型名精确匹配
+var nodeExporterFsTypes = []string{
+	"apfs", "ext4", "ext3", "ext2", "f2fs", "reiserfs", "jfs", "btrfs",
+	"fuseblk", "zfs", "simfs", "ntfs", "fat32", "exfat", "xfs", "fuse.rclone",
+}
+
+// metricSample Prometheus 文本格式中的一个样本
+type metricSample struct {
+	name   string
+	labels map[string]string
+	value  float64
+}
+
+// parseExposition 解析 Prometheus 文本格式（text/plain; version=0.0.4）
+// 只保留样本，忽略注释、HELP 与 TYPE 行
+func parseExposition(r io.Reader) ([]metricSample, error) {
+	var samples []metricSample
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		sample, err := parseSampleLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 行: %w", lineNo, err)
+		}
+		samples = append(samples, sample)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
+
+// parseSampleLine 解析单行样本：name{label="value",...} value [timestamp]
+func parseSampleLine(line string) (metricSample, error) {
+	sample := metricSample{labels: map[string]string{}}
+
+	nameEnd := strings.IndexAny(line, "{ \t")
+	if nameEnd <= 0 {
+		return sample, fmt.Errorf("无效样本: %q", line)
+	}
+	sample.name = line[:nameEnd]
+	rest := line[nameEnd:]
+
+	if rest[0] == '{' {
+		consumed, err := parseLabels(rest[1:], sample.labels)
+		if err != nil {
+			return sample, err
+		}
+		rest = rest[1+consumed:]
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return sample, fmt.Errorf("缺少样本值: %q", line)
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return sample, fmt.Errorf("无效样本值 %q: %w", fields[0], err)
+	}
+	sample.value = value
+	return sample, nil
+}
+
+// parseLabels 解析标签集合直到右花括号，返回消耗的字节数（包含右花括号）
+func parseLabels(s string, labels map[string]string) (int, error) {
+	i := 0
+	for {
+		for i < len(s) && (s[i] == ' ' || s[i] == ',') {
+			i++
+		}
+		if i >= len(s) {
+			return 0, fmt.Errorf("标签未闭合")
+		}
+		if s[i] == '}' {
+			return i + 1, nil
+		}
+
+		eq := strings.IndexByte(s[i:], '=')
+		if eq <= 0 {
+			return 0, fmt.Errorf("无效标签: %q", s[i:])
+		}
+		name := strings.TrimSpace(s[i : i+eq])
+		i += eq + 1
+		if i >= len(s) || s[i] != '"' {
+			return 0, fmt.Errorf("标签 %s 的值缺少引号", name)
+		}
+		i++
+
+		var value strings.Builder
+		for {
+			if i >= len(s) {
+				return 0, fmt.Errorf("标签 %s 的值未闭合", name)
+			}
+			c := s[i]
+			if c == '"' {
+				i++
+				break
+			}
+			if c == '\\' && i+1 < len(s) {
+				i++
+				switch s[i] {
+				case 'n':
+					value.WriteByte('\n')
+				default:
+					value.WriteByte(s[i])
+				}
+				i++
+				continue
+			}
+			value.WriteByte(c)
+			i++
+		}
+		labels[name] = value.String()
+	}
+}
+
+// nodeExporterScraper 抓取单个 node_exporter 并转换为 ServerInfo
+// CPU 占用与网络速度需要两次抓取之间的差值，因此每个服务器持有独立的实例，仅在单个拉取协程中使用
+type nodeExporterScraper struct {
+	client *http.Client
+
+	hasPrev      bool
+	prevTime     time.Time
+	prevCPUIdle  float64
+	prevCPUTotal float64
+	prevNetIn    uint64
+	prevNetOut   uint64
+}
+
+// newNodeExporterScraper 创建 node_exporter 抓取器
+func newNodeExporterScraper(client *http.Client) *nodeExporterScraper {
+	return &nodeExporterScraper{client: client}
+}
+
+// Scrape 抓取 metrics 并转换为 ServerInfo
+func (ns *nodeExporterScraper) Scrape(ctx context.Context, server *config.ServerConfig) (*model.ServerInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.PullUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/plain;version=0.0.4")
+
+	resp, err := ns.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("状态码 %d", resp.StatusCode)
+	}
+
+	samples, err := parseExposition(io.LimitReader(resp.Body, maxExpositionSize))
+	if err != nil {
+		return nil, fmt.Errorf("解析 metrics 失败: %w", err)
+	}
+
+	info := ns.convert(samples, time.Now())
+	if u, err := url.Parse(server.PullUrl); err == nil {
+		info.Ip = u.Hostname()
+	}
+	return info, nil
+}
+
+// convert 将样本映射到 ServerInfo
+func (ns *nodeExporterScraper) convert(samples []metricSample, now time.Time) *model.ServerInfo {
+	hostInfo := &model.HostInfo{AvgStat: &load.AvgStat{}}
+	cpuInfo := &model.CpuInfo{}
+	memInfo := &model.VirtualMemoryInfo{}
+	swapInfo := &model.SwapMemoryInfo{}
+	diskInfo := &model.DiskInfo{}
+	netInfo := &model.NetworkInfo{}
+
+	var cpuIdle, cpuTotal float64
+	cpus := make(map[string]struct{})
+	memory := make(map[string]float64)
+	fs := newFsSamples()
+	var nodeTime float64
+
+	for _, s := range samples {
+		switch {
+		case s.name == "node_cpu_seconds_total":
+			cpus[s.labels["cpu"]] = struct{}{}
+			cpuTotal += s.value
+			if mode := s.labels["mode"]; mode == "idle" || mode == "iowait" {
+				cpuIdle += s.value
+			}
+		case strings.HasPrefix(s.name, "node_memory_") && strings.HasSuffix(s.name, "_bytes"):
+			memory[strings.TrimSuffix(strings.TrimPrefix(s.name, "node_memory_"), "_bytes")] = s.value
+		case s.name == "node_filesystem_size_bytes":
+			key := s.labels["mountpoint"]
+			fs.size[key] = s.value
+			fs.labels[key] = s.labels
+		case s.name == "node_filesystem_avail_bytes":
+			fs.avail[s.labels["mountpoint"]] = s.value
+		case s.name == "node_filesystem_free_bytes":
+			fs.free[s.labels["mountpoint"]] = s.value
+		case s.name == "node_filesystem_files":
+			fs.files[s.labels["mountpoint"]] = s.value
+		case s.name == "node_filesystem_files_free":
+			fs.filesFree[s.labels["mountpoint"]] = s.value
+		case s.name == "node_filesystem_readonly":
+			fs.readonly[s.labels["mountpoint"]] = s.value
+		case s.name == "node_network_receive_bytes_total":
+			if !isExcludedDevice(s.labels["device"]) {
+				netInfo.NetInTransfer += uint64(s.value)
+			}
+		case s.name == "node_network_transmit_bytes_total":
+			if !isExcludedDevice(s.labels["device"]) {
+				netInfo.NetOutTransfer += uint64(s.value)
+			}
+		case s.name == "node_load1":
+			hostInfo.AvgStat.Load1 = s.value
+		case s.name == "node_load5":
+			hostInfo.AvgStat.Load5 = s.value
+		case s.name == "node_load15":
+			hostInfo.AvgStat.Load15 = s.value
+		case s.name == "node_boot_time_seconds":
+			hostInfo.BootTime = uint64(s.value)
+		case s.name == "node_time_seconds":
+			nodeTime = s.value
+		case s.name == "node_uname_info":
+			hostInfo.OS = strings.ToLower(s.labels["sysname"])
+			hostInfo.KernelVersion = s.labels["release"]
+			hostInfo.KernelArch = s.labels["machine"]
+		case s.name == "node_os_info":
+			hostInfo.Platform = s.labels["id"]
+			hostInfo.PlatformVersion = s.labels["version_id"]
+			hostInfo.PlatformFamily = s.labels["id_like"]
+		}
+	}
+
+	// 运行时间：优先使用被监控主机自身的时间，避免两端时钟偏差
+	if hostInfo.BootTime > 0 {
+		current := float64(now.Unix())
+		if nodeTime > 0 {
+			current = nodeTime
+		}
+		if current > float64(hostInfo.BootTime) {
+			hostInfo.Uptime = uint64(current) - hostInfo.BootTime
+		}
+	}
+
+	if len(cpus) > 0 {
+		cpuInfo.Info = []string{fmt.Sprintf("node_exporter x %d ", len(cpus))}
+		cpuInfo.LogicalCores = len(cpus)
+	}
+
+	// 内存：与 gopsutil 一致，优先使用 MemAvailable 计算已用
+	memInfo.Total = uint64(memory["MemTotal"])
+	if avail, ok := memory["MemAvailable"]; ok {
+		memInfo.Used = subUint(memory["MemTotal"], avail)
+	} else {
+		memInfo.Used = subUint(memory["MemTotal"], memory["MemFree"]+memory["Buffers"]+memory["Cached"])
+	}
+	memInfo.UsedPercent = percent(memInfo.Used, memInfo.Total)
+	memInfo.Available = uint64(memory["MemAvailable"])
+	memInfo.Free = uint64(memory["MemFree"])
+	memInfo.Buffers = uint64(memory["Buffers"])
+	memInfo.Cached = uint64(memory["Cached"])
+	memInfo.Shared = uint64(memory["Shmem"])
+	memInfo.Slab = uint64(memory["Slab"])
+	memInfo.Dirty = uint64(memory["Dirty"])
+	memInfo.CommittedAS = uint64(memory["Committed_AS"])
+	memInfo.HugePageSize = uint64(memory["Hugepagesize"])
+
+	swapInfo.Total = uint64(memory["SwapTotal"])
+	swapInfo.Free = uint64(memory["SwapFree"])
+	swapInfo.Used = subUint(memory["SwapTotal"], memory["SwapFree"])
+	swapInfo.UsedPercent = percent(swapInfo.Used, swapInfo.Total)
+
+	ns.fillDisk(diskInfo, fs)
+
+	// CPU 占用与网络速度基于与上一次抓取的差值
+	if ns.hasPrev {
+		if deltaTotal := cpuTotal - ns.prevCPUTotal; deltaTotal > 0 {
+			busy := deltaTotal - (cpuIdle - ns.prevCPUIdle)
+			cpuInfo.Percent = math.Max(0, math.Min(100, busy/deltaTotal*100))
+		}
+		if elapsed := now.Sub(ns.prevTime).Seconds(); elapsed > 0 {
+			// 计数器回绕或网卡重置时跳过本次速度计算
+			if netInfo.NetInTransfer >= ns.prevNetIn {
+				netInfo.NetInSpeed = uint64(float64(netInfo.NetInTransfer-ns.prevNetIn) / elapsed)
+			}
+			if netInfo.NetOutTransfer >= ns.prevNetOut {
+				netInfo.NetOutSpeed = uint64(float64(netInfo.NetOutTransfer-ns.prevNetOut) / elapsed)
+			}
+		}
+	}
+	ns.hasPrev = true
+	ns.prevTime = now
+	ns.prevCPUIdle = cpuIdle
+	ns.prevCPUTotal = cpuTotal
+	ns.prevNetIn = netInfo.NetInTransfer
+	ns.prevNetOut = netInfo.NetOutTransfer
+
+	return &model.ServerInfo{
+		HostInfo:          hostInfo,
+		CpuInfo:           cpuInfo,
+		VirtualMemoryInfo: memInfo,
+		SwapMemoryInfo:    swapInfo,
+		DiskInfo:          diskInfo,
+		NetworkInfo:       netInfo,
+	}
+}
+
+// fsSamples 按挂载点汇总的 node_filesystem_* 指标
+type fsSamples struct {
+	size, avail, free map[string]float64
+	files, filesFree  map[string]float64 // inode 总数与剩余数
+	readonly          map[string]float64
+	labels            map[string]map[string]string
+}
+
+func newFsSamples() *fsSamples {
+	return &fsSamples{
+		size: make(map[string]float64), avail: make(map[string]float64), free: make(map[string]float64),
+		files: make(map[string]float64), filesFree: make(map[string]float64),
+		readonly: make(map[string]float64),
+		labels:   make(map[string]map[string]string),
+	}
+}
+
+// fillDisk 汇总文件系统，按设备去重（同一设备的多个挂载点只统计一次）
+func (ns *nodeExporterScraper) fillDisk(diskInfo *model.DiskInfo, fs *fsSamples) {
+	mountpoints := make([]string, 0, len(fs.size))
+	for mp := range fs.size {
+		mountpoints = append(mountpoints, mp)
+	}
+	sort.Strings(mountpoints)
+
+	seenDevices := make(map[string]bool)
+	for _, mp := range mountpoints {
+		l := fs.labels[mp]
+		fsType := strings.ToLower(l["fstype"])
+		if !isListContains(nodeExporterFsTypes, fsType) || strings.Contains(mp, "/var/lib/kubelet") {
+			continue
+		}
+		if device := l["device"]; device != "" {
+			if seenDevices[device] {
+				continue
+			}
+			seenDevices[device] = true
+		}
+
+		total := uint64(fs.size[mp])
+		used := subUint(fs.size[mp], fs.free[mp])
+		inodesTotal := uint64(fs.files[mp])
+		inodesUsed := subUint(fs.files[mp], fs.filesFree[mp])
+		diskInfo.Partitions = append(diskInfo.Partitions, &model.Partition{
+			MountPoint:  mp,
+			Fstype:      l["fstype"],
+			Total:       total,
+			Free:        uint64(fs.avail[mp]),
+			Used:        used,
+			UsedPercent: percent(used, total),
+
+			InodesTotal:       inodesTotal,
+			InodesUsed:        inodesUsed,
+			InodesUsedPercent: percent(inodesUsed, inodesTotal),
+			ReadOnly:          fs.readonly[mp] == 1,
+		})
+		diskInfo.Total += total
+		diskInfo.Used += used
+	}
+	diskInfo.UsedPercent = percent(diskInfo.Used, diskInfo.Total)
+}
+
+// isExcludedDevice 判断网卡是否被排除
+func isExcludedDevice(device string) bool {
+	for _, prefix := range nodeExporterExcludeDevices {
+		if strings.HasPrefix(device, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isListContains 判断列表是否包含字符串
+func isListContains(list []string, str string) bool {
+	for _, s := range list {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
+
+// subUint 计算 a-b，结果为负时返回 0
+func subUint(a, b float64) uint64 {
+	if a <= b {
+		return 0
+	}
+	return uint64(a - b)
+}
+
+// percent 计算百分比，分母为 0 时返回 0
+func percent(used, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(used) / float64(total) * 100
+}
@@ -0,0 +1,283 @@
+package internal
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ruanun/simple-server-status/internal/dashboard/config"
+	"github.com/ruanun/simple-server-status/pkg/model"
+	"go.uber.org/zap"
+)
+
+// nodeExporterFixture 两次抓取的 node_exporter 输出（节选）
+// 两次之间：CPU 总计增加 10 秒，其中空闲 + iowait 增加 7.5 秒；eth0 收 10000 字节、发 5000 字节
+var nodeExporterFixture = []string{`# HELP node_cpu_seconds_total Seconds the CPUs spent in each mode.
+# TYPE node_cpu_seconds_total counter
+node_cpu_seconds_total{cpu="0",mode="idle"} 1000
+node_cpu_seconds_total{cpu="0",mode="iowait"} 10
+node_cpu_seconds_total{cpu="0",mode="system"} 50
+node_cpu_seconds_total{cpu="0",mode="user"} 140
+node_cpu_seconds_total{cpu="1",mode="idle"} 1000
+node_cpu_seconds_total{cpu="1",mode="iowait"} 10
+node_cpu_seconds_total{cpu="1",mode="system"} 50
+node_cpu_seconds_total{cpu="1",mode="user"} 140
+# HELP node_memory_MemTotal_bytes Memory information field MemTotal_bytes.
+# TYPE node_memory_MemTotal_bytes gauge
+node_memory_MemTotal_bytes 8.589934592e+09
+node_memory_MemAvailable_bytes 6.442450944e+09
+node_memory_MemFree_bytes 1.073741824e+09
+node_memory_SwapTotal_bytes 2.147483648e+09
+node_memory_SwapFree_bytes 1.610612736e+09
+node_filesystem_size_bytes{device="/dev/sda1",fstype="ext4",mountpoint="/"} 1e+11
+node_filesystem_free_bytes{device="/dev/sda1",fstype="ext4",mountpoint="/"} 6e+10
+node_filesystem_avail_bytes{device="/dev/sda1",fstype="ext4",mountpoint="/"} 5.5e+10
+node_filesystem_files{device="/dev/sda1",fstype="ext4",mountpoint="/"} 1e+06
+node_filesystem_files_free{device="/dev/sda1",fstype="ext4",mountpoint="/"} 40000
+node_filesystem_readonly{device="/dev/sda1",fstype="ext4",mountpoint="/"} 0
+node_filesystem_size_bytes{device="/dev/sda1",fstype="ext4",mountpoint="/var/lib/docker"} 1e+11
+node_filesystem_free_bytes{device="/dev/sda1",fstype="ext4",mountpoint="/var/lib/docker"} 6e+10
+node_filesystem_avail_bytes{device="/dev/sda1",fstype="ext4",mountpoint="/var/lib/docker"} 5.5e+10
+node_filesystem_size_bytes{device="tmpfs",fstype="tmpfs",mountpoint="/run"} 1e+08
+node_filesystem_free_bytes{device="tmpfs",fstype="tmpfs",mountpoint="/run"} 1e+08
+node_filesystem_avail_bytes{device="tmpfs",fstype="tmpfs",mountpoint="/run"} 1e+08
+node_network_receive_bytes_total{device="eth0"} 100000
+node_network_transmit_bytes_total{device="eth0"} 50000
+node_network_receive_bytes_total{device="lo"} 999999
+node_network_transmit_bytes_total{device="lo"} 999999
+node_load1 0.5
+node_load5 0.25
+node_load15 0.125
+node_boot_time_seconds 1.7e+09
+node_time_seconds 1.7000036e+09
+node_uname_info{domainname="(none)",machine="x86_64",nodename="web-01",release="6.1.0-18-amd64",sysname="Linux",version="#1 SMP Debian"} 1
+node_os_info{id="debian",id_like="",name="Debian GNU/Linux",version_id="12"} 1
+`, `node_cpu_seconds_total{cpu="0",mode="idle"} 1003.5
+node_cpu_seconds_total{cpu="0",mode="iowait"} 10.25
+node_cpu_seconds_total{cpu="0",mode="system"} 50.5
+node_cpu_seconds_total{cpu="0",mode="user"} 140.75
+node_cpu_seconds_total{cpu="1",mode="idle"} 1003.5
+node_cpu_seconds_total{cpu="1",mode="iowait"} 10.25
+node_cpu_seconds_total{cpu="1",mode="system"} 50.5
+node_cpu_seconds_total{cpu="1",mode="user"} 140.75
+node_memory_MemTotal_bytes 8.589934592e+09
+node_memory_MemAvailable_bytes 6.442450944e+09
+node_memory_SwapTotal_bytes 2.147483648e+09
+node_memory_SwapFree_bytes 1.610612736e+09
+node_filesystem_size_bytes{device="/dev/sda1",fstype="ext4",mountpoint="/"} 1e+11
+node_filesystem_free_bytes{device="/dev/sda1",fstype="ext4",mountpoint="/"} 6e+10
+node_filesystem_avail_bytes{device="/dev/sda1",fstype="ext4",mountpoint="/"} 5.5e+10
+node_network_receive_bytes_total{device="eth0"} 110000
+node_network_transmit_bytes_total{device="eth0"} 55000
+node_load1 0.5
+node_boot_time_seconds 1.7e+09
+`}
+
+// TestParseExposition 测试 Prometheus 文本格式解析
+func TestParseExposition(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantErr    bool
+		wantName   string
+		wantLabels map[string]string
+		wantValue  float64
+	}{
+		{"无标签", "node_load1 0.5", false, "node_load1", map[string]string{}, 0.5},
+		{"带时间戳", "node_load1 1.5 1700000000000", false, "node_load1", map[string]string{}, 1.5},
+		{"多个标签", `node_cpu_seconds_total{cpu="0",mode="idle"} 12`, false, "node_cpu_seconds_total",
+			map[string]string{"cpu": "0", "mode": "idle"}, 12},
+		{"转义字符", `x{path="a\"b\\c",n="1\n2"} 3`, false, "x", map[string]string{"path": `a"b\c`, "n": "1\n2"}, 3},
+		{"值含空格和逗号", `x{version="#1 SMP, Debian",} 1`, false, "x", map[string]string{"version": "#1 SMP, Debian"}, 1},
+		{"科学计数法", "node_memory_MemTotal_bytes 8.589934592e+09", false, "node_memory_MemTotal_bytes", map[string]string{}, 8.589934592e+09},
+		{"NaN", "x NaN", false, "x", map[string]string{}, math.NaN()},
+		{"标签未闭合", `x{a="1" 3`, true, "", nil, 0},
+		{"缺少值", "x", true, "", nil, 0},
+		{"无效值", "x abc", true, "", nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			samples, err := parseExposition(strings.NewReader("# HELP x test\n\n" + tt.input + "\n"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v; wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(samples) != 1 {
+				t.Fatalf("样本数 = %d; want 1", len(samples))
+			}
+			s := samples[0]
+			if s.name != tt.wantName {
+				t.Errorf("name = %s; want %s", s.name, tt.wantName)
+			}
+			if len(s.labels) != len(tt.wantLabels) {
+				t.Errorf("labels = %v; want %v", s.labels, tt.wantLabels)
+			}
+			for k, v := range tt.wantLabels {
+				if s.labels[k] != v {
+					t.Errorf("label %s = %q; want %q", k, s.labels[k], v)
+				}
+			}
+			if math.IsNaN(tt.wantValue) {
+				if !math.IsNaN(s.value) {
+					t.Errorf("value = %v; want NaN", s.value)
+				}
+			} else if s.value != tt.wantValue {
+				t.Errorf("value = %v; want %v", s.value, tt.wantValue)
+			}
+		})
+	}
+}
+
+// TestNodeExporterScraper 测试抓取 node_exporter 并计算差值指标
+func TestNodeExporterScraper(t *testing.T) {
+	var calls atomic.Int32
+	exporter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1)) - 1
+		if n >= len(nodeExporterFixture) {
+			n = len(nodeExporterFixture) - 1
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = w.Write([]byte(nodeExporterFixture[n]))
+	}))
+	defer exporter.Close()
+
+	server := &config.ServerConfig{Id: "web-01", Mode: ServerModeNodeExporter, PullUrl: exporter.URL + "/metrics"}
+	scraper := newNodeExporterScraper(exporter.Client())
+
+	first, err := scraper.Scrape(context.Background(), server)
+	if err != nil {
+		t.Fatalf("第一次抓取失败: %v", err)
+	}
+
+	// 首次抓取没有差值，占用与速度为 0，其余指标直接映射
+	if first.CpuInfo.Percent != 0 || first.NetworkInfo.NetInSpeed != 0 {
+		t.Errorf("首次抓取不应有差值指标: cpu=%v in=%v", first.CpuInfo.Percent, first.NetworkInfo.NetInSpeed)
+	}
+	if len(first.CpuInfo.Info) != 1 || !strings.Contains(first.CpuInfo.Info[0], "x 2") {
+		t.Errorf("CPU 核心数错误: %v", first.CpuInfo.Info)
+	}
+	if first.VirtualMemoryInfo.Total != 8589934592 || first.VirtualMemoryInfo.Used != 2147483648 ||
+		first.VirtualMemoryInfo.UsedPercent != 25 {
+		t.Errorf("内存映射错误: %+v", first.VirtualMemoryInfo)
+	}
+	if first.VirtualMemoryInfo.Available != 6442450944 || first.VirtualMemoryInfo.Free != 1073741824 {
+		t.Errorf("内存明细映射错误: %+v", first.VirtualMemoryInfo)
+	}
+	if first.SwapMemoryInfo.Used != 536870912 || first.SwapMemoryInfo.UsedPercent != 25 {
+		t.Errorf("Swap 映射错误: %+v", first.SwapMemoryInfo)
+	}
+	// 同设备的绑定挂载只统计一次，tmpfs 被过滤
+	if len(first.DiskInfo.Partitions) != 1 || first.DiskInfo.Partitions[0].MountPoint != "/" {
+		t.Fatalf("分区过滤错误: %+v", first.DiskInfo.Partitions)
+	}
+	if first.DiskInfo.Total != 1e11 || first.DiskInfo.Used != 4e10 || first.DiskInfo.UsedPercent != 40 {
+		t.Errorf("磁盘映射错误: %+v", first.DiskInfo)
+	}
+	if first.DiskInfo.Partitions[0].Free != 5.5e10 {
+		t.Errorf("分区可用空间 = %d; want 55000000000", first.DiskInfo.Partitions[0].Free)
+	}
+	if root := first.DiskInfo.Partitions[0]; root.InodesTotal != 1e6 || root.InodesUsed != 960000 ||
+		root.ReadOnly || root.Health() != model.PartitionHealthInodesCritical {
+		t.Errorf("inode 映射错误: %+v", root)
+	}
+	// 回环网卡不计入流量
+	if first.NetworkInfo.NetInTransfer != 100000 || first.NetworkInfo.NetOutTransfer != 50000 {
+		t.Errorf("流量映射错误: %+v", first.NetworkInfo)
+	}
+	if first.HostInfo.AvgStat.Load1 != 0.5 || first.HostInfo.AvgStat.Load5 != 0.25 || first.HostInfo.AvgStat.Load15 != 0.125 {
+		t.Errorf("负载映射错误: %+v", first.HostInfo.AvgStat)
+	}
+	if first.HostInfo.BootTime != 1700000000 || first.HostInfo.Uptime != 3600 {
+		t.Errorf("启动时间映射错误: boot=%d uptime=%d", first.HostInfo.BootTime, first.HostInfo.Uptime)
+	}
+	if first.HostInfo.OS != "linux" || first.HostInfo.KernelArch != "x86_64" || first.HostInfo.KernelVersion != "6.1.0-18-amd64" {
+		t.Errorf("uname 映射错误: %+v", first.HostInfo)
+	}
+	if first.HostInfo.Platform != "debian" || first.HostInfo.PlatformVersion != "12" {
+		t.Errorf("os_info 映射错误: %+v", first.HostInfo)
+	}
+	if first.Ip != "127.0.0.1" {
+		t.Errorf("Ip = %s; want 127.0.0.1", first.Ip)
+	}
+
+	// 伪造 2 秒间隔，使速度计算结果确定
+	scraper.prevTime = scraper.prevTime.Add(-2 * time.Second)
+
+	second, err := scraper.Scrape(context.Background(), server)
+	if err != nil {
+		t.Fatalf("第二次抓取失败: %v", err)
+	}
+	if second.CpuInfo.Percent != 25 {
+		t.Errorf("CPU 占用 = %v; want 25", second.CpuInfo.Percent)
+	}
+	// 实际耗时略大于 2 秒，允许少量误差
+	if in := second.NetworkInfo.NetInSpeed; in < 4900 || in > 5000 {
+		t.Errorf("下载速度 = %d; want ~5000", in)
+	}
+	if out := second.NetworkInfo.NetOutSpeed; out < 2450 || out > 2500 {
+		t.Errorf("上传速度 = %d; want ~2500", out)
+	}
+}
+
+// TestNodeExporterScraper_CounterReset 测试计数器重置（被监控主机重启）时不产生异常速度
+func TestNodeExporterScraper_CounterReset(t *testing.T) {
+	scraper := newNodeExporterScraper(http.DefaultClient)
+	now := time.Now()
+
+	before, _ := parseExposition(strings.NewReader(nodeExporterFixture[1]))
+	scraper.convert(before, now)
+
+	after, _ := parseExposition(strings.NewReader(nodeExporterFixture[0]))
+	info := scraper.convert(after, now.Add(time.Second))
+
+	if info.NetworkInfo.NetInSpeed != 0 || info.NetworkInfo.NetOutSpeed != 0 {
+		t.Errorf("计数器回退后速度应为 0: %+v", info.NetworkInfo)
+	}
+	if info.CpuInfo.Percent != 0 {
+		t.Errorf("计数器回退后 CPU 占用应为 0: %v", info.CpuInfo.Percent)
+	}
+}
+
+// TestPullManager_NodeExporter 测试 node_exporter 模式通过拉取管理器写入状态
+func TestPullManager_NodeExporter(t *testing.T) {
+	wsm, statusMap, _ := newTestWebSocketManager(t)
+
+	exporter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-AUTH-SECRET") != "" {
+			t.Error("不应向 node_exporter 发送认证密钥")
+		}
+		_, _ = w.Write([]byte(nodeExporterFixture[0]))
+	}))
+	defer exporter.Close()
+
+	pm := NewPullManager(wsm, nil, zap.NewNop().Sugar())
+	defer pm.Close()
+	pm.Reload([]*config.ServerConfig{{Id: "web-01", Mode: ServerModeNodeExporter, PullUrl: exporter.URL, PullInterval: 1}})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, ok := statusMap.Get("web-01"); ok {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	info, ok := statusMap.Get("web-01")
+	if !ok {
+		t.Fatal("抓取结果未写入状态表")
+	}
+	if info.Name != "Web 01" || info.VirtualMemoryInfo.UsedPercent != 25 {
+		t.Errorf("抓取结果未按上报流程处理: %+v", info)
+	}
+	if conn, ok := wsm.GetConnectionInfo("web-01"); !ok || conn.Transport != TransportNodeExporter {
+		t.Errorf("连接信息错误: %+v", conn)
+	}
+}
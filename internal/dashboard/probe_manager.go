@@ -0,0 +1,196 @@
+package internal
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+
+	"github.com/ruanun/simple-server-status/internal/dashboard/config"
+	"github.com/ruanun/simple-server-status/pkg/model"
+)
+
+// 网络探测任务的默认值与上限，与 Agent 端一致
+const (
+	defaultProbeInterval  = 60   // 秒
+	defaultProbeCount     = 5    // 每轮探测次数
+	defaultProbeTimeoutMs = 1000 // 毫秒
+	maxProbeCount         = 100
+	probeSpacingMs        = 200 // Agent 每轮中相邻两次探测的间隔
+)
+
+// probeHistorySize 每个服务器到每个目标保留的历史记录条数；按默认 60 秒的探测周期约为 24 小时
+const probeHistorySize = 1440
+
+// probeSeries 一台服务器到一个探测目标的历史
+type probeSeries struct {
+	latest *model.ProbeResult
+	points []*model.ProbePoint
+}
+
+// ProbeManager 网络探测任务与结果
+// 保存配置的探测任务，为每台服务器生成下发给 Agent 的指令，并记录各服务器到各目标的结果历史（只保存在内存中）
+type ProbeManager struct {
+	mu      sync.RWMutex
+	size    int
+	probes  []*config.ProbeConfig
+	servers map[string]map[string]*probeSeries // serverID -> 探测名称 -> 历史
+}
+
+// NewProbeManager 创建网络探测管理器，每个服务器到每个目标最多保留 size 条记录
+func NewProbeManager(size int) *ProbeManager {
+	return &ProbeManager{
+		size:    size,
+		servers: make(map[string]map[string]*probeSeries),
+	}
+}
+
+// SetProbes 替换全部探测任务（启动及配置热加载时调用）
+// 删除或不再分配给某台服务器的任务、目标发生变化的任务，其历史一并清空
+func (m *ProbeManager) SetProbes(probes []*config.ProbeConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	changed := make(map[string]bool)
+	for _, old := range m.probes {
+		i := slices.IndexFunc(probes, func(p *config.ProbeConfig) bool { return p.Name == old.Name })
+		if i < 0 || probes[i].Type != old.Type || probes[i].Host != old.Host || probes[i].Port != old.Port {
+			changed[old.Name] = true
+		}
+	}
+	m.probes = probes
+
+	for serverID, series := range m.servers {
+		for name := range series {
+			if changed[name] || m.probe(serverID, name) == nil {
+				delete(series, name)
+			}
+		}
+		if len(series) == 0 {
+			delete(m.servers, serverID)
+		}
+	}
+}
+
+// Command 返回下发给服务器的探测指令；没有分配任务时指令中的任务为空，Agent 据此清空已有任务
+func (m *ProbeManager) Command(serverID string) *model.AgentCommand {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	cmd := &model.AgentCommand{Type: model.CommandProbes}
+	for _, p := range m.probes {
+		if probeAssigned(p, serverID) {
+			cmd.Probes = append(cmd.Probes, &model.ProbeTask{
+				Name:      p.Name,
+				Type:      p.Type,
+				Host:      p.Host,
+				Port:      p.Port,
+				Interval:  p.Interval,
+				Count:     p.Count,
+				TimeoutMs: p.TimeoutMs,
+			})
+		}
+	}
+	return cmd
+}
+
+// Record 记录一次上报中的探测结果
+// 同一结果随后续上报重复发送时只记录一次；未分配给该服务器的任务（Agent 尚未收到新任务时）忽略
+func (m *ProbeManager) Record(serverID string, results []*model.ProbeResult) {
+	if len(results) == 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, r := range results {
+		if m.probe(serverID, r.Name) == nil {
+			continue
+		}
+		series := m.servers[serverID]
+		if series == nil {
+			series = make(map[string]*probeSeries)
+			m.servers[serverID] = series
+		}
+		s := series[r.Name]
+		if s == nil {
+			s = &probeSeries{}
+			series[r.Name] = s
+		}
+		if s.latest != nil && r.ProbedAt <= s.latest.ProbedAt {
+			continue
+		}
+		s.latest = r
+		s.points = append(s.points, &model.ProbePoint{
+			ProbedAt:    r.ProbedAt,
+			RTTMs:       r.RTTMs,
+			JitterMs:    r.JitterMs,
+			LossPercent: r.LossPercent,
+			Error:       r.Error,
+		})
+		if len(s.points) > m.size {
+			s.points = s.points[len(s.points)-m.size:]
+		}
+	}
+}
+
+// Matrix 返回各服务器到各目标最近一轮的结果；serverName 用于查询服务器的展示名称
+// 只包含已上报过结果的服务器，按服务器 ID 排序
+func (m *ProbeManager) Matrix(serverName func(serverID string) string) *model.ProbeMatrix {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	matrix := &model.ProbeMatrix{
+		Probes:  make([]string, 0, len(m.probes)),
+		Servers: make([]*model.ProbeMatrixRow, 0, len(m.servers)),
+	}
+	for _, p := range m.probes {
+		matrix.Probes = append(matrix.Probes, p.Name)
+	}
+	for serverID, series := range m.servers {
+		row := &model.ProbeMatrixRow{
+			Id:      serverID,
+			Name:    serverName(serverID),
+			Results: make(map[string]*model.ProbeResult, len(series)),
+		}
+		for name, s := range series {
+			row.Results[name] = s.latest
+		}
+		matrix.Servers = append(matrix.Servers, row)
+	}
+	slices.SortFunc(matrix.Servers, func(a, b *model.ProbeMatrixRow) int { return cmp.Compare(a.Id, b.Id) })
+	return matrix
+}
+
+// History 返回服务器到一个探测目标的历史
+func (m *ProbeManager) History(serverID, name string) []*model.ProbePoint {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s := m.servers[serverID][name]
+	if s == nil {
+		return []*model.ProbePoint{}
+	}
+	return append([]*model.ProbePoint(nil), s.points...)
+}
+
+// Remove 删除服务器的探测历史
+func (m *ProbeManager) Remove(serverID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.servers, serverID)
+}
+
+// probe 返回分配给服务器的指定任务，调用方需持有锁
+func (m *ProbeManager) probe(serverID, name string) *config.ProbeConfig {
+	for _, p := range m.probes {
+		if p.Name == name && probeAssigned(p, serverID) {
+			return p
+		}
+	}
+	return nil
+}
+
+// probeAssigned 任务是否分配给服务器；未指定服务器的任务分配给全部服务器
+func probeAssigned(p *config.ProbeConfig, serverID string) bool {
+	return len(p.Servers) == 0 || slices.Contains(p.Servers, serverID)
+}
@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/ruanun/simple-server-status/internal/dashboard/config"
+	"github.com/ruanun/simple-server-status/pkg/model"
+)
+
+// TestProbeManager 测试任务分配、结果去重、容量限制与任务变化
+func TestProbeManager(t *testing.T) {
+	m := NewProbeManager(3)
+	gw := &config.ProbeConfig{Name: "gw", Type: "icmp", Host: "10.0.0.1"}
+	db := &config.ProbeConfig{Name: "db", Type: "tcp", Host: "10.0.0.2", Port: 5432, Servers: []string{"s1"}}
+	m.SetProbes([]*config.ProbeConfig{gw, db})
+
+	// 未指定服务器的任务分配给全部服务器
+	if cmd := m.Command("s1"); cmd.Type != model.CommandProbes || len(cmd.Probes) != 2 {
+		t.Errorf("Command(s1) = %+v", cmd)
+	}
+	if cmd := m.Command("s2"); len(cmd.Probes) != 1 || cmd.Probes[0].Name != "gw" {
+		t.Errorf("Command(s2) = %+v", cmd)
+	}
+
+	result := func(name string, probedAt int64) *model.ProbeResult {
+		return &model.ProbeResult{Name: name, RTTMs: float64(probedAt % 100), ProbedAt: probedAt}
+	}
+	// 同一结果随多次上报重复发送时只记录一次；未分配给该服务器的任务忽略
+	m.Record("s1", []*model.ProbeResult{result("gw", 100), result("db", 100)})
+	m.Record("s1", []*model.ProbeResult{result("gw", 100), result("db", 100)})
+	m.Record("s1", []*model.ProbeResult{result("gw", 160)})
+	m.Record("s2", []*model.ProbeResult{result("gw", 100), result("db", 100)})
+	if got := m.History("s1", "gw"); len(got) != 2 || got[1].ProbedAt != 160 {
+		t.Errorf("History(s1, gw) = %+v", got)
+	}
+	matrix := m.Matrix(func(id string) string { return "name-" + id })
+	if len(matrix.Servers) != 2 || matrix.Servers[0].Id != "s1" || matrix.Servers[0].Name != "name-s1" ||
+		matrix.Servers[0].Results["gw"].ProbedAt != 160 || matrix.Servers[1].Results["db"] != nil {
+		t.Errorf("Matrix() = %+v", matrix)
+	}
+
+	// 超出容量时丢弃最早的记录
+	m.Record("s1", []*model.ProbeResult{result("gw", 220)})
+	m.Record("s1", []*model.ProbeResult{result("gw", 280)})
+	if got := m.History("s1", "gw"); len(got) != 3 || got[0].ProbedAt != 160 {
+		t.Errorf("容量限制后 = %+v", got)
+	}
+
+	// 目标变化的任务清空历史，不再分配的任务删除历史，其余保留
+	m.SetProbes([]*config.ProbeConfig{
+		{Name: "gw", Type: "icmp", Host: "10.0.0.254"},
+		{Name: "db", Type: "tcp", Host: "10.0.0.2", Port: 5432, Servers: []string{"s1"}},
+	})
+	if got := m.History("s1", "gw"); len(got) != 0 {
+		t.Errorf("目标变化后 gw = %+v", got)
+	}
+	if got := m.History("s1", "db"); len(got) != 1 {
+		t.Errorf("未变化的 db = %+v", got)
+	}
+	m.SetProbes([]*config.ProbeConfig{{Name: "db", Type: "tcp", Host: "10.0.0.2", Port: 5432, Servers: []string{"s2"}}})
+	if got := m.History("s1", "db"); len(got) != 0 {
+		t.Errorf("不再分配后 db = %+v", got)
+	}
+
+	m.Record("s2", []*model.ProbeResult{result("db", 400)})
+	m.Remove("s2")
+	if matrix = m.Matrix(func(id string) string { return id }); len(matrix.Servers) != 0 {
+		t.Errorf("删除后 = %+v", matrix.Servers)
+	}
+}
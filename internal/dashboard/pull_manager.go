@@ -0,0 +1,209 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/ruanun/simple-server-status/internal/dashboard/config"
+	"github.com/ruanun/simple-server-status/internal/dashboard/global/constant"
+	"github.com/ruanun/simple-server-status/pkg/model"
+	"go.uber.org/zap"
+)
+
+// 服务器数据来源
+const (
+	ServerModePush         = "push"          // Agent 主动推送（WebSocket / HTTP）
+	ServerModePull         = "pull"          // 面板定时拉取 Agent 暴露的快照
+	ServerModeNodeExporter = "node_exporter" // 面板定时抓取 Prometheus node_exporter，无需安装 Agent
+)
+
+// maxPullTimeout 单次拉取的最大超时时间
+const maxPullTimeout = 10 * time.Second
+
+// maxPullSnapshotSize Agent 快照响应体大小上限，需容纳各核心占用、进程排行、网卡、分区、传感器、检查与探测结果等完整数据
+const maxPullSnapshotSize = 1 << 20
+
+// pullTask 单个服务器的拉取任务
+type pullTask struct {
+	mode     string
+	url      string
+	secret   string
+	interval int
+	cancel   context.CancelFunc
+}
+
+// PullManager 管理由面板主动拉取数据的服务器（pull 与 node_exporter 模式）
+// 面板按 pullInterval 定时请求 pullUrl，结果与推送上报走相同的处理流程
+type PullManager struct {
+	wsm          *WebSocketManager
+	errorHandler *ErrorHandler
+	logger       *zap.SugaredLogger
+	client       *http.Client
+
+	mu    sync.Mutex
+	tasks map[string]*pullTask
+	wg    sync.WaitGroup
+}
+
+// NewPullManager 创建拉取管理器
+func NewPullManager(wsm *WebSocketManager, errorHandler *ErrorHandler, logger *zap.SugaredLogger) *PullManager {
+	return &PullManager{
+		wsm:          wsm,
+		errorHandler: errorHandler,
+		logger:       logger,
+		client:       &http.Client{},
+		tasks:        make(map[string]*pullTask),
+	}
+}
+
+// Reload 根据服务器配置启动、重启或停止拉取任务
+func (pm *PullManager) Reload(servers []*config.ServerConfig) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	wanted := make(map[string]*config.ServerConfig)
+	for _, server := range servers {
+		if (server.Mode == ServerModePull || server.Mode == ServerModeNodeExporter) && server.PullUrl != "" {
+			wanted[server.Id] = server
+		}
+	}
+
+	// 停止已删除或配置变化的任务；任务持有启动时的配置，密钥变化时同样需要重启
+	for id, task := range pm.tasks {
+		server, ok := wanted[id]
+		if ok && server.Mode == task.mode && server.PullUrl == task.url && server.Secret == task.secret && server.PullInterval == task.interval {
+			continue
+		}
+		task.cancel()
+		delete(pm.tasks, id)
+		pm.logger.Infof("停止拉取任务 - ServerID: %s", id)
+	}
+
+	// 启动新增的任务
+	for id, server := range wanted {
+		if _, exists := pm.tasks[id]; exists {
+			continue
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		pm.tasks[id] = &pullTask{mode: server.Mode, url: server.PullUrl, secret: server.Secret, interval: server.PullInterval, cancel: cancel}
+
+		pm.wg.Add(1)
+		go pm.run(ctx, server)
+		pm.logger.Infof("启动拉取任务 - ServerID: %s, 模式: %s, URL: %s, 间隔: %ds", id, server.Mode, server.PullUrl, server.PullInterval)
+	}
+}
+
+// Close 停止所有拉取任务
+func (pm *PullManager) Close() {
+	pm.mu.Lock()
+	for id, task := range pm.tasks {
+		task.cancel()
+		delete(pm.tasks, id)
+	}
+	pm.mu.Unlock()
+
+	pm.wg.Wait()
+}
+
+// run 定时拉取单个服务器
+func (pm *PullManager) run(ctx context.Context, server *config.ServerConfig) {
+	defer pm.wg.Done()
+
+	interval := time.Duration(server.PullInterval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	timeout := interval
+	if timeout > maxPullTimeout {
+		timeout = maxPullTimeout
+	}
+
+	// node_exporter 需要跨次保存计数器以计算速率，抓取器随任务创建
+	transport := TransportPull
+	fetch := pm.fetch
+	if server.Mode == ServerModeNodeExporter {
+		transport = TransportNodeExporter
+		fetch = newNodeExporterScraper(pm.client).Scrape
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		pm.pullOnce(ctx, server, transport, timeout, fetch)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pullOnce 拉取一次并应用到服务器状态
+func (pm *PullManager) pullOnce(ctx context.Context, server *config.ServerConfig, transport string, timeout time.Duration,
+	fetch func(context.Context, *config.ServerConfig) (*model.ServerInfo, error)) {
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	info, err := fetch(reqCtx, server)
+	cancel()
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		pm.logger.Debugf("拉取服务器信息失败 - ServerID: %s, Error: %v", server.Id, err)
+		if pm.errorHandler != nil {
+			pm.errorHandler.RecordError(NewNetworkError("拉取服务器信息失败",
+				fmt.Sprintf("ServerID: %s, Error: %v", server.Id, err)))
+		}
+		pm.wsm.incrementErrorCount(server.Id)
+		return
+	}
+
+	host := ""
+	if u, err := url.Parse(server.PullUrl); err == nil {
+		host = u.Hostname()
+	}
+	pm.wsm.touchConnection(server.Id, transport, host, 1)
+	pm.wsm.applyServerInfo(server.Id, info)
+}
+
+// fetch 请求 Agent 的服务器信息快照
+func (pm *PullManager) fetch(ctx context.Context, server *config.ServerConfig) (*model.ServerInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.PullUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(constant.HeaderId, server.Id)
+	req.Header.Set(constant.HeaderSecret, server.Secret)
+
+	resp, err := pm.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("状态码 %d", resp.StatusCode)
+	}
+
+	// 多读一个字节以区分恰好达到上限与超过上限，超过时直接报错而不是解析被截断的 JSON
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPullSnapshotSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+	if len(body) > maxPullSnapshotSize {
+		return nil, fmt.Errorf("响应过大（超过 %d 字节）", maxPullSnapshotSize)
+	}
+
+	var info model.ServerInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return &info, nil
+}
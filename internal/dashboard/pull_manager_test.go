@@ -0,0 +1,166 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ruanun/simple-server-status/internal/dashboard/config"
+	"github.com/ruanun/simple-server-status/internal/dashboard/global/constant"
+	"go.uber.org/zap"
+)
+
+// TestPullManager 测试定时拉取 Agent 快照并计入在线统计
+func TestPullManager(t *testing.T) {
+	wsm, statusMap, _ := newTestWebSocketManager(t)
+
+	var requests atomic.Int32
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get(constant.HeaderId) != "web-01" || r.Header.Get(constant.HeaderSecret) != "secret-123456" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"cpuInfo":{"percent":33},"loc":"US"}`))
+	}))
+	defer agent.Close()
+
+	logger := zap.NewNop().Sugar()
+	pm := NewPullManager(wsm, NewErrorHandler(logger), logger)
+	defer pm.Close()
+
+	pm.Reload([]*config.ServerConfig{
+		{Id: "web-01", Secret: "secret-123456", Mode: ServerModePull, PullUrl: agent.URL, PullInterval: 1},
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, ok := statusMap.Get("web-01"); ok {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	info, ok := statusMap.Get("web-01")
+	if !ok {
+		t.Fatal("拉取结果未写入状态表")
+	}
+	if info.CpuInfo == nil || info.CpuInfo.Percent != 33 || info.Loc != "jp" || info.Name != "Web 01" {
+		t.Errorf("拉取结果未按上报流程处理: %+v", info)
+	}
+
+	conn, ok := wsm.GetConnectionInfo("web-01")
+	if !ok || conn.Transport != TransportPull {
+		t.Fatalf("拉取连接信息错误: %+v", conn)
+	}
+
+	// 切换回推送模式后停止拉取
+	pm.Reload([]*config.ServerConfig{{Id: "web-01", Secret: "secret-123456", Mode: ServerModePush}})
+	before := requests.Load()
+	time.Sleep(1500 * time.Millisecond)
+	if after := requests.Load(); after != before {
+		t.Errorf("切换模式后仍在拉取: %d -> %d", before, after)
+	}
+}
+
+// TestPullManager_AuthFailure 测试拉取失败时不更新状态
+func TestPullManager_AuthFailure(t *testing.T) {
+	wsm, statusMap, _ := newTestWebSocketManager(t)
+
+	var requests atomic.Int32
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer agent.Close()
+
+	logger := zap.NewNop().Sugar()
+	errorHandler := NewErrorHandler(logger)
+	pm := NewPullManager(wsm, errorHandler, logger)
+
+	pm.Reload([]*config.ServerConfig{
+		{Id: "web-01", Secret: "wrong", Mode: ServerModePull, PullUrl: agent.URL, PullInterval: 1},
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) && requests.Load() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	pm.Close()
+
+	if _, ok := statusMap.Get("web-01"); ok {
+		t.Error("拉取失败不应写入状态")
+	}
+	if wsm.SessionLength() != 0 {
+		t.Errorf("SessionLength() = %d; want 0", wsm.SessionLength())
+	}
+	recent := errorHandler.GetRecentErrors(1)
+	if len(recent) != 1 || recent[0].Type != ErrorTypeNetwork {
+		t.Errorf("拉取失败未记录网络错误: %+v", recent)
+	}
+}
+
+// TestPullManager_Fetch 测试完整快照超过 WebSocket 消息上限时仍可解析，超过拉取上限时报错
+func TestPullManager_Fetch(t *testing.T) {
+	wsm, _, _ := newTestWebSocketManager(t)
+	logger := zap.NewNop().Sugar()
+	pm := NewPullManager(wsm, NewErrorHandler(logger), logger)
+	defer pm.Close()
+
+	var body string
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer agent.Close()
+	server := &config.ServerConfig{Id: "web-01", Secret: "secret-123456", Mode: ServerModePull, PullUrl: agent.URL}
+
+	// 64KB 的快照：远超 10KB，但属于正常大小
+	body = `{"loc":"` + strings.Repeat("x", 64<<10) + `","cpuInfo":{"percent":12}}`
+	info, err := pm.fetch(context.Background(), server)
+	if err != nil || info.CpuInfo == nil || info.CpuInfo.Percent != 12 {
+		t.Fatalf("fetch() = %+v, %v; want 解析成功", info, err)
+	}
+
+	body = `{"loc":"` + strings.Repeat("x", maxPullSnapshotSize) + `"}`
+	if _, err := pm.fetch(context.Background(), server); err == nil || !strings.Contains(err.Error(), "响应过大") {
+		t.Errorf("fetch() error = %v; want 响应过大", err)
+	}
+}
+
+// TestPullManager_ReloadSecret 测试只修改密钥时拉取任务改用新密钥
+func TestPullManager_ReloadSecret(t *testing.T) {
+	wsm, _, _ := newTestWebSocketManager(t)
+
+	var lastSecret atomic.Value
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastSecret.Store(r.Header.Get(constant.HeaderSecret))
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer agent.Close()
+
+	logger := zap.NewNop().Sugar()
+	pm := NewPullManager(wsm, NewErrorHandler(logger), logger)
+	defer pm.Close()
+
+	waitSecret := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if got, _ := lastSecret.Load().(string); got == want {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("拉取请求的密钥 = %v; want %s", lastSecret.Load(), want)
+	}
+
+	pm.Reload([]*config.ServerConfig{{Id: "web-01", Secret: "old-secret", Mode: ServerModePull, PullUrl: agent.URL, PullInterval: 1}})
+	waitSecret("old-secret")
+	pm.Reload([]*config.ServerConfig{{Id: "web-01", Secret: "new-secret", Mode: ServerModePull, PullUrl: agent.URL, PullInterval: 1}})
+	waitSecret("new-secret")
+}
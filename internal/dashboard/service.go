@@ -23,6 +23,7 @@ type DashboardService struct {
 	// 核心组件
 	httpServer        *http.Server
 	wsManager         *WebSocketManager
+	pullManager       *PullManager
 	frontendWsManager *FrontendWebSocketManager
 	errorHandler      *ErrorHandler
 	configValidator   *ConfigValidator
@@ -96,9 +97,12 @@ func (s *DashboardService) initComponents() error {
 	s.wsManager = NewWebSocketManager(s.logger, s.errorHandler, serverConfigAdapter, serverStatusAdapter, s)
 	s.logger.Info("WebSocket 管理器已初始化")
 
+	// 3.1 初始化拉取管理器（反向拉取模式的服务器）
+	s.pullManager = NewPullManager(s.wsManager, s.errorHandler, s.logger)
+
 	// 4. 初始化前端 WebSocket 管理器
 	serverStatusIteratorAdapter := &serverStatusIteratorAdapter{statusMap: s.serverStatusMap}
-	s.frontendWsManager = NewFrontendWebSocketManager(s.logger, s.errorHandler, serverStatusIteratorAdapter, s)
+	s.frontendWsManager = NewFrontendWebSocketManager(s.logger, s.errorHandler, serverStatusIteratorAdapter, s, s.wsManager)
 	s.logger.Info("前端 WebSocket 管理器已初始化")
 
 	// 5. 设置 WebSocket 路由
@@ -130,7 +134,7 @@ func (s *DashboardService) setupAPIRoutes() {
 	serverStatusMapAdapter := &serverStatusMapAdapter{statusMap: s.serverStatusMap}
 	serverConfigMapAdapter := &serverConfigMapAdapter{servers: s.servers}
 	configValidatorAdapter := &configValidatorAdapter{validator: s.configValidator}
-	handler.InitApi(s.ginEngine, s.wsManager, s, s.logger, serverStatusMapAdapter, serverConfigMapAdapter, configValidatorAdapter)
+	handler.InitApi(s.ginEngine, s.wsManager, s.wsManager, s.wsManager, s, s.logger, serverStatusMapAdapter, serverConfigMapAdapter, configValidatorAdapter)
 	s.logger.Info("API 路由已初始化")
 }
 
@@ -150,6 +154,10 @@ type serverStatusAdapter struct {
 	statusMap cmap.ConcurrentMap[string, *model.ServerInfo]
 }
 
+func (a *serverStatusAdapter) Get(key string) (*model.ServerInfo, bool) {
+	return a.statusMap.Get(key)
+}
+
 func (a *serverStatusAdapter) Set(key string, val *model.ServerInfo) {
 	a.statusMap.Set(key, val)
 }
@@ -240,6 +248,9 @@ func (s *DashboardService) Start() error {
 		}
 	}()
 
+	// 启动反向拉取任务
+	s.pullManager.Reload(s.config.Servers)
+
 	s.logger.Info("Dashboard 服务已启动")
 	return nil
 }
@@ -257,6 +268,9 @@ func (s *DashboardService) Stop(timeout time.Duration) error {
 
 	// 2. 关闭 WebSocket 管理器
 	s.logger.Info("关闭 WebSocket 管理器...")
+	if s.pullManager != nil {
+		s.pullManager.Close()
+	}
 	if s.wsManager != nil {
 		s.wsManager.Close()
 	}
@@ -329,6 +343,8 @@ func (s *DashboardService) ReloadServers(newServers []*config.ServerConfig) {
 	// 4. 清理被删除服务器的状态数据
 	for _, serverID := range removedServerIDs {
 		s.serverStatusMap.Remove(serverID)
+		s.wsManager.RemoveCheckHistory(serverID)
+		s.wsManager.RemoveProbeHistory(serverID)
 		s.logger.Infof("配置热加载：删除服务器 %s 的状态数据", serverID)
 	}
 
@@ -340,10 +356,21 @@ func (s *DashboardService) ReloadServers(newServers []*config.ServerConfig) {
 		s.servers.Set(server.Id, server)
 	}
 
+	// 6. 同步反向拉取任务
+	if s.pullManager != nil {
+		s.pullManager.Reload(newServers)
+	}
+
 	s.logger.Infof("已重新加载 %d 个服务器配置，删除 %d 个废弃服务器",
 		len(newServers), len(removedServerIDs))
 }
 
+// ReloadProbes 重新加载网络探测任务并下发给 Agent（用于配置热加载）
+func (s *DashboardService) ReloadProbes(probes []*config.ProbeConfig) {
+	s.wsManager.SetProbes(probes)
+	s.logger.Infof("已重新加载 %d 个网络探测任务", len(probes))
+}
+
 // GetServerStatusMap 获取服务器状态 map（用于外部访问）
 func (s *DashboardService) GetServerStatusMap() cmap.ConcurrentMap[string, *model.ServerInfo] {
 	return s.serverStatusMap
@@ -376,9 +403,8 @@ func (s *DashboardService) GetStats() map[string]interface{} {
 
 	// 计算在线/离线服务器
 	onlineCount := 0
-	now := time.Now().Unix()
 	for item := range s.serverStatusMap.IterBuffered() {
-		if now-item.Val.LastReportTime <= int64(s.config.ReportTimeIntervalMax) {
+		if s.wsManager != nil && s.wsManager.IsOnline(item.Val) {
 			onlineCount++
 		}
 	}
@@ -1,17 +1,22 @@
 package internal
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"math"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/olahol/melody"
 	"github.com/ruanun/simple-server-status/internal/dashboard/config"
 	"github.com/ruanun/simple-server-status/internal/dashboard/global/constant"
+	"github.com/ruanun/simple-server-status/internal/shared/heartbeat"
 	"github.com/ruanun/simple-server-status/pkg/model"
 )
 
@@ -22,6 +27,7 @@ type ServerConfigProvider interface {
 
 // ServerStatusProvider 服务器状态提供者接口
 type ServerStatusProvider interface {
+	Get(key string) (*model.ServerInfo, bool)
 	Set(key string, val *model.ServerInfo)
 }
 
@@ -39,17 +45,57 @@ const (
 	Reconnecting
 )
 
+// 上报传输方式
+const (
+	TransportWebSocket    = "ws"
+	TransportHTTP         = "http"
+	TransportPull         = "pull"
+	TransportNodeExporter = "node_exporter"
+	TransportIngest       = "ingest"
+)
+
+// 离线判定策略
+const (
+	OfflinePolicyReport    = "report"    // 超过 reportTimeIntervalMax 未上报视为离线
+	OfflinePolicyHeartbeat = "heartbeat" // 此外 WebSocket 连接断开或上一次 ping 未收到 pong 时也立即视为离线
+)
+
+// 心跳默认值
+const (
+	defaultHeartbeatInterval = 15 // 秒
+	defaultMaxMissedPongs    = 3
+	defaultMaxMessageSize    = 256              // KB，可容纳开启进程、传感器、可用性检查等全部采集器的完整上报
+	heartbeatWriteWait       = 10 * time.Second // 发送 ping 的超时
+)
+
 // ConnectionInfo 连接信息
 type ConnectionInfo struct {
-	ServerID      string           `json:"server_id"`
-	Session       *melody.Session  `json:"-"`
-	Status        ConnectionStatus `json:"status"`
-	ConnectedAt   time.Time        `json:"connected_at"`
-	LastHeartbeat time.Time        `json:"last_heartbeat"`
-	LastMessage   time.Time        `json:"last_message"`
-	IP            string           `json:"ip"`
-	MessageCount  int64            `json:"message_count"`
-	ErrorCount    int64            `json:"error_count"`
+	ServerID      string             `json:"server_id"`
+	Session       *melody.Session    `json:"-"`
+	Status        ConnectionStatus   `json:"status"`
+	Transport     string             `json:"transport"` // ws、http、pull、node_exporter 或 ingest
+	ConnectedAt   time.Time          `json:"connected_at"`
+	LastHeartbeat time.Time          `json:"last_heartbeat"`
+	LastMessage   time.Time          `json:"last_message"`
+	IP            string             `json:"ip"`
+	MessageCount  int64              `json:"message_count"`
+	ErrorCount    int64              `json:"error_count"`
+	InventoryHash string             `json:"inventory_hash,omitempty"` // 最近一次完整帧的哈希（分帧上报）
+	Encoding      string             `json:"encoding,omitempty"`       // 上报编码（WebSocket 握手时协商）
+	Link          *model.LinkQuality `json:"link,omitempty"`           // 面板向 Agent 发送 ping 测得的链路质量（WebSocket）
+	AgentLink     *model.LinkQuality `json:"agent_link,omitempty"`     // Agent 向面板发送 ping 测得的链路质量，随上报携带
+	// 与 Transport 不同的其他上报方式（如 WebSocket 连接期间的自定义上报）的活动，不影响连接本身的心跳与消息计数
+	OtherTransports map[string]TransportActivity `json:"other_transports,omitempty"`
+	codec           model.Codec
+	link            *heartbeat.Tracker
+	resyncPending   bool // 已要求 Agent 重新发送完整帧，收到完整帧前不再重复要求
+	pongPayload     bool // 已在读取协程中接管 pong 处理，可按载荷将 pong 与 ping 对应
+}
+
+// TransportActivity 某种上报方式的活动统计
+type TransportActivity struct {
+	LastMessage  time.Time `json:"last_message"`
+	MessageCount int64     `json:"message_count"`
 }
 
 // WebSocketManager Agent 端 WebSocket 管理器
@@ -62,7 +108,9 @@ type WebSocketManager struct {
 	ctx               context.Context
 	cancel            context.CancelFunc
 	heartbeatInterval time.Duration
-	heartbeatTimeout  time.Duration
+	heartbeatTimeout  time.Duration // 无会话的上报方式超过该时间未上报时移出连接列表
+	maxMissedPongs    int
+	offlinePolicy     string
 	maxMessageSize    int64
 	logger            interface {
 		Infof(string, ...interface{})
@@ -76,12 +124,19 @@ type WebSocketManager struct {
 	serverConfigs ServerConfigProvider
 	serverStatus  ServerStatusProvider
 	configAccess  ConfigAccessor
+	ingestMu      sync.Mutex // 串行化自定义上报的读取-合并-写入
+	checkHistory  *CheckHistory
+	probes        *ProbeManager
 
 	// 统计信息
 	totalConnections    int64
 	totalDisconnections int64
 	totalMessages       int64
 	totalErrors         int64
+	totalBytes          int64
+	inventoryFrames     int64
+	metricFrames        int64
+	legacyFrames        int64
 }
 
 // NewWebSocketManager 创建新的WebSocket管理器
@@ -92,8 +147,19 @@ func NewWebSocketManager(logger interface {
 	Info(...interface{})
 }, errorHandler *ErrorHandler, serverConfigs ServerConfigProvider, serverStatus ServerStatusProvider, configAccess ConfigAccessor) *WebSocketManager {
 	ctx, cancel := context.WithCancel(context.Background())
+	hb := configAccess.GetConfig().Heartbeat
+	heartbeatInterval := time.Duration(cmp.Or(hb.Interval, defaultHeartbeatInterval)) * time.Second
+	maxMissedPongs := cmp.Or(hb.MaxMissedPongs, defaultMaxMissedPongs)
+	maxMessageSize := int64(cmp.Or(configAccess.GetConfig().MaxMessageSize, defaultMaxMessageSize)) * 1024
+
 	m := melody.New()
-	m.Config.MaxMessageSize = 1024 * 10 // 10KB
+	m.Config.MaxMessageSize = maxMessageSize
+	// 支持 permessage-deflate 压缩，由 Agent 在握手时请求
+	m.Upgrader.EnableCompression = true
+	// 由心跳循环发送带载荷的 ping 并统计往返时间，不使用 melody 自带的 ping（其 pong 无法与 ping 对应）；
+	// melody 只在收到 pong 时延长读超时，读超时需长于断开前允许的最长无 pong 时间
+	m.Config.PingPeriod = time.Duration(math.MaxInt64)
+	m.Config.PongWait = heartbeatInterval * time.Duration(maxMissedPongs+1)
 
 	wsm := &WebSocketManager{
 		connections:       make(map[string]*ConnectionInfo),
@@ -101,19 +167,25 @@ func NewWebSocketManager(logger interface {
 		melody:            m,
 		ctx:               ctx,
 		cancel:            cancel,
-		heartbeatInterval: time.Second * 30, // 30秒心跳间隔
+		heartbeatInterval: heartbeatInterval,
 		heartbeatTimeout:  time.Second * 60, // 60秒心跳超时
-		maxMessageSize:    1024 * 10,
+		maxMissedPongs:    maxMissedPongs,
+		offlinePolicy:     strings.ToLower(cmp.Or(hb.OfflinePolicy, OfflinePolicyReport)),
+		maxMessageSize:    maxMessageSize,
 		logger:            logger,
 		errorHandler:      errorHandler,
 		serverConfigs:     serverConfigs,
 		serverStatus:      serverStatus,
 		configAccess:      configAccess,
+		checkHistory:      NewCheckHistory(checkHistorySize),
+		probes:            NewProbeManager(probeHistorySize),
 	}
+	wsm.probes.SetProbes(configAccess.GetConfig().Probes)
 
 	// 设置melody事件处理器
 	m.HandleConnect(wsm.handleConnect)
 	m.HandleMessage(wsm.handleMessage)
+	m.HandleMessageBinary(wsm.handleMessage) // msgpack、cbor 编码以 Binary 帧发送
 	m.HandleDisconnect(wsm.handleDisconnect)
 	m.HandleError(wsm.handleError)
 	// 添加ping/pong处理
@@ -132,12 +204,24 @@ func (wsm *WebSocketManager) SetupRoutes(r *gin.Engine) {
 		serverID := c.GetHeader(constant.HeaderId)
 
 		if wsm.authenticate(secret, serverID) {
+			// Agent 支持分帧上报时在握手响应中确认
+			if c.GetHeader(model.ProtocolHeader) == model.ProtocolFrames {
+				c.Writer.Header().Set(model.ProtocolHeader, model.ProtocolFrames)
+			}
+			// 确认 Agent 请求的编码；不支持时不回写，Agent 回退到 JSON
+			if codec := negotiateCodec(c.Request.Header); codec.Name() != model.EncodingJSON {
+				c.Writer.Header().Set(model.EncodingHeader, codec.Name())
+			}
 			_ = wsm.melody.HandleRequest(c.Writer, c.Request) // 忽略错误，melody 已经处理了响应
 		} else {
 			wsm.logger.Warnf("未授权连接尝试 - ServerID: %s, IP: %s", serverID, c.ClientIP())
 			c.JSON(401, gin.H{"error": "未授权连接"})
 		}
 	})
+	// 同一路径的 POST 请求用于 HTTP 推送模式（WebSocket 被拦截的网络环境）
+	r.POST(wsm.configAccess.GetConfig().WebSocketPath, wsm.handlePush)
+	// 自定义上报（设备、脚本等无法运行 Agent 的场景）
+	r.POST(IngestPath, wsm.handleIngest)
 }
 
 // handleConnect 处理连接事件
@@ -159,17 +243,17 @@ func (wsm *WebSocketManager) handleConnect(s *melody.Session) {
 
 	ip := wsm.getClientIP(s)
 	now := time.Now()
+	codec := negotiateCodec(s.Request.Header)
 
 	wsm.mu.Lock()
-	defer wsm.mu.Unlock()
 
 	// 如果已存在连接，先关闭旧连接
 	if oldConn, exists := wsm.connections[serverID]; exists {
 		wsm.logger.Infof("服务器 %s 重新连接，关闭旧连接", serverID)
 		if oldConn.Session != nil {
 			_ = oldConn.Session.Close() // 忽略关闭错误，连接即将被替换
+			delete(wsm.sessions, oldConn.Session)
 		}
-		delete(wsm.sessions, oldConn.Session)
 	}
 
 	// 创建新连接信息
@@ -177,19 +261,26 @@ func (wsm *WebSocketManager) handleConnect(s *melody.Session) {
 		ServerID:      serverID,
 		Session:       s,
 		Status:        Connected,
+		Transport:     TransportWebSocket,
 		ConnectedAt:   now,
 		LastHeartbeat: now,
 		LastMessage:   now,
 		IP:            ip,
 		MessageCount:  0,
 		ErrorCount:    0,
+		Encoding:      codec.Name(),
+		codec:         codec,
+		link:          heartbeat.NewTracker(heartbeat.DefaultWindow),
 	}
 
 	wsm.connections[serverID] = connInfo
 	wsm.sessions[s] = serverID
 	wsm.totalConnections++
+	wsm.mu.Unlock()
 
-	wsm.logger.Infof("服务器连接成功 - ServerID: %s, IP: %s", serverID, ip)
+	wsm.logger.Infof("服务器连接成功 - ServerID: %s, IP: %s, 编码: %s", serverID, ip, codec.Name())
+	// 释放锁后再写入：发送缓冲区已满时 melody 会同步调用 handleError，其中需要获取 wsm.mu
+	wsm.sendProbeTasks(s, serverID)
 }
 
 // handleMessage 处理消息事件
@@ -210,11 +301,18 @@ func (wsm *WebSocketManager) handleMessage(s *melody.Session, msg []byte) {
 	connInfo.LastMessage = time.Now()
 	connInfo.MessageCount++
 	wsm.totalMessages++
+	wsm.totalBytes += int64(len(msg))
+	codec := connInfo.codec
+	installPong := !connInfo.pongPayload
+	connInfo.pongPayload = true
 	wsm.mu.Unlock()
 
+	if installPong {
+		wsm.installPongHandler(s)
+	}
+
 	// 解析服务器状态信息
-	var serverStatusInfo model.ServerInfo
-	err := json.Unmarshal(msg, &serverStatusInfo)
+	serverStatusInfo, frame, err := wsm.decodeReport(serverID, msg, codec)
 	if err != nil {
 		// 记录消息格式错误
 		msgErr := NewValidationError("WebSocket消息格式错误", fmt.Sprintf("ServerID: %s, Error: %v", serverID, err))
@@ -225,6 +323,15 @@ func (wsm *WebSocketManager) handleMessage(s *melody.Session, msg []byte) {
 		return
 	}
 
+	if wsm.recordFrame(serverID, frame) {
+		wsm.requestResync(s, serverID)
+	}
+	wsm.applyServerInfo(serverID, serverStatusInfo)
+}
+
+// applyServerInfo 补充服务器配置信息并写入状态映射
+// WebSocket 与 HTTP 推送两种传输方式共用此处理逻辑，保证在线判定和展示一致
+func (wsm *WebSocketManager) applyServerInfo(serverID string, serverStatusInfo *model.ServerInfo) bool {
 	// 获取服务器配置信息
 	server, exists := wsm.serverConfigs.Get(serverID)
 	if !exists {
@@ -234,9 +341,12 @@ func (wsm *WebSocketManager) handleMessage(s *melody.Session, msg []byte) {
 			wsm.errorHandler.RecordError(configErr)
 		}
 		wsm.incrementErrorCount(serverID)
-		return
+		return false
 	}
 
+	// 补全缺失的嵌套对象（如推送 {} 时），展示与广播时直接访问这些对象
+	serverStatusInfo.FillEmpty()
+
 	// 更新服务器状态信息
 	serverStatusInfo.Name = server.Name
 	serverStatusInfo.Group = server.Group
@@ -248,7 +358,78 @@ func (wsm *WebSocketManager) handleMessage(s *melody.Session, msg []byte) {
 	serverStatusInfo.Loc = strings.ToLower(serverStatusInfo.Loc)
 
 	// 存储到全局状态映射
-	wsm.serverStatus.Set(serverID, &serverStatusInfo)
+	wsm.serverStatus.Set(serverID, serverStatusInfo)
+	wsm.checkHistory.Record(serverID, serverStatusInfo.Checks)
+	wsm.probes.Record(serverID, serverStatusInfo.Probes)
+	if serverStatusInfo.Link != nil {
+		wsm.mu.Lock()
+		if connInfo, ok := wsm.connections[serverID]; ok {
+			connInfo.AgentLink = serverStatusInfo.Link
+		}
+		wsm.mu.Unlock()
+	}
+	return true
+}
+
+// GetCheckHistory 获取服务器可用性检查的历史
+func (wsm *WebSocketManager) GetCheckHistory(serverID string) []*model.CheckHistory {
+	return wsm.checkHistory.Get(serverID)
+}
+
+// RemoveCheckHistory 删除服务器可用性检查的历史（服务器从配置中删除时调用）
+func (wsm *WebSocketManager) RemoveCheckHistory(serverID string) {
+	wsm.checkHistory.Remove(serverID)
+}
+
+// SetProbes 更新网络探测任务，并下发给已通过 WebSocket 连接的服务器（配置热加载时调用）
+// HTTP 推送模式的服务器在下一次推送的响应中收到新任务
+func (wsm *WebSocketManager) SetProbes(probes []*config.ProbeConfig) {
+	wsm.probes.SetProbes(probes)
+
+	// 在锁内复制会话列表，释放锁后再写入，原因同 handleConnect
+	wsm.mu.RLock()
+	sessions := make(map[string]*melody.Session, len(wsm.connections))
+	for serverID, conn := range wsm.connections {
+		if conn.Session != nil {
+			sessions[serverID] = conn.Session
+		}
+	}
+	wsm.mu.RUnlock()
+
+	for serverID, s := range sessions {
+		wsm.sendProbeTasks(s, serverID)
+	}
+}
+
+// sendProbeTasks 向 WebSocket 连接的服务器下发探测任务；调用方不能持有 wsm.mu
+func (wsm *WebSocketManager) sendProbeTasks(s *melody.Session, serverID string) {
+	msg, err := json.Marshal(wsm.probes.Command(serverID))
+	if err != nil {
+		return
+	}
+	if err := s.Write(msg); err != nil {
+		wsm.logger.Warnf("下发网络探测任务失败 - ServerID: %s, Error: %v", serverID, err)
+	}
+}
+
+// GetProbeMatrix 获取各服务器到各探测目标最近一轮的结果
+func (wsm *WebSocketManager) GetProbeMatrix() *model.ProbeMatrix {
+	return wsm.probes.Matrix(func(serverID string) string {
+		if server, ok := wsm.serverConfigs.Get(serverID); ok {
+			return server.Name
+		}
+		return serverID
+	})
+}
+
+// GetProbeHistory 获取服务器到一个探测目标的历史
+func (wsm *WebSocketManager) GetProbeHistory(serverID, name string) []*model.ProbePoint {
+	return wsm.probes.History(serverID, name)
+}
+
+// RemoveProbeHistory 删除服务器的探测历史（服务器从配置中删除时调用）
+func (wsm *WebSocketManager) RemoveProbeHistory(serverID string) {
+	wsm.probes.Remove(serverID)
 }
 
 // handleDisconnect 处理断开连接事件
@@ -305,23 +486,56 @@ func (wsm *WebSocketManager) handleError(s *melody.Session, err error) {
 	wsm.mu.Unlock()
 }
 
-// handlePong 处理pong消息
+// handlePong 处理 melody 转发的 pong 消息，只在接管 pong 处理之前（收到第一条上报之前）调用
+// melody 不传递 pong 的载荷；此时最多发送过一个 ping，按未响应的 ping 统计
 func (wsm *WebSocketManager) handlePong(s *melody.Session) {
-	wsm.mu.RLock()
-	serverID, exists := wsm.sessions[s]
-	wsm.mu.RUnlock()
+	wsm.recordPong(s, "")
 
-	if !exists {
-		return
+	wsm.mu.Lock()
+	connInfo, exists := wsm.connections[wsm.sessions[s]]
+	install := exists && connInfo.Session == s && !connInfo.pongPayload
+	if install {
+		connInfo.pongPayload = true
 	}
+	wsm.mu.Unlock()
+
+	if install {
+		wsm.installPongHandler(s)
+	}
+}
+
+// installPongHandler 接管 pong 处理以获取 pong 的载荷，必须在读取协程中调用
+// melody 在 connectHandler 之后才设置自己的 pong 处理函数，因此在收到第一条消息或 pong 时替换；
+// 与 melody 相同，收到 pong 时延长读超时
+func (wsm *WebSocketManager) installPongHandler(s *melody.Session) {
+	conn := s.WebsocketConnection()
+	pongWait := wsm.melody.Config.PongWait
+	conn.SetPongHandler(func(payload string) error {
+		_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+		wsm.recordPong(s, payload)
+		return nil
+	})
+}
 
+// recordPong 记录收到的 pong；载荷与最近一次 ping 不对应（发送新的 ping 之后才到达）的 pong 不计入往返时间
+func (wsm *WebSocketManager) recordPong(s *melody.Session, payload string) {
+	now := time.Now()
 	wsm.mu.Lock()
-	if connInfo, exists := wsm.connections[serverID]; exists {
-		connInfo.LastHeartbeat = time.Now()
+	serverID, exists := wsm.sessions[s]
+	connInfo := wsm.connections[serverID]
+	if !exists || connInfo == nil || connInfo.Session != s {
+		wsm.mu.Unlock()
+		return
 	}
+	connInfo.LastHeartbeat = now
+	matched := connInfo.link.Pong(payload, now)
 	wsm.mu.Unlock()
 
-	wsm.logger.Debugf("收到心跳响应 - ServerID: %s", serverID)
+	if matched {
+		wsm.logger.Debugf("收到心跳响应 - ServerID: %s", serverID)
+	} else {
+		wsm.logger.Debugf("丢弃过期的心跳响应 - ServerID: %s, 载荷: %s", serverID, payload)
+	}
 }
 
 // heartbeatLoop 心跳检测循环
@@ -339,27 +553,85 @@ func (wsm *WebSocketManager) heartbeatLoop() {
 	}
 }
 
-// checkHeartbeats 检查心跳超时
+// checkHeartbeats 向 WebSocket 连接发送 ping 并检查心跳超时
+// WebSocket 连接连续 maxMissedPongs 次未收到 pong 时断开；无会话的上报方式（HTTP 推送、拉取等）以最近一次上报作为心跳
 func (wsm *WebSocketManager) checkHeartbeats() {
-	wsm.mu.Lock()
-	defer wsm.mu.Unlock()
-
 	now := time.Now()
+	var pings []*ConnectionInfo
 	var timeoutSessions []*melody.Session
 
+	wsm.mu.Lock()
 	for serverID, connInfo := range wsm.connections {
-		if now.Sub(connInfo.LastMessage) > wsm.heartbeatTimeout {
-			wsm.logger.Warnf("服务器心跳超时 - ServerID: %s, 最后消息时间: %v", serverID, connInfo.LastMessage)
+		if connInfo.Session == nil {
+			if now.Sub(connInfo.LastHeartbeat) > wsm.heartbeatTimeout {
+				wsm.logger.Warnf("服务器上报超时 - ServerID: %s, 最后上报时间: %v", serverID, connInfo.LastHeartbeat)
+				delete(wsm.connections, serverID)
+				wsm.totalDisconnections++
+			}
+			continue
+		}
+		if missed := connInfo.link.Missed(); missed >= wsm.maxMissedPongs {
+			wsm.logger.Warnf("服务器心跳超时 - ServerID: %s, 连续 %d 次未收到心跳响应, 最后心跳时间: %v", serverID, missed, connInfo.LastHeartbeat)
 			timeoutSessions = append(timeoutSessions, connInfo.Session)
+			continue
 		}
+		pings = append(pings, connInfo)
 	}
+	wsm.mu.Unlock()
 
-	// 关闭超时的连接
+	// 在锁外发送 ping 和关闭连接，避免网络阻塞影响上报处理
+	for _, connInfo := range pings {
+		payload := connInfo.link.Ping(now)
+		// WriteControl 可与 melody 发送循环中的写操作并发调用
+		if err := connInfo.Session.WebsocketConnection().WriteControl(websocket.PingMessage, []byte(payload), now.Add(heartbeatWriteWait)); err != nil {
+			wsm.logger.Debugf("发送心跳失败 - ServerID: %s, Error: %v", connInfo.ServerID, err)
+		}
+	}
 	for _, session := range timeoutSessions {
 		_ = session.Close() // 忽略关闭错误，会话即将被清理
 	}
 }
 
+// IsOnline 按离线判定策略判断服务器是否在线
+func (wsm *WebSocketManager) IsOnline(info *model.ServerInfo) bool {
+	if time.Now().Unix()-info.LastReportTime > int64(wsm.configAccess.GetConfig().ReportTimeIntervalMax) {
+		return false
+	}
+	if wsm.offlinePolicy != OfflinePolicyHeartbeat {
+		return true
+	}
+
+	wsm.mu.RLock()
+	defer wsm.mu.RUnlock()
+	connInfo, ok := wsm.connections[info.Id]
+	return ok && (connInfo.link == nil || connInfo.link.Missed() == 0)
+}
+
+// GetConnectionLinks 获取各服务器的链路测量结果
+func (wsm *WebSocketManager) GetConnectionLinks() map[string]*model.ConnectionLink {
+	wsm.mu.RLock()
+	defer wsm.mu.RUnlock()
+
+	result := make(map[string]*model.ConnectionLink, len(wsm.connections))
+	for serverID, connInfo := range wsm.connections {
+		result[serverID] = &model.ConnectionLink{
+			Transport:     connInfo.Transport,
+			LastHeartbeat: connInfo.LastHeartbeat.Unix(),
+			Dashboard:     connInfo.linkQuality(),
+			Agent:         connInfo.AgentLink,
+		}
+	}
+	return result
+}
+
+// linkQuality 返回面板测得的链路质量；无会话的上报方式返回 nil
+func (c *ConnectionInfo) linkQuality() *model.LinkQuality {
+	if c.link == nil {
+		return nil
+	}
+	return c.link.Quality()
+}
+
 // authenticate 认证
 func (wsm *WebSocketManager) authenticate(secret, serverID string) bool {
 	if secret == "" || serverID == "" {
@@ -423,6 +695,8 @@ func (wsm *WebSocketManager) GetConnectionInfo(serverID string) (*ConnectionInfo
 	// 返回副本以避免并发问题
 	copy := *connInfo
 	copy.Session = nil // 不暴露session
+	copy.Link = connInfo.linkQuality()
+	copy.OtherTransports = maps.Clone(connInfo.OtherTransports)
 	return &copy, true
 }
 
@@ -435,6 +709,8 @@ func (wsm *WebSocketManager) GetAllConnections() map[string]*ConnectionInfo {
 	for serverID, connInfo := range wsm.connections {
 		copy := *connInfo
 		copy.Session = nil // 不暴露session
+		copy.Link = connInfo.linkQuality()
+		copy.OtherTransports = maps.Clone(connInfo.OtherTransports)
 		result[serverID] = &copy
 	}
 
@@ -452,6 +728,10 @@ func (wsm *WebSocketManager) GetStats() map[string]interface{} {
 		"total_disconnections": wsm.totalDisconnections,
 		"total_messages":       wsm.totalMessages,
 		"total_errors":         wsm.totalErrors,
+		"total_bytes":          wsm.totalBytes,
+		"inventory_frames":     wsm.inventoryFrames,
+		"metric_frames":        wsm.metricFrames,
+		"legacy_frames":        wsm.legacyFrames,
 	}
 }
 
@@ -532,7 +812,9 @@ func (wsm *WebSocketManager) DelByServerId(serverID string) {
 			_ = connInfo.Session.Close() // 忽略关闭错误，连接即将被清理
 		}
 		delete(wsm.connections, serverID)
-		delete(wsm.sessions, connInfo.Session)
+		if connInfo.Session != nil {
+			delete(wsm.sessions, connInfo.Session)
+		}
 	}
 }
 
@@ -0,0 +1,118 @@
+package heartbeat
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ruanun/simple-server-status/pkg/model"
+)
+
+// DefaultWindow 计算平均往返时间与抖动使用的最近往返时间个数
+const DefaultWindow = 10
+
+// Tracker 根据 WebSocket ping 与对应 pong 的时间统计链路往返时间
+// 同一时间只跟踪一个未响应的 ping：发送下一个 ping 时上一个仍未收到 pong，则计为丢失
+type Tracker struct {
+	mu        sync.Mutex
+	window    int
+	seq       uint64
+	pending   string    // 未响应的 ping 的载荷；为空表示没有未响应的 ping
+	pendingAt time.Time // 未响应的 ping 的发送时间
+	rtts      []time.Duration
+	quality   model.LinkQuality
+}
+
+// NewTracker 创建往返时间统计，平均值与抖动按最近 window 个往返时间计算
+func NewTracker(window int) *Tracker {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &Tracker{window: window}
+}
+
+// Ping 记录一次 ping 的发送，返回需要携带的载荷（对端在 pong 中原样返回）
+// 上一个 ping 仍未收到 pong 时计为丢失
+func (t *Tracker) Ping(now time.Time) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.pending != "" {
+		t.quality.MissedPongs++
+		t.quality.ConsecutiveMissed++
+	}
+	t.seq++
+	t.pending = strconv.FormatUint(t.seq, 10)
+	t.pendingAt = now
+	t.quality.PingsSent++
+	return t.pending
+}
+
+// Pong 记录收到的 pong，返回是否与未响应的 ping 对应
+// payload 为 pong 携带的载荷；无法获取载荷时传空字符串，按未响应的 ping 处理
+func (t *Tracker) Pong(payload string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.pending == "" || (payload != "" && payload != t.pending) {
+		return false
+	}
+	rtt := max(now.Sub(t.pendingAt), 0)
+	t.pending = ""
+	t.rtts = append(t.rtts, rtt)
+	if len(t.rtts) > t.window {
+		t.rtts = t.rtts[len(t.rtts)-t.window:]
+	}
+
+	var sum, jitter time.Duration
+	for i, d := range t.rtts {
+		sum += d
+		if i > 0 {
+			diff := d - t.rtts[i-1]
+			jitter += max(diff, -diff)
+		}
+	}
+	q := &t.quality
+	q.LastRTTMs = durationMs(rtt)
+	q.AvgRTTMs = durationMs(sum / time.Duration(len(t.rtts)))
+	q.JitterMs = 0
+	if len(t.rtts) > 1 {
+		q.JitterMs = durationMs(jitter / time.Duration(len(t.rtts)-1))
+	}
+	q.Samples = len(t.rtts)
+	q.PongsReceived++
+	q.ConsecutiveMissed = 0
+	q.LastPongAt = now.Unix()
+	return true
+}
+
+// Missed 返回连续未收到的 pong 个数
+func (t *Tracker) Missed() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.quality.ConsecutiveMissed
+}
+
+// Quality 返回当前的链路质量
+func (t *Tracker) Quality() *model.LinkQuality {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	q := t.quality
+	return &q
+}
+
+// Reset 建立新连接时清空未响应的 ping 与往返时间，累计次数保留
+func (t *Tracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pending = ""
+	t.rtts = nil
+	t.quality.LastRTTMs, t.quality.AvgRTTMs, t.quality.JitterMs, t.quality.Samples = 0, 0, 0, 0
+	t.quality.ConsecutiveMissed = 0
+}
+
+// durationMs 转换为毫秒，保留两位小数
+func durationMs(d time.Duration) float64 {
+	return math.Round(float64(d.Microseconds())/10) / 100
+}
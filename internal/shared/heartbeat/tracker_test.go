@@ -0,0 +1,58 @@
+package heartbeat
+
+import (
+	"testing"
+	"time"
+)
+
+// TestTracker 测试往返时间、抖动与丢失 pong 的统计
+func TestTracker(t *testing.T) {
+	tr := NewTracker(3)
+	now := time.Unix(1700000000, 0)
+	round := func(rtt time.Duration) {
+		t.Helper()
+		payload := tr.Ping(now)
+		if !tr.Pong(payload, now.Add(rtt)) {
+			t.Fatalf("Pong(%q) = false", payload)
+		}
+		now = now.Add(15 * time.Second)
+	}
+
+	round(10 * time.Millisecond)
+	round(20 * time.Millisecond)
+	round(15 * time.Millisecond)
+	q := tr.Quality()
+	if q.LastRTTMs != 15 || q.AvgRTTMs != 15 || q.JitterMs != 7.5 || q.Samples != 3 || q.PingsSent != 3 || q.PongsReceived != 3 {
+		t.Errorf("Quality() = %+v", *q)
+	}
+
+	// 超出窗口时只统计最近的往返时间
+	round(15 * time.Millisecond)
+	if q = tr.Quality(); q.AvgRTTMs != 16.67 || q.JitterMs != 2.5 || q.Samples != 3 {
+		t.Errorf("超出窗口后 = %+v", *q)
+	}
+
+	// 未响应的 ping 在下一次 ping 时计为丢失，载荷不对应的 pong 忽略
+	stale := tr.Ping(now)
+	tr.Ping(now.Add(15 * time.Second))
+	tr.Ping(now.Add(30 * time.Second))
+	if tr.Pong(stale, now.Add(31*time.Second)) {
+		t.Error("过期的 pong 不应被统计")
+	}
+	if q = tr.Quality(); tr.Missed() != 2 || q.MissedPongs != 2 || q.LastPongAt != time.Unix(1700000045, 0).Unix() {
+		t.Errorf("丢失后 Missed() = %d, Quality() = %+v", tr.Missed(), *q)
+	}
+
+	// 无法获取载荷时按未响应的 ping 处理，收到后连续丢失清零
+	if !tr.Pong("", now.Add(30*time.Second+5*time.Millisecond)) || tr.Missed() != 0 {
+		t.Errorf("Pong(\"\") 后 Missed() = %d", tr.Missed())
+	}
+	if tr.Pong("", now.Add(31*time.Second)) {
+		t.Error("没有未响应的 ping 时不应统计")
+	}
+
+	tr.Reset()
+	if q = tr.Quality(); q.Samples != 0 || q.AvgRTTMs != 0 || q.PingsSent != 7 || q.MissedPongs != 2 {
+		t.Errorf("Reset() 后 = %+v", *q)
+	}
+}
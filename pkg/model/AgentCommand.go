@@ -0,0 +1,31 @@
+package model
+
+// AgentCommand 面板下发给 Agent 的指令
+// WebSocket 连接以 JSON 文本帧发送；HTTP 推送模式放在推送响应的 data.command 中
+type AgentCommand struct {
+	Type   string       `json:"type"`
+	Probes []*ProbeTask `json:"probes,omitempty"`
+}
+
+// 指令类型
+const (
+	CommandProbes = "probes" //下发网络探测任务，替换 Agent 当前的全部任务
+	CommandResync = "resync" //指标帧与面板已接收的完整帧不对应，要求 Agent 下一帧发送完整帧
+)
+
+// 网络探测类型
+const (
+	ProbeTypeTCP  = "tcp"  //建立 TCP 连接
+	ProbeTypeICMP = "icmp" //ICMP echo；没有权限时回退为 TCP 连接（需配置端口）
+)
+
+// ProbeTask 网络探测任务，由面板配置并下发给选定的 Agent
+type ProbeTask struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`           //tcp 或 icmp
+	Host      string `json:"host"`           //目标主机名或 IP
+	Port      int    `json:"port,omitempty"` //tcp 探测的端口；icmp 探测没有权限时回退为 TCP 连接的端口
+	Interval  int    `json:"interval"`       //探测周期，单位秒
+	Count     int    `json:"count"`          //每轮探测次数
+	TimeoutMs int    `json:"timeoutMs"`      //单次探测超时，单位毫秒
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ugorji/go/codec"
+)
+
+// 上报编码协商
+// Agent 在 WebSocket 握手请求中携带 EncodingHeader，面板支持时在握手响应中回写相同的值；
+// 未确认时使用 JSON。二进制编码的消息以 Binary 帧发送
+const (
+	EncodingHeader = "X-REPORT-ENCODING"
+
+	EncodingJSON    = "json"
+	EncodingMsgpack = "msgpack"
+	EncodingCBOR    = "cbor"
+)
+
+// Codec 上报消息编解码器
+type Codec interface {
+	Name() string
+	// Binary 是否为二进制编码（决定 WebSocket 帧类型）
+	Binary() bool
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var (
+	msgpackHandle = &codec.MsgpackHandle{WriteExt: true}
+	cborHandle    = &codec.CborHandle{}
+)
+
+// 字段名取自 json tag（codec 默认读取 codec、json 两种 tag），与 JSON 格式一致
+func init() {
+	msgpackHandle.RawToString = true
+}
+
+// NewCodec 根据名称创建编解码器，名称为空时使用 JSON
+func NewCodec(name string) (Codec, error) {
+	switch strings.ToLower(name) {
+	case "", EncodingJSON:
+		return jsonCodec{}, nil
+	case EncodingMsgpack:
+		return handleCodec{name: EncodingMsgpack, handle: msgpackHandle}, nil
+	case EncodingCBOR:
+		return handleCodec{name: EncodingCBOR, handle: cborHandle}, nil
+	default:
+		return nil, fmt.Errorf("不支持的编码: %s", name)
+	}
+}
+
+// jsonCodec JSON 编解码
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string                               { return EncodingJSON }
+func (jsonCodec) Binary() bool                               { return false }
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+// handleCodec 基于 ugorji/go/codec 的二进制编解码
+type handleCodec struct {
+	name   string
+	handle codec.Handle
+}
+
+func (c handleCodec) Name() string { return c.name }
+func (c handleCodec) Binary() bool { return true }
+
+func (c handleCodec) Marshal(v interface{}) ([]byte, error) {
+	var out []byte
+	err := codec.NewEncoderBytes(&out, c.handle).Encode(v)
+	return out, err
+}
+
+func (c handleCodec) Unmarshal(data []byte, v interface{}) error {
+	return codec.NewDecoderBytes(data, c.handle).Decode(v)
+}
@@ -0,0 +1,147 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// 分帧上报协议协商
+// Agent 在 WebSocket 握手请求中携带 ProtocolHeader，面板支持时在握手响应中回写相同的值，
+// 之后 Agent 发送 ReportFrame；未协商时仍发送完整的 ServerInfo，兼容旧版本面板
+const (
+	ProtocolHeader = "X-REPORT-PROTOCOL"
+	ProtocolFrames = "frames"
+)
+
+// 上报帧类型
+const (
+	FrameTypeInventory = "inventory" // 完整数据：连接建立时或硬件与系统信息变化时发送
+	FrameTypeMetrics   = "metrics"   // 仅指标：不含硬件与系统信息
+)
+
+// ReportFrame 分帧上报消息
+type ReportFrame struct {
+	Type string      `json:"type"`
+	Hash string      `json:"hash"` //硬件与系统信息的哈希，指标帧用于关联对应的完整数据
+	Data *ServerInfo `json:"data"`
+}
+
+// inventory 几乎不变的硬件与系统信息
+type inventory struct {
+	KernelArch           string                `json:"kernelArch"`
+	KernelVersion        string                `json:"kernelVersion"`
+	VirtualizationSystem string                `json:"virtualizationSystem"`
+	BootTime             uint64                `json:"bootTime"`
+	OS                   string                `json:"os"`
+	Platform             string                `json:"platform"`
+	PlatformFamily       string                `json:"platformFamily"`
+	PlatformVersion      string                `json:"platformVersion"`
+	CpuInfo              []string              `json:"cpuInfo"`
+	LogicalCores         int                   `json:"logicalCores"`
+	PhysicalCores        int                   `json:"physicalCores"`
+	Partitions           []*partitionInventory `json:"partitions"`
+}
+
+// partitionInventory 分区中不随使用量变化的信息；用量、inode 与健康状态随指标帧上报
+type partitionInventory struct {
+	MountPoint string `json:"mountPoint"`
+	Fstype     string `json:"fstype"`
+	Total      uint64 `json:"total"`
+}
+
+func (s *ServerInfo) inventory() *inventory {
+	inv := &inventory{}
+	if h := s.HostInfo; h != nil {
+		inv.KernelArch = h.KernelArch
+		inv.KernelVersion = h.KernelVersion
+		inv.VirtualizationSystem = h.VirtualizationSystem
+		inv.BootTime = h.BootTime
+		inv.OS = h.OS
+		inv.Platform = h.Platform
+		inv.PlatformFamily = h.PlatformFamily
+		inv.PlatformVersion = h.PlatformVersion
+	}
+	if s.CpuInfo != nil {
+		inv.CpuInfo = s.CpuInfo.Info
+		inv.LogicalCores = s.CpuInfo.LogicalCores
+		inv.PhysicalCores = s.CpuInfo.PhysicalCores
+	}
+	if s.DiskInfo != nil {
+		for _, p := range s.DiskInfo.Partitions {
+			inv.Partitions = append(inv.Partitions, &partitionInventory{MountPoint: p.MountPoint, Fstype: p.Fstype, Total: p.Total})
+		}
+	}
+	return inv
+}
+
+// InventoryHash 计算硬件与系统信息的哈希
+func (s *ServerInfo) InventoryHash() string {
+	data, _ := json.Marshal(s.inventory()) // 只包含基本类型，不会失败
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// MetricsOnly 返回去掉硬件与系统信息的浅拷贝，用于指标帧
+func (s *ServerInfo) MetricsOnly() *ServerInfo {
+	m := *s
+	if s.HostInfo != nil {
+		m.HostInfo = &HostInfo{Uptime: s.HostInfo.Uptime, AvgStat: s.HostInfo.AvgStat}
+	}
+	if s.CpuInfo != nil {
+		m.CpuInfo = &CpuInfo{Percent: s.CpuInfo.Percent, PerCore: s.CpuInfo.PerCore, Times: s.CpuInfo.Times, Mhz: s.CpuInfo.Mhz}
+	}
+	if s.DiskInfo != nil {
+		m.DiskInfo = &DiskInfo{Total: s.DiskInfo.Total, Used: s.DiskInfo.Used, UsedPercent: s.DiskInfo.UsedPercent}
+		for _, p := range s.DiskInfo.Partitions {
+			metrics := *p
+			metrics.MountPoint, metrics.Fstype = "", ""
+			m.DiskInfo.Partitions = append(m.DiskInfo.Partitions, &metrics)
+		}
+	}
+	return &m
+}
+
+// MergeInventory 使用 from 中的硬件与系统信息补全指标帧
+// 只替换嵌套对象，不修改 from；分区的挂载点与文件系统类型按顺序从 from 补全，
+// 指标帧不含分区（旧版本 Agent）或分区数量与 from 不一致时沿用 from 中的分区
+func (s *ServerInfo) MergeInventory(from *ServerInfo) {
+	if from == nil {
+		return
+	}
+	inv := from.inventory()
+	s.FillEmpty()
+
+	host := *s.HostInfo
+	host.KernelArch = inv.KernelArch
+	host.KernelVersion = inv.KernelVersion
+	host.VirtualizationSystem = inv.VirtualizationSystem
+	host.BootTime = inv.BootTime
+	host.OS = inv.OS
+	host.Platform = inv.Platform
+	host.PlatformFamily = inv.PlatformFamily
+	host.PlatformVersion = inv.PlatformVersion
+	s.HostInfo = &host
+
+	cpu := *s.CpuInfo
+	cpu.Info = inv.CpuInfo
+	cpu.LogicalCores = inv.LogicalCores
+	cpu.PhysicalCores = inv.PhysicalCores
+	s.CpuInfo = &cpu
+
+	if from.DiskInfo == nil {
+		return
+	}
+	disk := *s.DiskInfo
+	if len(disk.Partitions) != len(from.DiskInfo.Partitions) {
+		disk.Partitions = from.DiskInfo.Partitions
+	} else {
+		disk.Partitions = make([]*Partition, len(s.DiskInfo.Partitions))
+		for i, p := range s.DiskInfo.Partitions {
+			merged := *p
+			merged.MountPoint, merged.Fstype = from.DiskInfo.Partitions[i].MountPoint, from.DiskInfo.Partitions[i].Fstype
+			disk.Partitions[i] = &merged
+		}
+	}
+	s.DiskInfo = &disk
+}
@@ -16,14 +16,25 @@ type RespServerInfo struct {
 	Platform string `json:"platform"` //系统版型信息 ex: Windows 11 x64 ;platform+platformVersion
 
 	CpuPercent  float64 `json:"cpuPercent"`  //cpu占用
-	RAMPercent  float64 `json:"RAMPercent"`  //内存占用
+	RAMPercent  float64 `json:"RAMPercent"`  //内存占用；计算方式由面板配置 ramPercentMode 决定
 	SWAPPercent float64 `json:"SWAPPercent"` //swap占用
 	DiskPercent float64 `json:"diskPercent"` //硬盘占用
 
+	CpuPerCore []float64 `json:"cpuPerCore,omitempty"` //各逻辑核心占用
+	CpuTimes   *CpuTimes `json:"cpuTimes,omitempty"`   //cpu各状态时间占比（iowait、steal 等）
+	DiskIOBusy float64   `json:"diskIOBusy,omitempty"` //最繁忙磁盘的忙碌占比
+
 	NetInSpeed  uint64 `json:"netInSpeed"`  //下载速度
 	NetOutSpeed uint64 `json:"netOutSpeed"` //上传速度
 
-	Loc string `json:"loc"`
+	Loc   string   `json:"loc"`
+	Stale []string `json:"stale,omitempty"` //数据过期的采集器
+
+	ProcessesDown []string `json:"processesDown,omitempty"` //未运行（实例数不足）的受监控进程，可用于展示和告警
+	ChecksFailed  []string `json:"checksFailed,omitempty"`  //最近一次检查失败的可用性检查
+	Overheated    []string `json:"overheated,omitempty"`    //达到临界温度的温度传感器
+
+	Aggregates *SampleAggregates `json:"aggregates,omitempty"` //上报周期内的采样统计（min/avg/max/p95）
 
 	HostInfo *RespHostData `json:"hostInfo"`
 
@@ -34,18 +45,50 @@ type RespHostData struct {
 	CpuInfo []string      `json:"cpuInfo"` //cpu信息字符串描述
 	AvgStat *load.AvgStat `json:"avgStat"` //load
 
+	CpuMhz        float64 `json:"cpuMhz,omitempty"`        //cpu当前频率
+	LogicalCores  int     `json:"logicalCores,omitempty"`  //逻辑核心数
+	PhysicalCores int     `json:"physicalCores,omitempty"` //物理核心数
+
 	RAMTotal  uint64 `json:"RAMTotal"`
 	RAMUsed   uint64 `json:"RAMUsed"`
 	SwapTotal uint64 `json:"swapTotal"`
 	SwapUsed  uint64 `json:"swapUsed"`
 
-	DiskTotal      uint64       `json:"diskTotal"`      //总硬盘
-	DiskUsed       uint64       `json:"diskUsed"`       //已使用
-	DiskPartitions []*Partition `json:"diskPartitions"` //各个分区
+	//内存明细，旧版本 Agent 不上报
+	RAMAvailable   uint64 `json:"RAMAvailable,omitempty"`
+	RAMFree        uint64 `json:"RAMFree,omitempty"`
+	RAMBuffers     uint64 `json:"RAMBuffers,omitempty"`
+	RAMCached      uint64 `json:"RAMCached,omitempty"`
+	RAMShared      uint64 `json:"RAMShared,omitempty"`
+	RAMSlab        uint64 `json:"RAMSlab,omitempty"`
+	RAMDirty       uint64 `json:"RAMDirty,omitempty"`
+	RAMCommitted   uint64 `json:"RAMCommitted,omitempty"`
+	HugePagesTotal uint64 `json:"hugePagesTotal,omitempty"`
+	HugePagesFree  uint64 `json:"hugePagesFree,omitempty"`
+	HugePageSize   uint64 `json:"hugePageSize,omitempty"`
+
+	DiskTotal      uint64           `json:"diskTotal"`      //总硬盘
+	DiskUsed       uint64           `json:"diskUsed"`       //已使用
+	DiskPartitions []*RespPartition `json:"diskPartitions"` //各个分区
+
+	DiskIO []*DiskIO `json:"diskIO,omitempty"` //各物理磁盘的 I/O 负载
 
 	NetInTransfer  uint64 `json:"netInTransfer"`  //下载的流量
 	NetOutTransfer uint64 `json:"netOutTransfer"` //上传的流量
 
+	NetInterfaces []*NetInterface `json:"netInterfaces,omitempty"` //各网卡的统计
+
+	Sockets *SocketInfo `json:"sockets,omitempty"` //TCP/UDP 连接与套接字统计
+
+	Processes *ProcessInfo `json:"processes,omitempty"` //进程数量统计与资源占用排行
+
+	Sensors *SensorInfo `json:"sensors,omitempty"` //硬件传感器读数
+
+	WatchedProcesses []*WatchedProcess `json:"watchedProcesses,omitempty"` //受监控进程的状态
+
+	Checks []*CheckResult `json:"checks,omitempty"` //可用性检查的最近一次结果
+	Probes []*ProbeResult `json:"probes,omitempty"` //网络探测任务最近一轮的结果
+
 	OS                   string `json:"os"`
 	Platform             string `json:"platform"`
 	PlatformVersion      string `json:"platformVersion"`
@@ -54,6 +97,21 @@ type RespHostData struct {
 	KernelArch           string `json:"kernelArch"`
 }
 
+// RespPartition 分区信息及其健康状态
+type RespPartition struct {
+	*Partition
+	Health string `json:"health"` //分区健康状态，见 PartitionHealthOK 等
+}
+
+// NewRespPartitions 为各分区计算健康状态
+func NewRespPartitions(partitions []*Partition) []*RespPartition {
+	result := make([]*RespPartition, 0, len(partitions))
+	for _, p := range partitions {
+		result = append(result, &RespPartition{Partition: p, Health: p.Health()})
+	}
+	return result
+}
+
 func isWin(serverInfo *ServerInfo) bool {
 	return strings.Contains(serverInfo.HostInfo.Platform, "Windows")
 }
@@ -63,18 +121,49 @@ func NewRespHostData(serverInfo *ServerInfo) *RespHostData {
 		CpuInfo: serverInfo.CpuInfo.Info,
 		AvgStat: serverInfo.HostInfo.AvgStat,
 
+		CpuMhz:        serverInfo.CpuInfo.Mhz,
+		LogicalCores:  serverInfo.CpuInfo.LogicalCores,
+		PhysicalCores: serverInfo.CpuInfo.PhysicalCores,
+
 		RAMTotal:  serverInfo.VirtualMemoryInfo.Total,
 		RAMUsed:   serverInfo.VirtualMemoryInfo.Used,
 		SwapTotal: serverInfo.SwapMemoryInfo.Total,
 		SwapUsed:  serverInfo.SwapMemoryInfo.Used,
 
+		RAMAvailable:   serverInfo.VirtualMemoryInfo.Available,
+		RAMFree:        serverInfo.VirtualMemoryInfo.Free,
+		RAMBuffers:     serverInfo.VirtualMemoryInfo.Buffers,
+		RAMCached:      serverInfo.VirtualMemoryInfo.Cached,
+		RAMShared:      serverInfo.VirtualMemoryInfo.Shared,
+		RAMSlab:        serverInfo.VirtualMemoryInfo.Slab,
+		RAMDirty:       serverInfo.VirtualMemoryInfo.Dirty,
+		RAMCommitted:   serverInfo.VirtualMemoryInfo.CommittedAS,
+		HugePagesTotal: serverInfo.VirtualMemoryInfo.HugePagesTotal,
+		HugePagesFree:  serverInfo.VirtualMemoryInfo.HugePagesFree,
+		HugePageSize:   serverInfo.VirtualMemoryInfo.HugePageSize,
+
 		DiskTotal:      serverInfo.DiskInfo.Total,
 		DiskUsed:       serverInfo.DiskInfo.Used,
-		DiskPartitions: serverInfo.DiskInfo.Partitions,
+		DiskPartitions: NewRespPartitions(serverInfo.DiskInfo.Partitions),
+
+		DiskIO: serverInfo.DiskIO,
 
 		NetInTransfer:  serverInfo.NetworkInfo.NetInTransfer,
 		NetOutTransfer: serverInfo.NetworkInfo.NetOutTransfer,
 
+		NetInterfaces: serverInfo.NetworkInfo.Interfaces,
+
+		Sockets: serverInfo.Sockets,
+
+		Processes: serverInfo.Processes,
+
+		Sensors: serverInfo.Sensors,
+
+		WatchedProcesses: serverInfo.WatchedProcesses,
+
+		Checks: serverInfo.Checks,
+		Probes: serverInfo.Probes,
+
 		OS:                   serverInfo.HostInfo.OS,
 		Platform:             serverInfo.HostInfo.Platform,
 		PlatformVersion:      serverInfo.HostInfo.PlatformVersion,
@@ -104,12 +193,116 @@ func NewRespServerInfo(serverInfo *ServerInfo) *RespServerInfo {
 		RAMPercent:  serverInfo.VirtualMemoryInfo.UsedPercent,
 		SWAPPercent: serverInfo.SwapMemoryInfo.UsedPercent,
 		DiskPercent: serverInfo.DiskInfo.UsedPercent,
+		CpuPerCore:  serverInfo.CpuInfo.PerCore,
+		CpuTimes:    serverInfo.CpuInfo.Times,
+		DiskIOBusy:  maxDiskBusy(serverInfo.DiskIO),
 		NetInSpeed:  serverInfo.NetworkInfo.NetInSpeed,
 		NetOutSpeed: serverInfo.NetworkInfo.NetOutSpeed,
 
-		Loc: serverInfo.Loc,
+		Loc:   serverInfo.Loc,
+		Stale: serverInfo.Stale,
+
+		ProcessesDown: downProcesses(serverInfo.WatchedProcesses),
+		ChecksFailed:  failedChecks(serverInfo.Checks),
+		Overheated:    overheatedSensors(serverInfo.Sensors),
+
+		Aggregates: serverInfo.Aggregates,
 
 		//其他信息
 		HostInfo: NewRespHostData(serverInfo),
 	}
 }
+
+// downProcesses 返回未运行的受监控进程名称
+func downProcesses(watched []*WatchedProcess) []string {
+	var down []string
+	for _, p := range watched {
+		if !p.Up {
+			down = append(down, p.Name)
+		}
+	}
+	return down
+}
+
+// failedChecks 返回最近一次检查失败的可用性检查名称
+func failedChecks(checks []*CheckResult) []string {
+	var failed []string
+	for _, c := range checks {
+		if c.Status == CheckStatusFail {
+			failed = append(failed, c.Name)
+		}
+	}
+	return failed
+}
+
+// overheatedSensors 返回达到临界温度的温度传感器名称
+func overheatedSensors(sensors *SensorInfo) []string {
+	if sensors == nil {
+		return nil
+	}
+	var overheated []string
+	for _, t := range sensors.Temperatures {
+		if t.Overheated() {
+			overheated = append(overheated, t.Name)
+		}
+	}
+	return overheated
+}
+
+// maxDiskBusy 返回各磁盘忙碌占比的最大值
+func maxDiskBusy(diskIO []*DiskIO) float64 {
+	var busy float64
+	for _, io := range diskIO {
+		if io.BusyPercent > busy {
+			busy = io.BusyPercent
+		}
+	}
+	return busy
+}
+
+// CheckHistory 一项可用性检查的历史记录
+type CheckHistory struct {
+	Name   string        `json:"name"`
+	Type   string        `json:"type"`
+	Target string        `json:"target"`
+	Points []*CheckPoint `json:"points"` //按检查时间升序
+}
+
+// CheckPoint 可用性检查历史中的一次结果
+type CheckPoint struct {
+	CheckedAt  int64   `json:"checkedAt"`
+	Status     string  `json:"status"`
+	LatencyMs  float64 `json:"latencyMs"`
+	StatusCode int     `json:"statusCode,omitempty"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// ProbeMatrix 各服务器到各探测目标的最近一轮结果
+type ProbeMatrix struct {
+	Probes  []string          `json:"probes"` //探测任务名称，按配置顺序
+	Servers []*ProbeMatrixRow `json:"servers"`
+}
+
+// ProbeMatrixRow 一台服务器到各探测目标的结果
+type ProbeMatrixRow struct {
+	Id      string                  `json:"id"`
+	Name    string                  `json:"name"`
+	Results map[string]*ProbeResult `json:"results"` //探测任务名称到最近一轮结果；未执行该任务的目标不出现
+}
+
+// ProbePoint 网络探测历史中的一轮结果
+type ProbePoint struct {
+	ProbedAt    int64   `json:"probedAt"`
+	RTTMs       float64 `json:"rttMs"`
+	JitterMs    float64 `json:"jitterMs"`
+	LossPercent float64 `json:"lossPercent"`
+	Error       string  `json:"error,omitempty"`
+}
+
+// ConnectionLink 面板与 Agent 之间 WebSocket 链路的双向测量结果
+type ConnectionLink struct {
+	Transport     string       `json:"transport"`           //ws、http、pull、node_exporter 或 ingest；只有 ws 有链路测量
+	LastHeartbeat int64        `json:"lastHeartbeat"`       //最近一次收到 pong（或无会话上报）的时间，unix 秒
+	Dashboard     *LinkQuality `json:"dashboard,omitempty"` //面板向 Agent 发送 ping 测得
+	Agent         *LinkQuality `json:"agent,omitempty"`     //Agent 向面板发送 ping 测得，随上报携带
+}
@@ -17,36 +17,130 @@ type ServerInfo struct {
 
 	Ip  string `json:"ip"`
 	Loc string `json:"loc"`
+
+	Stale []string `json:"stale,omitempty"` //采集失败或超时、沿用上一次结果的采集器
+
+	DiskIO []*DiskIO `json:"diskIO,omitempty"` //各物理磁盘的 I/O 负载
+
+	Sockets *SocketInfo `json:"sockets,omitempty"` //TCP/UDP 连接与套接字统计，仅 Linux
+
+	Processes *ProcessInfo `json:"processes,omitempty"` //进程数量统计与资源占用排行
+
+	Sensors *SensorInfo `json:"sensors,omitempty"` //硬件传感器读数，仅 Linux；没有传感器（如虚拟机）时为空
+
+	WatchedProcesses []*WatchedProcess `json:"watchedProcesses,omitempty"` //Agent 配置中受监控进程的状态
+
+	Checks []*CheckResult `json:"checks,omitempty"` //Agent 配置中可用性检查的最近一次结果
+
+	Probes []*ProbeResult `json:"probes,omitempty"` //面板下发的网络探测任务最近一轮的结果
+
+	Aggregates *SampleAggregates `json:"aggregates,omitempty"` //上报周期内多次采样的统计；未开启高频采样时为空
+
+	Link *LinkQuality `json:"link,omitempty"` //Agent 测得的到面板的 WebSocket 链路质量；其他上报方式为空
+}
+
+// 高频采样统计的指标名称
+const (
+	MetricCPU    = "cpu"    //CPU占用百分比
+	MetricIowait = "iowait" //CPU iowait百分比
+	MetricSteal  = "steal"  //CPU steal百分比
+	MetricRAM    = "ram"    //内存占用百分比
+	MetricSwap   = "swap"   //swap占用百分比
+	MetricLoad1  = "load1"  //1分钟负载
+	MetricNetIn  = "netIn"  //下载速度
+	MetricNetOut = "netOut" //上传速度
+	MetricDisk   = "disk"   //硬盘占用百分比
+)
+
+// SampleAggregates 一个上报周期内多次采样的统计
+type SampleAggregates struct {
+	Samples  int                        `json:"samples"`  //采样次数
+	WindowMs int64                      `json:"windowMs"` //统计窗口长度，单位毫秒
+	Metrics  map[string]MetricAggregate `json:"metrics"`  //key 为指标名称
+}
+
+// MetricAggregate 单项指标在统计窗口内的最小值、平均值、最大值和 95 分位数
+type MetricAggregate struct {
+	Min float64 `json:"min"`
+	Avg float64 `json:"avg"`
+	Max float64 `json:"max"`
+	P95 float64 `json:"p95"`
 }
 type CpuInfo struct {
-	//Cores     int32   `json:"cores"`
-	//ModelName string `json:"modelName"`
-	//Mhz       float64 `json:"mhz"`
 	/*cpu占用*/
 	Percent float64 `json:"percent"`
+	//各逻辑核心占用
+	PerCore []float64 `json:"perCore,omitempty"`
+	//各状态的时间占比（user/system/iowait/steal/irq 等）
+	Times *CpuTimes `json:"times,omitempty"`
+	//当前频率，单位MHz；各核心平均值
+	Mhz float64 `json:"mhz,omitempty"`
+	//逻辑核心数
+	LogicalCores int `json:"logicalCores,omitempty"`
+	//物理核心数
+	PhysicalCores int `json:"physicalCores,omitempty"`
 	//cpu信息字符串描述
-	Info []string `json:"info"`
+	Info []string `json:"info,omitempty"`
+}
+
+// CpuTimes 两次采集之间各状态的时间占比，单位百分比
+type CpuTimes struct {
+	User    float64 `json:"user"`
+	System  float64 `json:"system"`
+	Nice    float64 `json:"nice"`
+	Idle    float64 `json:"idle"`
+	Iowait  float64 `json:"iowait"`
+	Irq     float64 `json:"irq"`
+	Softirq float64 `json:"softirq"`
+	Steal   float64 `json:"steal"` //虚拟机被宿主机占用的时间，超售的 VPS 上偏高
 }
 type HostInfo struct {
-	KernelArch           string `json:"kernelArch"` // native cpu architecture queried at runtime, as returned by `uname -m` or empty string in case of error
-	KernelVersion        string `json:"kernelVersion"`
-	VirtualizationSystem string `json:"virtualizationSystem"`
+	KernelArch           string `json:"kernelArch,omitempty"` // native cpu architecture queried at runtime, as returned by `uname -m` or empty string in case of error
+	KernelVersion        string `json:"kernelVersion,omitempty"`
+	VirtualizationSystem string `json:"virtualizationSystem,omitempty"`
 	Uptime               uint64 `json:"uptime"` //单位秒
-	BootTime             uint64 `json:"bootTime"`
+	BootTime             uint64 `json:"bootTime,omitempty"`
 	//Procs                uint64 `json:"procs"`          // number of processes
-	OS              string        `json:"os"`              // ex: freebsd, linux
-	Platform        string        `json:"platform"`        // ex: ubuntu, linuxmint
-	PlatformFamily  string        `json:"platformFamily"`  // ex: debian, rhel
-	PlatformVersion string        `json:"platformVersion"` //具体版本
+	OS              string        `json:"os,omitempty"`              // ex: freebsd, linux
+	Platform        string        `json:"platform,omitempty"`        // ex: ubuntu, linuxmint
+	PlatformFamily  string        `json:"platformFamily,omitempty"`  // ex: debian, rhel
+	PlatformVersion string        `json:"platformVersion,omitempty"` //具体版本
 	AvgStat         *load.AvgStat `json:"avgStat"`
 }
 type VirtualMemoryInfo struct {
-	Total uint64 `json:"total"`
-	//Available   uint64  `json:"available"`
+	Total       uint64  `json:"total"`
 	Used        uint64  `json:"used"`
 	UsedPercent float64 `json:"usedPercent"`
-	//Free        uint64  `json:"free"`
+
+	//以下为内存明细，旧版本 Agent 不上报
+	Available   uint64 `json:"available,omitempty"`   //可用内存（含可回收的缓存），由内核估算
+	Free        uint64 `json:"free,omitempty"`        //完全空闲的内存
+	Buffers     uint64 `json:"buffers,omitempty"`     //块设备缓冲
+	Cached      uint64 `json:"cached,omitempty"`      //页缓存
+	Shared      uint64 `json:"shared,omitempty"`      //共享内存（含 tmpfs）
+	Slab        uint64 `json:"slab,omitempty"`        //内核 slab
+	Dirty       uint64 `json:"dirty,omitempty"`       //等待写回磁盘的脏页
+	CommittedAS uint64 `json:"committedAS,omitempty"` //已承诺分配的内存，超过 total 说明存在超额分配
+
+	HugePagesTotal uint64 `json:"hugePagesTotal,omitempty"` //大页数量
+	HugePagesFree  uint64 `json:"hugePagesFree,omitempty"`  //空闲大页数量
+	HugePageSize   uint64 `json:"hugePageSize,omitempty"`   //大页大小，单位字节
 }
+
+// 内存占用百分比的计算方式
+const (
+	RAMPercentUsed         = "used"          //Agent 上报的 usedPercent
+	RAMPercentExcludeCache = "exclude_cache" //(total - available) / total，不计入可回收的缓存
+)
+
+// Percent 按指定方式计算内存占用百分比；缺少 available（旧版本 Agent）时使用 usedPercent
+func (m *VirtualMemoryInfo) Percent(mode string) float64 {
+	if mode == RAMPercentExcludeCache && m.Available > 0 && m.Total >= m.Available {
+		return float64(m.Total-m.Available) / float64(m.Total) * 100
+	}
+	return m.UsedPercent
+}
+
 type SwapMemoryInfo struct {
 	Total       uint64  `json:"total"`
 	Used        uint64  `json:"used"`
@@ -57,18 +151,199 @@ type DiskInfo struct {
 	Total       uint64       `json:"total"`
 	Used        uint64       `json:"used"`
 	UsedPercent float64      `json:"usedPercent"`
-	Partitions  []*Partition `json:"partitions"`
+	Partitions  []*Partition `json:"partitions,omitempty"`
+}
+
+// SocketInfo TCP/UDP 连接与套接字统计，IPv4 与 IPv6 合计
+// 来源于 /proc/net/sockstat、/proc/net/snmp、/proc/net/netstat 与 nf_conntrack
+type SocketInfo struct {
+	TCPEstablished uint64 `json:"tcpEstablished"` //ESTABLISHED 与 CLOSE_WAIT 状态的连接数
+	TCPTimeWait    uint64 `json:"tcpTimeWait"`    //TIME_WAIT 状态的连接数
+	TCPOrphan      uint64 `json:"tcpOrphan"`      //已与进程分离、仍在关闭中的连接数
+	TCPInUse       uint64 `json:"tcpInUse"`       //除 TIME_WAIT 外的全部 TCP 套接字（含 LISTEN）
+	UDPInUse       uint64 `json:"udpInUse"`       //UDP 套接字数
+
+	RetransPercent  float64 `json:"retransPercent"`  //两次采集之间重传报文占发送报文的百分比
+	ListenOverflows uint64  `json:"listenOverflows"` //两次采集之间 accept 队列溢出次数
+	ListenDrops     uint64  `json:"listenDrops"`     //两次采集之间 LISTEN 套接字丢弃的连接请求数
+	UDPErrors       uint64  `json:"udpErrors"`       //两次采集之间 UDP 接收错误数（含缓冲区满）
+
+	ConntrackCount   uint64  `json:"conntrackCount,omitempty"`   //连接跟踪表条目数，未加载 nf_conntrack 时省略
+	ConntrackMax     uint64  `json:"conntrackMax,omitempty"`     //连接跟踪表上限，达到后新连接会被丢弃
+	ConntrackPercent float64 `json:"conntrackPercent,omitempty"` //连接跟踪表使用率
+}
+
+// ProcessInfo 进程数量统计与 CPU、内存占用排行
+type ProcessInfo struct {
+	Total   uint64 `json:"total"`   //进程总数
+	Running uint64 `json:"running"` //运行中（R 状态）的进程数
+	Zombie  uint64 `json:"zombie"`  //僵尸进程数
+	Threads uint64 `json:"threads"` //线程总数
+
+	TopCPU []*ProcessStat `json:"topCPU,omitempty"` //按两次采集之间的 CPU 占用排序，首次采集时为空
+	TopRSS []*ProcessStat `json:"topRSS,omitempty"` //按常驻内存排序
+}
+
+// ProcessStat 单个进程的资源占用
+type ProcessStat struct {
+	Pid        int32   `json:"pid"`
+	Name       string  `json:"name"`
+	User       string  `json:"user,omitempty"`
+	Cmdline    string  `json:"cmdline,omitempty"` //按配置截断，敏感参数已隐藏
+	CPUPercent float64 `json:"cpuPercent"`        //两次采集之间占用单个核心的百分比，多线程进程可超过 100
+	RSS        uint64  `json:"rss"`               //常驻内存，单位字节
+	MemPercent float64 `json:"memPercent"`        //常驻内存占总内存的百分比
+}
+
+// SensorInfo 硬件传感器读数，来源于 sysfs 的 class/hwmon 与 class/thermal
+type SensorInfo struct {
+	Temperatures []*TemperatureSensor `json:"temperatures,omitempty"`
+	Fans         []*FanSensor         `json:"fans,omitempty"`
+	Power        []*PowerSensor       `json:"power,omitempty"`
+}
+
+// TemperatureSensor 单个温度传感器
+type TemperatureSensor struct {
+	Name     string  `json:"name"`               //驱动名与标签，如 coretemp_core_0、nvme_composite；thermal_zone 为其类型，如 x86_pkg_temp
+	Celsius  float64 `json:"celsius"`            //当前温度，单位摄氏度
+	High     float64 `json:"high,omitempty"`     //高温阈值，传感器未提供时省略
+	Critical float64 `json:"critical,omitempty"` //临界温度，达到后硬件可能降频或关机，传感器未提供时省略
+}
+
+// Overheated 是否达到临界温度
+func (t *TemperatureSensor) Overheated() bool {
+	return t.Critical > 0 && t.Celsius >= t.Critical
+}
+
+// FanSensor 单个风扇
+type FanSensor struct {
+	Name string `json:"name"`          //驱动名与标签，如 nct6775_cpu_fan，没有标签时为 nct6775_fan1
+	RPM  uint64 `json:"rpm"`           //当前转速
+	Min  uint64 `json:"min,omitempty"` //最低转速阈值，低于该值视为故障，未设置时省略
+}
+
+// PowerSensor 单个功耗传感器
+type PowerSensor struct {
+	Name  string  `json:"name"`          //驱动名与标签，如 amdgpu_ppt
+	Watts float64 `json:"watts"`         //当前（或平均）功耗，单位瓦
+	Cap   float64 `json:"cap,omitempty"` //功耗上限，未设置时省略
+}
+
+// WatchedProcess 受监控进程的状态
+type WatchedProcess struct {
+	Name        string  `json:"name"`
+	Up          bool    `json:"up"`                    //运行的实例数不少于配置的最小值
+	Count       int     `json:"count"`                 //运行的实例数
+	Pid         int32   `json:"pid,omitempty"`         //主进程 pid：最早启动的实例，或 pidfile 中记录的进程
+	CPUPercent  float64 `json:"cpuPercent"`            //各实例两次采集之间的 CPU 占用之和（单个核心的百分比）
+	RSS         uint64  `json:"rss"`                   //各实例常驻内存之和，单位字节
+	Restarts    uint64  `json:"restarts"`              //Agent 启动以来检测到的重启次数（主进程 pid 变化）
+	RestartedAt int64   `json:"restartedAt,omitempty"` //最近一次重启的检测时间，unix 秒
+	Error       string  `json:"error,omitempty"`       //pidfile 读取失败等原因
+}
+
+// 可用性检查类型
+const (
+	CheckTypeHTTP = "http"
+	CheckTypeTCP  = "tcp"
+	CheckTypeTLS  = "tls"
+)
+
+// 可用性检查状态
+const (
+	CheckStatusOK   = "ok"
+	CheckStatusWarn = "warn" //检查成功但需要关注，如证书即将过期
+	CheckStatusFail = "fail"
+)
+
+// CheckResult 可用性检查的一次结果
+type CheckResult struct {
+	Name          string  `json:"name"`
+	Type          string  `json:"type"`   //http、tcp 或 tls
+	Target        string  `json:"target"` //http 检查为 URL，其余为 host:port
+	Status        string  `json:"status"` //ok、warn 或 fail
+	LatencyMs     float64 `json:"latencyMs"`
+	StatusCode    int     `json:"statusCode,omitempty"`    //http 检查的响应状态码
+	CertExpiresAt int64   `json:"certExpiresAt,omitempty"` //tls 检查的证书过期时间，unix 秒
+	Error         string  `json:"error,omitempty"`
+	CheckedAt     int64   `json:"checkedAt"` //检查完成时间，unix 秒
+}
+
+// ProbeResult 网络探测任务一轮的结果
+type ProbeResult struct {
+	Name        string  `json:"name"`
+	Target      string  `json:"target"` //实际探测的地址：icmp 为主机，tcp 为 host:port
+	Method      string  `json:"method"` //实际使用的方式：icmp 或 tcp（icmp 没有权限时回退）
+	Sent        int     `json:"sent"`
+	Received    int     `json:"received"`
+	LossPercent float64 `json:"lossPercent"`
+	RTTMs       float64 `json:"rttMs"`    //平均往返时间
+	MinMs       float64 `json:"minMs"`    //最小往返时间
+	MaxMs       float64 `json:"maxMs"`    //最大往返时间
+	JitterMs    float64 `json:"jitterMs"` //相邻两次往返时间之差的平均值
+	Error       string  `json:"error,omitempty"`
+	ProbedAt    int64   `json:"probedAt"` //本轮完成时间，unix 秒
 }
 
 // Partition /*磁盘分区信息*/
 type Partition struct {
-	MountPoint  string  `json:"mountPoint"`
-	Fstype      string  `json:"fstype"`
+	MountPoint  string  `json:"mountPoint,omitempty"` //指标帧中省略，由 Dashboard 按顺序从完整帧补全
+	Fstype      string  `json:"fstype,omitempty"`
 	Total       uint64  `json:"total"`
 	Free        uint64  `json:"free"`
 	Used        uint64  `json:"used"`
 	UsedPercent float64 `json:"usedPercent"`
+
+	InodesTotal       uint64  `json:"inodesTotal,omitempty"`
+	InodesUsed        uint64  `json:"inodesUsed,omitempty"`
+	InodesUsedPercent float64 `json:"inodesUsedPercent,omitempty"`
+
+	ReadOnly   bool   `json:"readOnly,omitempty"`   //意外以只读方式挂载（不在预期只读的挂载点中），常见于文件系统出错后被内核重新挂载为只读
+	WriteError string `json:"writeError,omitempty"` //写入探测失败的原因，未开启探测或探测成功时为空
+	Stale      bool   `json:"stale,omitempty"`      //本次查询失败、超时或处于隔离期，沿用最近一次成功的结果
+}
+
+// 分区健康状态，按严重程度排列
+const (
+	PartitionHealthOK             = "ok"
+	PartitionHealthReadOnly       = "readonly"        //意外只读
+	PartitionHealthWriteFailed    = "write_failed"    //写入探测失败
+	PartitionHealthInodesCritical = "inodes_critical" //inode 使用率达到 InodesCriticalPercent
+	PartitionHealthInodesWarning  = "inodes_warning"  //inode 使用率达到 InodesWarningPercent
+)
+
+// inode 使用率告警阈值
+const (
+	InodesWarningPercent  = 85
+	InodesCriticalPercent = 95
+)
+
+// Health 返回分区最严重的健康问题，没有问题时为 PartitionHealthOK
+func (p *Partition) Health() string {
+	switch {
+	case p.ReadOnly:
+		return PartitionHealthReadOnly
+	case p.WriteError != "":
+		return PartitionHealthWriteFailed
+	case p.InodesUsedPercent >= InodesCriticalPercent:
+		return PartitionHealthInodesCritical
+	case p.InodesUsedPercent >= InodesWarningPercent:
+		return PartitionHealthInodesWarning
+	}
+	return PartitionHealthOK
+}
+
+// DiskIO 单个块设备在两次采集之间的 I/O 负载
+type DiskIO struct {
+	Name        string  `json:"name"`        //设备名，如 sda、nvme0n1
+	ReadSpeed   uint64  `json:"readSpeed"`   //读取速度，单位字节/秒
+	WriteSpeed  uint64  `json:"writeSpeed"`  //写入速度，单位字节/秒
+	ReadIOPS    float64 `json:"readIOPS"`    //每秒读次数
+	WriteIOPS   float64 `json:"writeIOPS"`   //每秒写次数
+	AwaitMs     float64 `json:"awaitMs"`     //平均每次 I/O 的耗时（含排队），单位毫秒
+	BusyPercent float64 `json:"busyPercent"` //设备忙碌时间占比，接近 100 说明磁盘已饱和
 }
+
 type NetworkInfo struct {
 	//下载速度
 	NetInSpeed uint64 `json:"netInSpeed"`
@@ -78,4 +353,63 @@ type NetworkInfo struct {
 	NetInTransfer uint64 `json:"netInTransfer"`
 	//上传
 	NetOutTransfer uint64 `json:"netOutTransfer"`
+
+	Interfaces []*NetInterface `json:"interfaces,omitempty"` //各网卡的统计，聚合值保持原有口径
+}
+
+// NetInterface 单个网卡的流量、包统计与链路状态
+type NetInterface struct {
+	Name        string   `json:"name"`                //网卡名，如 eth0
+	InSpeed     uint64   `json:"inSpeed"`             //下载速度，单位字节/秒
+	OutSpeed    uint64   `json:"outSpeed"`            //上传速度，单位字节/秒
+	InTransfer  uint64   `json:"inTransfer"`          //累计接收字节
+	OutTransfer uint64   `json:"outTransfer"`         //累计发送字节
+	InPackets   uint64   `json:"inPackets"`           //每秒接收包数
+	OutPackets  uint64   `json:"outPackets"`          //每秒发送包数
+	InErrors    uint64   `json:"inErrors"`            //累计接收错误
+	OutErrors   uint64   `json:"outErrors"`           //累计发送错误
+	InDrops     uint64   `json:"inDrops"`             //累计接收丢包
+	OutDrops    uint64   `json:"outDrops"`            //累计发送丢包
+	OperState   string   `json:"operState,omitempty"` //链路状态：up、down、unknown 等
+	MTU         int      `json:"mtu,omitempty"`
+	LinkSpeed   int      `json:"linkSpeed,omitempty"` //协商速率，单位 Mbps；虚拟网卡等无法获取时为 0
+	Addrs       []string `json:"addrs,omitempty"`     //IPv4/IPv6 地址（CIDR 形式）
+}
+
+// FillEmpty 补全为空的嵌套对象，保证展示层可以直接访问
+func (s *ServerInfo) FillEmpty() {
+	if s.HostInfo == nil {
+		s.HostInfo = &HostInfo{}
+	}
+	if s.HostInfo.AvgStat == nil {
+		s.HostInfo.AvgStat = &load.AvgStat{}
+	}
+	if s.CpuInfo == nil {
+		s.CpuInfo = &CpuInfo{}
+	}
+	if s.VirtualMemoryInfo == nil {
+		s.VirtualMemoryInfo = &VirtualMemoryInfo{}
+	}
+	if s.SwapMemoryInfo == nil {
+		s.SwapMemoryInfo = &SwapMemoryInfo{}
+	}
+	if s.DiskInfo == nil {
+		s.DiskInfo = &DiskInfo{}
+	}
+	if s.NetworkInfo == nil {
+		s.NetworkInfo = &NetworkInfo{}
+	}
+}
+
+// LinkQuality WebSocket 链路质量，由 ping 与对应 pong 之间的往返时间统计
+type LinkQuality struct {
+	LastRTTMs         float64 `json:"lastRttMs"`         //最近一次往返时间
+	AvgRTTMs          float64 `json:"avgRttMs"`          //最近若干次往返时间的平均值
+	JitterMs          float64 `json:"jitterMs"`          //最近若干次中相邻两次往返时间之差的平均值
+	Samples           int     `json:"samples"`           //参与统计的往返时间个数
+	PingsSent         int64   `json:"pingsSent"`         //累计发送的 ping
+	PongsReceived     int64   `json:"pongsReceived"`     //累计收到的对应 pong
+	MissedPongs       int64   `json:"missedPongs"`       //累计在下一次 ping 前仍未收到的 pong
+	ConsecutiveMissed int     `json:"consecutiveMissed"` //连续未收到的 pong，收到 pong 后清零
+	LastPongAt        int64   `json:"lastPongAt"`        //最近一次收到 pong 的时间，unix 秒
 }
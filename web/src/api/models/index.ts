@@ -7,6 +7,9 @@ export interface ServerInfo {
     platform: string;
 
     cpuPercent: number;
+    cpuPerCore?: number[];
+    cpuTimes?: CpuTimes;
+    diskIOBusy?: number;
     RAMPercent: number;
     SWAPPercent: number;
     diskPercent: number;
@@ -18,20 +21,77 @@ export interface ServerInfo {
     hostInfo: HostInfo;
 
     loc: string;
+
+    // 未运行的受监控进程名称
+    processesDown?: string[];
+    // 最近一次检查失败的可用性检查名称
+    checksFailed?: string[];
+    // 达到临界温度的温度传感器名称
+    overheated?: string[];
+
+    aggregates?: SampleAggregates;
+}
+
+// Agent 开启高频采样时，上报周期内各指标的统计
+export interface SampleAggregates {
+    samples: number;
+    windowMs: number;
+    metrics: Record<string, MetricAggregate>;
+}
+
+export interface MetricAggregate {
+    min: number;
+    avg: number;
+    max: number;
+    p95: number;
 }
 
 export interface HostInfo {
     cpuInfo: string[];
     avgStat: AvgStat;
+    cpuMhz?: number;
+    logicalCores?: number;
+    physicalCores?: number;
     RAMTotal: number;
     RAMUsed: number;
     swapTotal: number;
     swapUsed: number;
+    RAMAvailable?: number;
+    RAMFree?: number;
+    RAMBuffers?: number;
+    RAMCached?: number;
+    RAMShared?: number;
+    RAMSlab?: number;
+    RAMDirty?: number;
+    RAMCommitted?: number;
+    hugePagesTotal?: number;
+    hugePagesFree?: number;
+    hugePageSize?: number;
     diskTotal: number;
     diskUsed: number;
     diskPartitions: DiskPartition[];
+    diskIO?: DiskIO[];
     netInTransfer: number;
     netOutTransfer: number;
+    netInterfaces?: NetInterface[];
+    sockets?: SocketInfo;
+    processes?: ProcessInfo;
+    sensors?: SensorInfo;
+    watchedProcesses?: WatchedProcess[];
+    checks?: CheckResult[];
+    probes?: ProbeResult[];
+}
+
+// 两次采集之间 CPU 各状态的时间占比（百分比）
+export interface CpuTimes {
+    user: number;
+    system: number;
+    nice: number;
+    idle: number;
+    iowait: number;
+    irq: number;
+    softirq: number;
+    steal: number;
 }
 
 export interface DiskPartition {
@@ -41,6 +101,182 @@ export interface DiskPartition {
     free: number;
     used: number;
     usedPercent: number;
+    inodesTotal?: number;
+    inodesUsed?: number;
+    inodesUsedPercent?: number;
+    readOnly?: boolean;
+    writeError?: string;
+    // 本次查询失败、超时或处于隔离期，沿用最近一次成功的结果
+    stale?: boolean;
+    // 分区健康状态，仅 /api/server/statusInfo 返回
+    health?: PartitionHealth;
+}
+
+export type PartitionHealth = 'ok' | 'readonly' | 'write_failed' | 'inodes_critical' | 'inodes_warning';
+
+// 单个物理磁盘的 I/O 负载
+export interface DiskIO {
+    name: string;
+    readSpeed: number;
+    writeSpeed: number;
+    readIOPS: number;
+    writeIOPS: number;
+    awaitMs: number;
+    busyPercent: number;
+}
+
+// 单个网卡的统计
+export interface NetInterface {
+    name: string;
+    inSpeed: number;
+    outSpeed: number;
+    inTransfer: number;
+    outTransfer: number;
+    inPackets: number;
+    outPackets: number;
+    inErrors: number;
+    outErrors: number;
+    inDrops: number;
+    outDrops: number;
+    operState?: string;
+    mtu?: number;
+    linkSpeed?: number;
+    addrs?: string[];
+}
+
+// TCP/UDP 连接与套接字统计（仅 Linux），差值类指标为两次采集之间的增量
+export interface SocketInfo {
+    tcpEstablished: number;
+    tcpTimeWait: number;
+    tcpOrphan: number;
+    tcpInUse: number;
+    udpInUse: number;
+    retransPercent: number;
+    listenOverflows: number;
+    listenDrops: number;
+    udpErrors: number;
+    conntrackCount?: number;
+    conntrackMax?: number;
+    conntrackPercent?: number;
+}
+
+// 硬件传感器读数（仅 Linux），没有对应传感器时省略
+export interface SensorInfo {
+    temperatures?: TemperatureSensor[];
+    fans?: FanSensor[];
+    power?: PowerSensor[];
+}
+
+export interface TemperatureSensor {
+    name: string;
+    celsius: number;
+    high?: number;
+    critical?: number;
+}
+
+export interface FanSensor {
+    name: string;
+    rpm: number;
+    min?: number;
+}
+
+export interface PowerSensor {
+    name: string;
+    watts: number;
+    cap?: number;
+}
+
+// 进程数量统计与资源占用排行
+export interface ProcessInfo {
+    total: number;
+    running: number;
+    zombie: number;
+    threads: number;
+    topCPU?: ProcessStat[];
+    topRSS?: ProcessStat[];
+}
+
+export interface ProcessStat {
+    pid: number;
+    name: string;
+    user?: string;
+    cmdline?: string;
+    // 两次采集之间占用单个核心的百分比，多线程进程可超过 100
+    cpuPercent: number;
+    rss: number;
+    memPercent: number;
+}
+
+// Agent 配置中受监控进程的状态
+export interface WatchedProcess {
+    name: string;
+    up: boolean;
+    count: number;
+    pid?: number;
+    cpuPercent: number;
+    rss: number;
+    // Agent 启动以来检测到的重启次数（主进程 pid 变化）
+    restarts: number;
+    restartedAt?: number;
+    error?: string;
+}
+
+// Agent 配置中可用性检查的最近一次结果
+export interface CheckResult {
+    name: string;
+    // http、tcp 或 tls
+    type: string;
+    target: string;
+    // ok、warn（如证书即将过期）或 fail
+    status: string;
+    latencyMs: number;
+    statusCode?: number;
+    certExpiresAt?: number;
+    error?: string;
+    checkedAt: number;
+}
+
+// 可用性检查的历史，由 /api/server/checks/:id 返回
+export interface CheckHistory {
+    name: string;
+    type: string;
+    target: string;
+    points: CheckPoint[];
+}
+
+export interface CheckPoint {
+    checkedAt: number;
+    status: string;
+    latencyMs: number;
+    statusCode?: number;
+    error?: string;
+}
+
+// 面板下发的网络探测任务最近一轮的结果
+export interface ProbeResult {
+    name: string;
+    // 实际探测的地址：icmp 为主机，tcp 为 host:port
+    target: string;
+    // 实际使用的方式：icmp 或 tcp（icmp 没有权限时回退）
+    method: string;
+    sent: number;
+    received: number;
+    lossPercent: number;
+    rttMs: number;
+    minMs: number;
+    maxMs: number;
+    jitterMs: number;
+    error?: string;
+    probedAt: number;
+}
+
+// 网络探测历史中的一轮结果，由 /api/probes/:id/:name 返回
+export interface ProbePoint {
+    probedAt: number;
+    rttMs: number;
+    jitterMs: number;
+    lossPercent: number;
+    error?: string;
 }
 
 export interface AvgStat {
@@ -11,13 +11,20 @@ const enUS = {
       memoryUsage: 'Memory',
       swapMemory: 'Swap',
       networkSpeed: 'Network',
+      diskIO: 'Disk I/O',
       uptime: 'Uptime',
       lastUpdate: 'Updated',
       cpuInfo: 'CPU',
       memoryDetails: 'Memory',
       systemLoad: 'Load',
       totalTraffic: 'Traffic',
-      diskUsage: 'Disk'
+      diskUsage: 'Disk',
+      connections: 'Conns',
+      processes: 'Procs',
+      sensors: 'Sensors',
+      watchedProcesses: 'Watched',
+      checks: 'Checks',
+      probes: 'Latency'
     },
     units: {
       percent: '%',
@@ -36,7 +43,56 @@ const enUS = {
     table: {
       mountPoint: 'Mount',
       used: 'Used',
-      total: 'Total'
+      total: 'Total',
+      health: 'Status',
+      process: 'Process',
+      rss: 'Memory'
+    },
+    partitionStale: 'Stale',
+    partitionHealth: {
+      readonly: 'Read-only',
+      write_failed: 'Write failed',
+      inodes_critical: 'Inodes full',
+      inodes_warning: 'Inodes low'
+    },
+    sockets: {
+      summary: '{established} established / {timeWait} TIME_WAIT',
+      retrans: 'Retrans {percent}%',
+      listenOverflows: 'Listen overflows {count}',
+      udp: 'UDP {inuse}, errors {errors}',
+      conntrack: 'conntrack {count} / {max} ({percent}%)'
+    },
+    processes: {
+      summary: '{total} ({running} running, {threads} threads)',
+      zombie: '{count} zombie',
+      topCPU: 'Top CPU',
+      topRSS: 'Top memory'
+    },
+    sensors: {
+      maxTemp: 'Max {celsius}°C',
+      fans: '{count} fans',
+      power: '{watts} W',
+      temperature: '{name} {celsius}°C',
+      critical: ' (crit {critical}°C)',
+      fan: '{name} {rpm} RPM',
+      powerItem: '{name} {watts} W',
+      alert: '{count} overheated'
+    },
+    watchedProcesses: {
+      down: 'Not running ({count} instances)',
+      restarts: 'Restarted {count} times, last at {time}',
+      alert: '{count} process down | {count} processes down'
+    },
+    checks: {
+      certExpires: 'Certificate expires {date}',
+      history: 'Last {count}: {uptime}% up, avg {latency} ms',
+      noHistory: 'No history yet',
+      alert: '{count} check failing | {count} checks failing'
+    },
+    probes: {
+      details: '{method} {sent} sent, {received} received, min/avg/max {min}/{avg}/{max} ms, jitter {jitter} ms',
+      history: 'Last {count}: avg {latency} ms, jitter {jitter} ms, loss {loss}%',
+      noHistory: 'No history yet'
     },
     status: {
       online: 'Online',
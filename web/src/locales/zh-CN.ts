@@ -11,13 +11,20 @@ const zhCN = {
       memoryUsage: '内存',
       swapMemory: '交换区',
       networkSpeed: '网络',
+      diskIO: '磁盘 I/O',
       uptime: '运行时间',
       lastUpdate: '最后更新',
       cpuInfo: 'CPU',
       memoryDetails: '内存',
       systemLoad: '负载',
       totalTraffic: '流量',
-      diskUsage: '磁盘'
+      diskUsage: '磁盘',
+      connections: '连接',
+      processes: '进程',
+      sensors: '传感器',
+      watchedProcesses: '监控进程',
+      checks: '可用性',
+      probes: '网络延迟'
     },
     units: {
       percent: '%',
@@ -36,7 +43,56 @@ const zhCN = {
     table: {
       mountPoint: '挂载点',
       used: '已用',
-      total: '总计'
+      total: '总计',
+      health: '状态',
+      process: '进程',
+      rss: '内存'
+    },
+    partitionStale: '数据过期',
+    partitionHealth: {
+      readonly: '只读',
+      write_failed: '写入失败',
+      inodes_critical: 'inode 耗尽',
+      inodes_warning: 'inode 紧张'
+    },
+    sockets: {
+      summary: '已建立 {established} / TIME_WAIT {timeWait}',
+      retrans: '重传率 {percent}%',
+      listenOverflows: '队列溢出 {count}',
+      udp: 'UDP {inuse}，错误 {errors}',
+      conntrack: 'conntrack {count} / {max}（{percent}%）'
+    },
+    processes: {
+      summary: '{total}（运行 {running}，线程 {threads}）',
+      zombie: '僵尸 {count}',
+      topCPU: 'CPU 占用',
+      topRSS: '内存占用'
+    },
+    sensors: {
+      maxTemp: '最高 {celsius}°C',
+      fans: '风扇 {count}',
+      power: '功耗 {watts} W',
+      temperature: '{name} {celsius}°C',
+      critical: '（临界 {critical}°C）',
+      fan: '{name} {rpm} RPM',
+      powerItem: '{name} {watts} W',
+      alert: '{count} 个传感器过热'
+    },
+    watchedProcesses: {
+      down: '未运行（实例数 {count}）',
+      restarts: '重启 {count} 次，最近 {time}',
+      alert: '{count} 个进程异常'
+    },
+    checks: {
+      certExpires: '证书 {date} 过期',
+      history: '最近 {count} 次：可用率 {uptime}%，平均延迟 {latency} ms',
+      noHistory: '暂无历史记录',
+      alert: '{count} 项检查失败'
+    },
+    probes: {
+      details: '{method} 发送 {sent} 次，收到 {received} 次，最小/平均/最大 {min}/{avg}/{max} ms，抖动 {jitter} ms',
+      history: '最近 {count} 轮：平均延迟 {latency} ms，抖动 {jitter} ms，丢包 {loss}%',
+      noHistory: '暂无历史记录'
     },
     status: {
       online: '在线',
@@ -22,7 +22,7 @@
  */
 
 import http from '@/api'
-import type { ServerInfo } from '@/api/models'
+import type { CheckHistory, ProbePoint, ServerInfo } from '@/api/models'
 
 /**
  * 服务器数据服务类
@@ -42,6 +42,39 @@ export class ServerService {
       throw error
     }
   }
+
+  /**
+   * 获取服务器可用性检查的历史
+   * @param serverId 服务器 ID
+   * @returns Promise<CheckHistory[]> 各项检查的历史，按名称排序
+   * @throws Error 当 HTTP 请求失败时抛出
+   */
+  async fetchCheckHistory(serverId: string): Promise<CheckHistory[]> {
+    try {
+      const response = await http.get<Array<CheckHistory>>(`/server/checks/${encodeURIComponent(serverId)}`)
+      return response.data
+    } catch (error) {
+      console.error('Failed to fetch check history:', error)
+      throw error
+    }
+  }
+
+  /**
+   * 获取服务器到一个探测目标的历史
+   * @param serverId 服务器 ID
+   * @param name 探测任务名称
+   * @returns Promise<ProbePoint[]> 按时间排序的各轮结果
+   * @throws Error 当 HTTP 请求失败时抛出
+   */
+  async fetchProbeHistory(serverId: string, name: string): Promise<ProbePoint[]> {
+    try {
+      const response = await http.get<Array<ProbePoint>>(`/probes/${encodeURIComponent(serverId)}/${encodeURIComponent(name)}`)
+      return response.data
+    } catch (error) {
+      console.error('Failed to fetch probe history:', error)
+      throw error
+    }
+  }
 }
 
 /**